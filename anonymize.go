@@ -0,0 +1,81 @@
+package mapper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"reflect"
+)
+
+// WithAnonymizer has exporters write the values of column col through fn,
+// so personal data is hashed or faked on the way out of the database:
+//
+//	var exported = users.With(
+//	  WithAnonymizer("email", AnonymizeHash(salt)),
+//	  WithAnonymizer("name", func(any) any { return "John Doe" }),
+//	)
+//
+// It applies to [ExportValues], which [WriteCSV], the fixtures dumper and
+// the Parquet, Arrow, XLSX and BigQuery adapters use, while [Values] and
+// statement helpers are left alone. Exporters with a schema, as Parquet,
+// expect fn to return values of the type it is given. It panics with an
+// [*ErrMissingColumns] when col is not mapped, so a misspelled column does
+// not leave data unanonymized.
+func WithAnonymizer(col string, fn func(v any) any) MapperOption {
+	return func(m *mapper) {
+		if len(m.cols) > 0 && !m.Has(col) { // else checked once mapped
+			panic(&ErrMissingColumns{Cols: []string{col}})
+		}
+		m.anonymizers = maps.Clone(m.anonymizers) // shared with clones
+		if m.anonymizers == nil {
+			m.anonymizers = make(map[string]func(any) any)
+		}
+		m.anonymizers[col] = fn
+	}
+}
+
+// AnonymizeHash returns an anonymizer replacing values with the hex SHA-256
+// of salt and their text, see [FormatValueE], so they still join and count
+// alike. NULL values are kept, and values failing to format still fail.
+func AnonymizeHash(salt string) func(v any) any {
+	return func(v any) any {
+		if v == nil || isNull(reflect.ValueOf(v)) {
+			return nil
+		}
+		s, err := FormatValueE(v)
+		if err != nil {
+			return failingValue{err}
+		}
+		sum := sha256.Sum256([]byte(salt + s))
+		return hex.EncodeToString(sum[:])
+	}
+}
+
+// ExportValues is like [Values] but returns the values of anonymized
+// columns through their anonymizer, see [WithAnonymizer].
+func (m *mapper) ExportValues(dest any) []any {
+	vals, err := m.ExportValuesE(dest)
+	if err != nil {
+		panic(err)
+	}
+	return vals
+}
+
+// ExportValuesE is like [ExportValues] but returns an error instead of
+// panicking, as [ValuesE].
+func (m *mapper) ExportValuesE(dest any) ([]any, error) {
+	v, p, err := m.structOf(dest)
+	if err != nil {
+		return nil, err
+	}
+	vals := m.values(v, p)
+	if len(m.anonymizers) == 0 {
+		return vals, nil
+	}
+	for j, col := range m.cols {
+		if fn := m.anonymizers[col]; fn != nil {
+			vals[j] = fn(vals[j])
+		}
+	}
+	return vals, nil
+}
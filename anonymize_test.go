@@ -0,0 +1,47 @@
+package mapper
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithAnonymizer(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID    int64   `mapper:"id"`
+		Email *string `mapper:"email"`
+		Name  string  `mapper:"name"`
+	}
+	email := "a@b.c"
+	u := User{1, &email, "alice"}
+	users := Mapper(User{}, "*")
+	anon := users.With(WithAnonymizer("email", AnonymizeHash("s")), WithAnonymizer("name", func(any) any { return "x" }))
+
+	is.Equal(users.ExportValues(u), users.Values(u))
+	is.Equal(anon.Values(u), users.Values(u)) // left alone
+	vals := anon.ExportValues(u)
+	is.Equal(vals[0], int64(1))
+	is.Equal(len(vals[1].(string)), 64)
+	is.Equal(vals[1], AnonymizeHash("s")("a@b.c")) // pointers hash as their value
+	is.True(vals[1] != AnonymizeHash("t")("a@b.c"))
+	is.Equal(vals[2], "x")
+	is.Equal(anon.ExportValues(User{ID: 2})[1], nil)
+
+	var b strings.Builder
+	is.NoErr(anon.WriteCSV(&b, []User{u}))
+	is.Equal(b.String(), "id,email,name\n1,"+vals[1].(string)+",x\n")
+	is.Equal(len(users.With(WithAnonymizer("id", nil)).anonymizers), 1)
+	is.Equal(len(users.anonymizers), 0) // not shared
+
+	_, err := MapperWithOptionsE(User{}, []MapperOption{WithAnonymizer("mail", AnonymizeHash("s"))}, "*")
+	var missing *ErrMissingColumns
+	is.True(errors.As(err, &missing))
+	is.Equal(missing.Cols, []string{"mail"})
+	defer func() {
+		is.True(errors.As(recover().(error), &missing)) // once mapped
+	}()
+	users.With(WithAnonymizer("mail", AnonymizeHash("s")))
+}
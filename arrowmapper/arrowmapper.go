@@ -0,0 +1,189 @@
+// Package arrowmapper converts mapped records into Apache Arrow record
+// batches, with a schema derived from the mapped fields, for zero-copy
+// handoff to analytics tooling such as DuckDB or ADBC drivers. It lives in
+// its own module to keep mapper free of dependencies.
+package arrowmapper
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/dav-m85/mapper"
+)
+
+// Mapper is the part of a mapper this package needs.
+type Mapper interface {
+	Fields() []mapper.FieldInfo
+	ExportValues(dest any) []any
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// nullTypes are the database/sql nullable types, by the type they hold.
+var nullTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeFor[sql.NullString]():  reflect.TypeFor[string](),
+	reflect.TypeFor[sql.NullInt64]():   reflect.TypeFor[int64](),
+	reflect.TypeFor[sql.NullInt32]():   reflect.TypeFor[int32](),
+	reflect.TypeFor[sql.NullInt16]():   reflect.TypeFor[int16](),
+	reflect.TypeFor[sql.NullByte]():    reflect.TypeFor[uint8](),
+	reflect.TypeFor[sql.NullFloat64](): reflect.TypeFor[float64](),
+	reflect.TypeFor[sql.NullBool]():    reflect.TypeFor[bool](),
+	reflect.TypeFor[sql.NullTime]():    timeType,
+}
+
+// Schema derives an Arrow schema from the mapped fields. Pointers and
+// sql.Null types are nullable, times are UTC nanosecond timestamps.
+func Schema(m Mapper) (*arrow.Schema, error) {
+	fields := m.Fields()
+	res := make([]arrow.Field, len(fields))
+	for j, f := range fields {
+		t, nullable := f.Type, false
+		if t.Kind() == reflect.Pointer {
+			t, nullable = t.Elem(), true
+		} else if v, ok := nullTypes[t]; ok {
+			t, nullable = v, true
+		}
+		dt, err := dataType(t)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", f.Column, err)
+		}
+		res[j] = arrow.Field{Name: f.Column, Type: dt, Nullable: nullable}
+	}
+	return arrow.NewSchema(res, nil), nil
+}
+
+func dataType(t reflect.Type) (arrow.DataType, error) {
+	if t == timeType {
+		return arrow.FixedWidthTypes.Timestamp_ns, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case reflect.Int8:
+		return arrow.PrimitiveTypes.Int8, nil
+	case reflect.Int16:
+		return arrow.PrimitiveTypes.Int16, nil
+	case reflect.Int32:
+		return arrow.PrimitiveTypes.Int32, nil
+	case reflect.Int, reflect.Int64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case reflect.Uint8:
+		return arrow.PrimitiveTypes.Uint8, nil
+	case reflect.Uint16:
+		return arrow.PrimitiveTypes.Uint16, nil
+	case reflect.Uint32:
+		return arrow.PrimitiveTypes.Uint32, nil
+	case reflect.Uint, reflect.Uint64:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case reflect.Float32:
+		return arrow.PrimitiveTypes.Float32, nil
+	case reflect.Float64:
+		return arrow.PrimitiveTypes.Float64, nil
+	case reflect.String:
+		return arrow.BinaryTypes.String, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return arrow.BinaryTypes.Binary, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// Appender accumulates records into Arrow columns:
+//
+//	a, _ := arrowmapper.NewAppender(users, memory.DefaultAllocator)
+//	defer a.Release()
+//	for _, u := range us {
+//	  a.Append(u)
+//	}
+//	batch := a.NewRecordBatch()
+type Appender struct {
+	m Mapper
+	b *array.RecordBuilder
+}
+
+// NewAppender returns an Appender allocating from mem.
+func NewAppender(m Mapper, mem memory.Allocator) (*Appender, error) {
+	schema, err := Schema(m)
+	if err != nil {
+		return nil, err
+	}
+	return &Appender{m: m, b: array.NewRecordBuilder(mem, schema)}, nil
+}
+
+// Schema returns the schema of built batches.
+func (a *Appender) Schema() *arrow.Schema {
+	return a.b.Schema()
+}
+
+// Append adds rec, a mapper target struct or a pointer to one, as a row.
+func (a *Appender) Append(rec any) {
+	for j, v := range a.m.ExportValues(rec) {
+		appendValue(a.b.Field(j), reflect.ValueOf(v))
+	}
+}
+
+// NewRecordBatch returns the rows appended so far as a record batch, and
+// resets the Appender. The batch MUST be released.
+func (a *Appender) NewRecordBatch() arrow.RecordBatch {
+	return a.b.NewRecordBatch()
+}
+
+// Release frees the Appender buffers.
+func (a *Appender) Release() {
+	a.b.Release()
+}
+
+func appendValue(b array.Builder, v reflect.Value) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			b.AppendNull()
+			return
+		}
+		v = v.Elem()
+	}
+	if _, ok := nullTypes[v.Type()]; ok {
+		if !v.FieldByName("Valid").Bool() {
+			b.AppendNull()
+			return
+		}
+		v = v.Field(0)
+	}
+	switch b := b.(type) {
+	case *array.BooleanBuilder:
+		b.Append(v.Bool())
+	case *array.Int8Builder:
+		b.Append(int8(v.Int()))
+	case *array.Int16Builder:
+		b.Append(int16(v.Int()))
+	case *array.Int32Builder:
+		b.Append(int32(v.Int()))
+	case *array.Int64Builder:
+		b.Append(v.Int())
+	case *array.Uint8Builder:
+		b.Append(uint8(v.Uint()))
+	case *array.Uint16Builder:
+		b.Append(uint16(v.Uint()))
+	case *array.Uint32Builder:
+		b.Append(uint32(v.Uint()))
+	case *array.Uint64Builder:
+		b.Append(v.Uint())
+	case *array.Float32Builder:
+		b.Append(float32(v.Float()))
+	case *array.Float64Builder:
+		b.Append(v.Float())
+	case *array.StringBuilder:
+		b.Append(v.String())
+	case *array.BinaryBuilder:
+		b.Append(v.Bytes())
+	case *array.TimestampBuilder:
+		b.Append(arrow.Timestamp(v.Interface().(time.Time).UnixNano()))
+	default:
+		panic(fmt.Sprintf("unexpected builder %T", b))
+	}
+}
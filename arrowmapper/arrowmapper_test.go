@@ -0,0 +1,52 @@
+package arrowmapper
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/dav-m85/mapper"
+	"github.com/matryer/is"
+)
+
+type event struct {
+	ID    int64
+	Name  string
+	Note  *string
+	Score sql.NullFloat64
+	At    time.Time
+}
+
+func TestAppender(t *testing.T) {
+	is := is.New(t)
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	a, err := NewAppender(mapper.Mapper(event{}, "*"), mem)
+	is.NoErr(err)
+	defer a.Release()
+	is.Equal(a.Schema().String(), `schema:
+  fields: 5
+    - id: type=int64
+    - name: type=utf8
+    - note: type=utf8, nullable
+    - score: type=float64, nullable
+    - at: type=timestamp[ns, tz=UTC]`)
+
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	note := "n"
+	a.Append(event{1, "a", &note, sql.NullFloat64{Float64: 1.5, Valid: true}, at})
+	a.Append(&event{2, "b", nil, sql.NullFloat64{}, at})
+
+	batch := a.NewRecordBatch()
+	defer batch.Release()
+	is.Equal(batch.NumRows(), int64(2))
+	is.Equal(batch.Column(0).(*array.Int64).Int64Values(), []int64{1, 2})
+	is.Equal(batch.Column(2).(*array.String).Value(0), "n")
+	is.True(batch.Column(2).IsNull(1))
+	is.True(batch.Column(3).IsNull(1))
+	is.Equal(batch.Column(4).(*array.Timestamp).Value(0), arrow.Timestamp(at.UnixNano()))
+}
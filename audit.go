@@ -0,0 +1,101 @@
+package mapper
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// AuditRow is a row of an audit table, recording the change of a column
+// of a row. Map it to the audit table, possibly renamed:
+//
+//	var audits = Mapper(AuditRow{}, "*")
+//
+//	CREATE TABLE audit_log (
+//	  table_name text, row_key text, column_name text,
+//	  before_value text, after_value text, changed_at timestamptz
+//	)
+type AuditRow struct {
+	Table  string    `mapper:"table_name"`
+	Key    string    `mapper:"row_key"` // primary key values, comma separated
+	Column string    `mapper:"column_name"`
+	Before *string   `mapper:"before_value"` // as text, see FormatValue, nil for NULL
+	After  *string   `mapper:"after_value"`
+	At     time.Time `mapper:"changed_at,autocreate"`
+}
+
+// AuditRows returns the audit rows of the changes of a row of table from
+// before to after, records of m, one a changed column in column order.
+// Before is nil for an insert, and after for a delete. Values of fields
+// tagged sensitive are recorded as [REDACTED], while expressions are left
+// out. At is left zero, for [InsertBatch] to stamp.
+func (m *mapper) AuditRows(table string, before, after any) ([]AuditRow, error) {
+	changes, err := m.ChangesE(before, after)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	keyed := after
+	if keyed == nil {
+		keyed = before
+	}
+	v, p, err := m.structOf(keyed)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, j := range m.pkIndexesE() {
+		key, err := FormatValueE(m.fieldValue(v, p, j))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	rows := make([]AuditRow, len(changes))
+	for i, c := range changes {
+		rows[i] = AuditRow{
+			Table:  m.tableName(table),
+			Key:    strings.Join(keys, ","),
+			Column: c.Column,
+		}
+		if rows[i].Before, err = m.auditText(c.Column, c.Before); err != nil {
+			return nil, err
+		}
+		if rows[i].After, err = m.auditText(c.Column, c.After); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// WriteAudit inserts the audit rows of the changes of a row of table from
+// before to after into auditTable, through audits, a mapper of [AuditRow],
+// in the same transaction as the change preferably:
+//
+//	err := users.WriteAudit(ctx, tx, audits, "audit_log", "users", old, u)
+//
+// See [AuditRows].
+func (m *mapper) WriteAudit(ctx context.Context, db Execer, audits *mapper, auditTable, table string, before, after any) error {
+	rows, err := m.AuditRows(table, before, after)
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+	_, err = audits.InsertBatch(ctx, db, auditTable, rows, BatchOptions{})
+	return err
+}
+
+// auditText returns value v of col as audit text.
+func (m *mapper) auditText(col string, v any) (*string, error) {
+	if v == nil || isNull(reflect.ValueOf(v)) {
+		return nil, nil
+	}
+	if m.fields[fieldSlice(m.cols).index(col)].opts.Has("sensitive") {
+		s := redacted
+		return &s, nil
+	}
+	s, err := FormatValueE(v)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,59 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestAudit(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID       int64      `mapper:"id,pk"`
+		Name     string     `mapper:"name"`
+		Password string     `mapper:"password,sensitive"`
+		Seen     *time.Time `mapper:"seen"`
+		Posts    int        `mapper:"posts,expr=count(*)"`
+	}
+	users := Mapper(User{}, "*")
+	seen := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := User{ID: 1, Name: "a", Password: "x", Posts: 1}
+	after := User{ID: 1, Name: "b", Password: "y", Seen: &seen, Posts: 2}
+	str := func(s string) *string { return &s }
+
+	rows, err := users.AuditRows("users", before, &after)
+	is.NoErr(err)
+	is.Equal(rows, []AuditRow{
+		{Table: "users", Key: "1", Column: "name", Before: str("a"), After: str("b")},
+		{Table: "users", Key: "1", Column: "password", Before: str("[REDACTED]"), After: str("[REDACTED]")},
+		{Table: "users", Key: "1", Column: "seen", After: str("2024-06-01T00:00:00Z")},
+	})
+	rows, err = users.AuditRows("users", before, before)
+	is.NoErr(err)
+	is.Equal(len(rows), 0)
+	rows, err = users.AuditRows("users", nil, before) // insert, NULL seen left out
+	is.NoErr(err)
+	is.Equal(len(rows), 3)
+	is.Equal(rows[0], AuditRow{Table: "users", Key: "1", Column: "id", After: str("1")})
+	rows, err = users.AuditRows("users", after, nil) // delete
+	is.NoErr(err)
+	is.Equal(len(rows), 4)
+	is.Equal(rows[3].After, (*string)(nil))
+	_, err = users.AuditRows("users", before, buildUser{})
+	is.True(err != nil)
+
+	conn := &fakeConnector{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	audits := Mapper(AuditRow{}, "*")
+	is.NoErr(users.WriteAudit(context.Background(), db, audits, "audit_log", "users", before, after))
+	call := conn.lastCall()
+	is.Equal(call.query, "INSERT INTO audit_log (table_name,row_key,column_name,before_value,after_value,changed_at) VALUES (?,?,?,?,?,?),(?,?,?,?,?,?),(?,?,?,?,?,?)")
+	is.True(!call.args[5].(time.Time).IsZero()) // stamped
+	conn.calls = nil
+	is.NoErr(users.WriteAudit(context.Background(), db, audits, "audit_log", "users", before, before))
+	is.Equal(len(conn.calls), 0) // nothing changed
+}
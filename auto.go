@@ -0,0 +1,92 @@
+package mapper
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var nullTimeType = reflect.TypeFor[sql.NullTime]()
+
+// checkAuto returns an error when a field of type t has timestamp options
+// while not being a time.
+func checkAuto(t reflect.Type, opts TagOptions) error {
+	if !opts.Has("autocreate") && !opts.Has("autoupdate") {
+		return nil
+	}
+	switch t {
+	case timeType, reflect.PointerTo(timeType), nullTimeType:
+		return nil
+	}
+	return fmt.Errorf("field of type %s cannot be autocreate or autoupdate", t)
+}
+
+// autoExpr returns the SQL expression written for column j in INSERT, or
+// UPDATE statements when update is set, if any.
+func (m *mapper) autoExpr(j int, update bool) string {
+	opts := m.fields[j].opts
+	if !update && opts.Has("autocreate") {
+		return opts.Get("autocreate")
+	}
+	return opts.Get("autoupdate")
+}
+
+// stampedValues returns the values of rec as [Values] does, timestamps
+// stamped for an INSERT, or an UPDATE when update is set. On INSERT, zero
+// uuid primary keys are generated and defaults applied too, see [defaultOf].
+// Sensitive values are [Redacted].
+func (m *mapper) stampedValues(rec any, update bool) ([]any, error) {
+	if err := m.writable(); err != nil {
+		return nil, err
+	}
+	v, p, err := m.structOf(rec)
+	if err != nil {
+		return nil, err
+	}
+	if !update {
+		v = m.setDefaults(v)
+	}
+	vals := m.values(v, p)
+	var now time.Time
+	for j, f := range m.fields {
+		if !update && f.uuid != uuidNone && f.opts.Has("pk") {
+			if fv := v.FieldByIndex(f.Index); fv.IsZero() {
+				vals[j] = uuidValue(f.uuid, setUUID(fv, m.uuid))
+			}
+			continue
+		}
+		create, upd := f.opts.Has("autocreate"), f.opts.Has("autoupdate")
+		if !create && !upd || m.autoExpr(j, update) != "" {
+			continue
+		}
+		fv := v.FieldByIndex(f.Index)
+		if upd || !update && fv.IsZero() {
+			if now.IsZero() {
+				now = m.now()
+			}
+			vals[j] = m.utc(j, stamp(fv, now))
+		}
+	}
+	for j, v := range vals {
+		vals[j] = m.redact(j, v)
+	}
+	return vals, nil
+}
+
+// stamp sets the time field fv to t, and returns its new value.
+func stamp(fv reflect.Value, t time.Time) any {
+	var v reflect.Value
+	switch fv.Type() {
+	case timeType:
+		v = reflect.ValueOf(t)
+	case nullTimeType:
+		v = reflect.ValueOf(sql.NullTime{Time: t, Valid: true})
+	default:
+		v = reflect.ValueOf(&t)
+	}
+	if fv.CanSet() {
+		fv.Set(v)
+	}
+	return v.Interface()
+}
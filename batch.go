@@ -0,0 +1,196 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BatchOptions bound the statements of batched helpers, as [InsertBatch],
+// which split records into chunks run as one statement each. Zero fields
+// stand for the defaults of the mapper dialect.
+type BatchOptions struct {
+	// MaxParams bounds the placeholders of a statement: 65535 for Postgres
+	// and MySQL, 32766 for SQLite, and 999 otherwise.
+	MaxParams int
+
+	// MaxBytes bounds the approximate size of the arguments of a statement,
+	// which MUST stay under max_allowed_packet with MySQL: 4 MiB for
+	// MySQL, and unbounded otherwise.
+	MaxBytes int
+
+	// MaxRows bounds the records of a statement, unbounded by default.
+	MaxRows int
+
+	// ContinueOnError runs the chunks following a failed one, and returns
+	// the errors of every failed chunk joined.
+	ContinueOnError bool
+}
+
+// maxParams returns the placeholders per statement of o for dialect d.
+func (o BatchOptions) maxParams(d Dialect) int {
+	switch {
+	case o.MaxParams > 0:
+		return o.MaxParams
+	case d == Postgres, d == MySQL:
+		return 65535
+	case d == SQLite:
+		return 32766
+	}
+	return 999
+}
+
+// maxBytes returns the argument bytes per statement of o for dialect d, 0
+// when unbounded.
+func (o BatchOptions) maxBytes(d Dialect) int {
+	if o.MaxBytes == 0 && d == MySQL {
+		return 4 << 20
+	}
+	return o.MaxBytes
+}
+
+// BatchError is returned for a failed chunk of a batch, which held the
+// records from Offset to Offset+Len.
+type BatchError struct {
+	Chunk, Offset, Len int
+	Err                error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("chunk %d, records %d to %d: %v", e.Chunk, e.Offset, e.Offset+e.Len-1, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// InsertBatch inserts the records of recs, a slice of structs or struct
+// pointers, into table with multi-row INSERT statements, split so that
+// none exceeds the placeholder and size limits of opts:
+//
+//	n, err := users.InsertBatch(ctx, db, "", users, mapper.BatchOptions{})
+//
+// Records are stamped and run their BeforeInsert hook as with [Insert],
+// which makes a batch of one record. It returns the rows affected by the
+// statements run, and a [BatchError] per failed statement.
+func (m *mapper) InsertBatch(ctx context.Context, db Execer, table string, recs any, opts BatchOptions) (int64, error) {
+	if err := m.writable(); err != nil {
+		return 0, err
+	}
+	list, err := records(recs)
+	if err != nil {
+		return 0, err
+	}
+	rows := make([][]any, len(list))
+	for i, rec := range list {
+		if err := beforeInsert(ctx, rec); err != nil {
+			return 0, err
+		}
+		if err := m.validate(rec); err != nil {
+			return 0, err
+		}
+		args, err := m.insertArgs(rec)
+		if err != nil {
+			return 0, err
+		}
+		if rows[i], err = m.withTenant(ctx, args); err != nil {
+			return 0, err
+		}
+	}
+	prefix := m.insertInto(table)
+	return m.execBatch(ctx, db, m.tableName(table), rows, 0, 0, opts, func(chunk [][]any) (string, []any) {
+		var b strings.Builder
+		b.WriteString(prefix)
+		var args []any
+		for i, r := range chunk {
+			if i > 0 {
+				b.WriteString(m.sep())
+			}
+			b.WriteString("(" + m.insertValues(len(args)) + ")")
+			args = append(args, r...)
+		}
+		return b.String(), args
+	})
+}
+
+// records returns the records of recs, a slice, as pointers when
+// addressable so that stamps are set.
+func records(recs any) ([]any, error) {
+	rv := reflect.ValueOf(recs)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("records of type %T not a slice", recs)
+	}
+	list := make([]any, rv.Len())
+	for i := range list {
+		rec := rv.Index(i)
+		if rec.Kind() != reflect.Pointer {
+			rec = rec.Addr()
+		}
+		list[i] = rec.Interface()
+	}
+	return list, nil
+}
+
+// execBatch runs the statements made by stmt of chunks of rows, the
+// values of each record, split as set by opts. Statements take perRow
+// placeholders a record, or as many as its values when 0, and extra
+// ones.
+func (m *mapper) execBatch(ctx context.Context, db Execer, table string, rows [][]any, perRow, extra int, opts BatchOptions, stmt func(chunk [][]any) (string, []any)) (int64, error) {
+	maxParams, maxBytes := opts.maxParams(m.Dialect)-extra, opts.maxBytes(m.Dialect)
+	var affected int64
+	var errs []error
+	chunk := 0
+	for start := 0; start < len(rows); chunk++ {
+		end, params, size := start, 0, 0
+		for end < len(rows) && (opts.MaxRows == 0 || end-start < opts.MaxRows) {
+			p, s := perRow, argsSize(rows[end])
+			if p == 0 {
+				p = len(rows[end])
+			}
+			if end > start && (params+p > maxParams || maxBytes > 0 && size+s > maxBytes) {
+				break
+			}
+			params, size = params+p, size+s
+			end++
+		}
+		query, args := stmt(rows[start:end])
+		res, err := m.execTraced(ctx, db, table, query, args)
+		if err == nil {
+			var n int64
+			if n, err = res.RowsAffected(); err == nil {
+				affected += n
+			}
+		}
+		if err != nil {
+			errs = append(errs, &BatchError{Chunk: chunk, Offset: start, Len: end - start, Err: err})
+			if !opts.ContinueOnError {
+				break
+			}
+		}
+		start = end
+	}
+	if len(errs) == 1 {
+		return affected, errs[0]
+	}
+	return affected, errors.Join(errs...)
+}
+
+// argsSize returns the approximate size of args once sent.
+func argsSize(args []any) int {
+	size := 0
+	for _, a := range args {
+		switch v := a.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		case Redacted:
+			size += argsSize([]any{v.v})
+		default:
+			size += 8
+		}
+	}
+	return size
+}
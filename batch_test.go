@@ -0,0 +1,59 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestInsertBatch(t *testing.T) {
+	is := is.New(t)
+	type Item struct {
+		ID   int64  `mapper:"id,pk"`
+		Name string `mapper:"name"`
+	}
+	fc := &fakeConnector{}
+	db := sql.OpenDB(fc)
+	defer db.Close()
+	ctx := context.Background()
+	dut := Mapper(Item{}, "*").SetOptions(WithDialect(Postgres), WithTable("items"))
+
+	items := []Item{{1, "a"}, {2, "b"}, {3, "c"}, {4, "d"}, {5, "e"}}
+	n, err := dut.InsertBatch(ctx, db, "", items, BatchOptions{MaxParams: 4})
+	is.NoErr(err)
+	is.Equal(n, int64(3)) // one per statement with the fake driver
+	is.Equal(len(fc.calls), 3)
+	is.Equal(fc.calls[0].query, "INSERT INTO items (id,name) VALUES ($1,$2),($3,$4)")
+	is.Equal(fc.calls[0].args, []driver.Value{int64(1), "a", int64(2), "b"})
+	is.Equal(fc.calls[2].query, "INSERT INTO items (id,name) VALUES ($1,$2)")
+
+	fc.calls = nil
+	_, err = dut.With(WithDialect(MySQL)).InsertBatch(ctx, db, "", items, BatchOptions{MaxBytes: 20})
+	is.NoErr(err)
+	is.Equal(fc.calls[0].query, "INSERT INTO items (id,name) VALUES (?,?),(?,?)") // 9 bytes a record
+
+	fc.calls = nil
+	fc.execErr = func(query string, args []driver.Value) error {
+		if args[0] != int64(1) {
+			return errors.New("boom")
+		}
+		return nil
+	}
+	n, err = dut.InsertBatch(ctx, db, "", items, BatchOptions{MaxRows: 2, ContinueOnError: true})
+	is.Equal(n, int64(1))
+	var be *BatchError
+	is.True(errors.As(err, &be))
+	is.Equal(be.Chunk, 1)
+	is.Equal(be.Offset, 2)
+	is.True(strings.Contains(err.Error(), "chunk 2, records 4 to 4: boom"))
+
+	n, err = dut.InsertBatch(ctx, db, "", items, BatchOptions{MaxRows: 2})
+	is.Equal(n, int64(1))
+	is.True(errors.As(err, &be))
+	is.Equal(be.Err.Error(), "boom")
+}
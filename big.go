@@ -0,0 +1,68 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+var (
+	ratType    = reflect.TypeFor[big.Rat]()
+	bigIntType = reflect.TypeFor[big.Int]()
+)
+
+func init() {
+	RegisterConverter(ratValue, scanRat)
+	RegisterConverter(bigIntValue, scanBigInt)
+}
+
+func ratValue(r big.Rat) (driver.Value, error) {
+	n, exact := r.FloatPrec()
+	if !exact {
+		return nil, fmt.Errorf("%s has no finite decimal form", r.String())
+	}
+	return r.FloatString(n), nil
+}
+
+func scanRat(src any) (big.Rat, error) {
+	var r big.Rat
+	switch v := src.(type) {
+	case string:
+		if _, ok := r.SetString(v); ok {
+			return r, nil
+		}
+	case []byte:
+		if _, ok := r.SetString(string(v)); ok {
+			return r, nil
+		}
+	case int64:
+		r.SetInt64(v)
+		return r, nil
+	case float64:
+		if r.SetFloat64(v) != nil {
+			return r, nil
+		}
+	}
+	return r, fmt.Errorf("cannot scan %T %v into a big.Rat", src, src)
+}
+
+func bigIntValue(i big.Int) (driver.Value, error) {
+	return i.String(), nil
+}
+
+// scanBigInt accepts the integral values of scanRat, as 12.00 from a
+// NUMERIC column with a scale.
+func scanBigInt(src any) (big.Int, error) {
+	var i big.Int
+	if v, ok := src.(int64); ok {
+		i.SetInt64(v)
+		return i, nil
+	}
+	r, err := scanRat(src)
+	if err != nil || !r.IsInt() {
+		return i, fmt.Errorf("cannot scan %T %v into a big.Int", src, src)
+	}
+	i.Set(r.Num())
+	return i, nil
+}
@@ -0,0 +1,54 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestBig(t *testing.T) {
+	is := is.New(t)
+	type Invoice struct {
+		ID     int64    `mapper:"id,pk"`
+		Total  big.Rat  `mapper:"total"`
+		Tax    *big.Rat `mapper:"tax"`
+		Shares *big.Int `mapper:"shares"`
+	}
+	dut := Mapper(Invoice{}, "*")
+	total, _ := new(big.Rat).SetString("12345678901234567890.01")
+	shares, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	vals := dut.Values(Invoice{1, *total, nil, shares})
+	v, err := driver.DefaultParameterConverter.ConvertValue(vals[1])
+	is.NoErr(err)
+	is.Equal(v, "12345678901234567890.01")
+	is.Equal(vals[2], nil) // NULL
+	v, err = driver.DefaultParameterConverter.ConvertValue(vals[3])
+	is.NoErr(err)
+	is.Equal(v, "123456789012345678901234567890")
+
+	_, err = dut.Values(Invoice{Total: *big.NewRat(1, 3)})[1].(driver.Valuer).Value()
+	is.True(err != nil) // no finite decimal form
+
+	rows := queryFake(t, []string{"id", "total", "tax", "shares"},
+		[]driver.Value{int64(1), []byte("0.10"), "0.2", []byte("42.000")},
+		[]driver.Value{int64(2), int64(3), nil, []byte("4.5")},
+	)
+	is.True(rows.Next())
+	inv, err := ScanRow[Invoice](dut, rows)
+	is.NoErr(err)
+	is.Equal(inv.Total.String(), "1/10")
+	is.Equal(inv.Tax.String(), "1/5")
+	is.Equal(inv.Shares.String(), "42")
+	is.True(rows.Next())
+	_, err = ScanRow[Invoice](dut, rows)
+	is.True(err != nil) // 4.5 shares
+
+	ddl, err := dut.With(WithTable("invoices"), WithDialect(MySQL)).CreateTableStringE("")
+	is.NoErr(err)
+	is.True(strings.Contains(ddl, "total decimal(65,30)"))
+	is.True(compatibleType(reflect.TypeFor[*big.Rat](), "numeric", Postgres))
+}
@@ -0,0 +1,85 @@
+// Package bigquerymapper adapts mapped records to cloud.google.com/go/bigquery
+// streaming inserts, using the mapper column names instead of a second
+// struct tag scheme. It lives in its own module to keep mapper free of
+// dependencies.
+package bigquerymapper
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// Mapper is the part of a mapper this package needs.
+type Mapper interface {
+	Columns() []string
+	ExportValues(dest any) []any
+}
+
+// Saver returns a bigquery.ValueSaver of rec, a mapper target struct or a
+// pointer to one. insertID enables best effort deduplication, leave it
+// empty to have the client generate one:
+//
+//	err := table.Inserter().Put(ctx, bigquerymapper.Saver(events, ev, ev.UUID))
+//
+// NULL values, as nil pointers and invalid sql.Null types, are sent as
+// such.
+func Saver(m Mapper, rec any, insertID string) bigquery.ValueSaver {
+	return &saver{m: m, rec: rec, insertID: insertID}
+}
+
+// Savers returns the savers of records, a slice of structs or struct
+// pointers, for a single Put call. insertID gives the insert ID of record
+// i, it can be nil.
+func Savers(m Mapper, records any, insertID func(i int) string) ([]bigquery.ValueSaver, error) {
+	recs := reflect.ValueOf(records)
+	if recs.Kind() != reflect.Slice && recs.Kind() != reflect.Array {
+		return nil, fmt.Errorf("records not a slice: %s", recs.Kind())
+	}
+	res := make([]bigquery.ValueSaver, recs.Len())
+	for i := range res {
+		var id string
+		if insertID != nil {
+			id = insertID(i)
+		}
+		res[i] = Saver(m, recs.Index(i).Interface(), id)
+	}
+	return res, nil
+}
+
+type saver struct {
+	m        Mapper
+	rec      any
+	insertID string
+}
+
+// Save implements bigquery.ValueSaver.
+func (s *saver) Save() (map[string]bigquery.Value, string, error) {
+	cols := s.m.Columns()
+	row := make(map[string]bigquery.Value, len(cols))
+	for j, v := range s.m.ExportValues(s.rec) {
+		v, err := plain(v)
+		if err != nil {
+			return nil, "", fmt.Errorf("column %s: %w", cols[j], err)
+		}
+		row[cols[j]] = v
+	}
+	return row, s.insertID, nil
+}
+
+// plain dereferences pointers and resolves driver.Valuer implementations,
+// such as sql.Null types, returning nil for NULL.
+func plain(v any) (any, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return plain(rv.Elem().Interface())
+	}
+	if vr, ok := v.(driver.Valuer); ok {
+		return vr.Value()
+	}
+	return v, nil
+}
@@ -0,0 +1,37 @@
+package bigquerymapper
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/dav-m85/mapper"
+	"github.com/matryer/is"
+)
+
+type event struct {
+	ID   string
+	Kind sql.NullString
+	At   time.Time
+	N    *int
+}
+
+func TestSaver(t *testing.T) {
+	is := is.New(t)
+	m := mapper.Mapper(event{}, "*")
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	n := 3
+
+	row, id, err := Saver(m, &event{"e1", sql.NullString{}, at, &n}, "e1").Save()
+	is.NoErr(err)
+	is.Equal(id, "e1")
+	is.Equal(row, map[string]bigquery.Value{"id": "e1", "kind": nil, "at": at, "n": 3})
+
+	ss, err := Savers(m, []event{{ID: "a"}, {ID: "b"}}, func(i int) string { return string(rune('a' + i)) })
+	is.NoErr(err)
+	is.Equal(len(ss), 2)
+	_, id, err = ss[1].Save()
+	is.NoErr(err)
+	is.Equal(id, "b")
+}
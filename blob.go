@@ -0,0 +1,208 @@
+package mapper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// Compressor compresses the values of fields tagged compress=, see
+// [RegisterCompressor].
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+
+	// Magic returns the bytes compressed data starts with, which tell
+	// compressed values from legacy uncompressed ones.
+	Magic() []byte
+}
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[string]Compressor{"gzip": gzipCompressor{}}
+)
+
+// RegisterCompressor makes c available to fields tagged compress=name,
+// which MUST be strings or byte slices:
+//
+//	Payload []byte `mapper:"payload,compress=gzip"`
+//
+// [Values] then gives compressed bytes, and scans decompress values
+// starting with the magic bytes of the field compressor, passing others
+// as is, so columns can hold legacy uncompressed values. Compressors with
+// no magic bytes decompress every value. gzip is
+// built in, and the zstdmapper package registers zstd.
+//
+// Compressors MUST be registered before mappers using them are built,
+// which init functions do.
+func RegisterCompressor(name string, c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[name] = c
+}
+
+// checkBlob returns the compressor of a field of type t with options
+// opts, or an error when it is unknown or the field is compressed or
+// encrypted while not being a string, a byte slice or gob encoded.
+func checkBlob(t reflect.Type, opts TagOptions) (Compressor, error) {
+	if !opts.Has("compress") && !opts.Has("encrypted") {
+		return nil, nil
+	}
+	if !opts.Has("gob") && t.Kind() != reflect.String && (t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8) {
+		return nil, fmt.Errorf("field of type %s cannot be compressed or encrypted", t)
+	}
+	if !opts.Has("compress") {
+		return nil, nil
+	}
+	name := opts.Get("compress")
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	c, ok := compressors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown compressor %q, see RegisterCompressor", name)
+	}
+	return c, nil
+}
+
+// isBlob tells whether f is gob encoded, compressed or encrypted.
+func (f *field) isBlob() bool {
+	return f.gob || f.compressor != nil || f.encrypted
+}
+
+// encodeBlob returns the gob encoded, compressed, then encrypted, bytes of
+// v, the value of field j. Nil values are NULL.
+func (m *mapper) encodeBlob(j int, v any) any {
+	f := &m.fields[j]
+	rv := reflect.ValueOf(v)
+	var data []byte
+	var err error
+	switch {
+	case !rv.IsValid(), isNilable(rv.Kind()) && rv.IsNil():
+		return nil // NULL
+	case f.gob:
+		var b bytes.Buffer
+		if err := gob.NewEncoder(&b).EncodeValue(rv); err != nil {
+			return failingValue{fmt.Errorf("%s: %w", f.Name, err)}
+		}
+		data = b.Bytes()
+	case rv.Kind() == reflect.String:
+		data = []byte(rv.String())
+	default:
+		data = rv.Bytes()
+	}
+	if f.compressor != nil {
+		if data, err = f.compressor.Compress(data); err != nil {
+			return failingValue{err}
+		}
+	}
+	if f.encrypted {
+		if m.cipher == nil {
+			return failingValue{errNoCipher}
+		}
+		if data, err = m.cipher.Encrypt(data); err != nil {
+			return failingValue{err}
+		}
+	}
+	return data
+}
+
+// blobScanner scans bytes into the gob encoded, compressed or encrypted
+// field pointed to by dest, decrypting, decompressing then decoding them.
+type blobScanner struct {
+	f      *field
+	cipher Cipher
+	dest   reflect.Value
+}
+
+func (s blobScanner) Scan(src any) error {
+	fv := s.dest.Elem()
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		fv.SetZero()
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan a %T into %s", src, s.f.Name)
+	}
+	var err error
+	if s.f.encrypted {
+		if s.cipher == nil {
+			return errNoCipher
+		}
+		if data, err = s.cipher.Decrypt(data); err != nil {
+			return err
+		}
+	}
+	if s.f.compressor != nil {
+		if data, err = decompress(s.f.compressor, data); err != nil {
+			return err
+		}
+	}
+	if s.f.gob {
+		fv.SetZero()
+		if err := gob.NewDecoder(bytes.NewReader(data)).DecodeValue(fv); err != nil {
+			return fmt.Errorf("%s: %w", s.f.Name, err)
+		}
+		return nil
+	}
+	if fv.Kind() == reflect.String {
+		fv.SetString(string(data))
+	} else {
+		fv.SetBytes(bytes.Clone(data)) // drivers reuse src
+	}
+	return nil
+}
+
+// isNilable tells whether values of kind k can be nil.
+func isNilable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return true
+	}
+	return false
+}
+
+// decompress returns data decompressed by c when it starts with the magic
+// bytes of c, or data itself, a legacy uncompressed value.
+func decompress(c Compressor, data []byte) ([]byte, error) {
+	if magic := c.Magic(); len(magic) > 0 && !bytes.HasPrefix(data, magic) {
+		return data, nil
+	}
+	return c.Decompress(data)
+}
+
+// gzipCompressor is the built in gzip [Compressor].
+type gzipCompressor struct{}
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (gzipCompressor) Magic() []byte {
+	return []byte{0x1f, 0x8b}
+}
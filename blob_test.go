@@ -0,0 +1,120 @@
+package mapper
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// reverseCompressor is a [Compressor] reversing bytes, with magic RV1.
+type reverseCompressor struct{}
+
+func (reverseCompressor) Compress(data []byte) ([]byte, error) {
+	return append([]byte("RV1"), reversed(data)...), nil
+}
+
+func (reverseCompressor) Decompress(data []byte) ([]byte, error) {
+	return reversed(data[3:]), nil
+}
+
+func (reverseCompressor) Magic() []byte {
+	return []byte("RV1")
+}
+
+func reversed(data []byte) []byte {
+	r := bytes.Clone(data)
+	slices.Reverse(r)
+	return r
+}
+
+func TestCompress(t *testing.T) {
+	is := is.New(t)
+	RegisterCompressor("reverse", reverseCompressor{})
+	type Event struct {
+		ID      int64  `mapper:"id,pk"`
+		Payload string `mapper:"payload,compress=gzip"`
+		Secret  []byte `mapper:"secret,compress=gzip,encrypted"`
+	}
+	k, err := NewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	is.NoErr(err)
+	dut := Mapper(Event{}, "*").SetOptions(WithCipher(k))
+
+	payload := strings.Repeat("hello ", 100)
+	vals := dut.Values(Event{1, payload, []byte(payload)})
+	c := vals[1].([]byte)
+	is.True(bytes.HasPrefix(c, []byte{0x1f, 0x8b}))
+	is.True(len(c) < len(payload))
+	s := vals[2].([]byte)
+	is.True(bytes.HasPrefix(s, []byte("k1:")))
+	is.True(len(s) < len(payload)) // compressed before encryption
+
+	rows := queryFake(t, []string{"id", "payload", "secret"},
+		[]driver.Value{int64(1), c, s},
+		[]driver.Value{int64(2), []byte("legacy"), nil},
+		[]driver.Value{int64(3), []byte("RV1 reversed"), nil},
+	)
+	is.True(rows.Next())
+	e, err := ScanRow[Event](dut, rows)
+	is.NoErr(err)
+	is.Equal(e.Payload, payload)
+	is.Equal(string(e.Secret), payload)
+	is.True(rows.Next())
+	e, err = ScanRow[Event](dut, rows)
+	is.NoErr(err)
+	is.Equal(e.Payload, "legacy") // uncompressed values pass as is
+	is.Equal(e.Secret, nil)
+	is.True(rows.Next())
+	e, err = ScanRow[Event](dut, rows)
+	is.NoErr(err)
+	is.Equal(e.Payload, "RV1 reversed") // left alone by other compressors
+
+	type Bad struct {
+		N int `mapper:"n,compress=gzip"`
+	}
+	_, err = MapperE(Bad{}, "*")
+	is.True(err != nil)
+	type Unknown struct {
+		B []byte `mapper:"b,compress=lz4"`
+	}
+	_, err = MapperE(Unknown{}, "*")
+	is.True(err != nil)
+}
+
+func TestGob(t *testing.T) {
+	is := is.New(t)
+	type Point struct{ X, Y int }
+	type Shape struct {
+		ID     int64          `mapper:"id,pk"`
+		Points []Point        `mapper:"points,gob,compress=gzip"`
+		Tags   map[string]int `mapper:"tags,gob"`
+		Meta   *Point         `mapper:"meta,gob"`
+	}
+	dut := Mapper(Shape{}, "*")
+	vals := dut.Values(Shape{ID: 1, Points: []Point{{1, 2}, {3, 4}}})
+	is.True(bytes.HasPrefix(vals[1].([]byte), []byte{0x1f, 0x8b}))
+	is.Equal(vals[2], nil) // nil map is NULL
+	is.Equal(vals[3], nil)
+
+	tags := dut.Values(Shape{Tags: map[string]int{"a": 1}})[2].([]byte)
+	rows := queryFake(t, []string{"id", "points", "tags", "meta"},
+		[]driver.Value{int64(1), vals[1], tags, nil},
+		[]driver.Value{int64(2), nil, []byte("garbage"), nil},
+	)
+	is.True(rows.Next())
+	s, err := ScanRow[Shape](dut, rows)
+	is.NoErr(err)
+	is.Equal(s.Points, []Point{{1, 2}, {3, 4}})
+	is.Equal(s.Tags, map[string]int{"a": 1})
+	is.Equal(s.Meta, nil)
+	is.True(rows.Next())
+	_, err = ScanRow[Shape](dut, rows)
+	is.True(err != nil)
+
+	ddl, err := dut.With(WithTable("shapes"), WithDialect(Postgres)).CreateTableStringE("")
+	is.NoErr(err)
+	is.True(strings.Contains(ddl, "points bytea")) // gob columns hold bytes
+}
@@ -0,0 +1,295 @@
+package mapper
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SelectString returns a SELECT statement of the mapped columns from table,
+// to be completed with WHERE, ORDER BY... clauses. With a tenant column, it
+// already has a WHERE clause to be completed with AND, see
+// [WithTenantColumn]. It selects DISTINCT rows if so set, see [WithDistinct]
+// and [WithDistinctOn].
+func (m *mapper) SelectString(table string) string {
+	m.warnDeprecated(nil)
+	s := "SELECT " + m.distinctString() + m.selectList("") + " FROM " + m.tableName(table)
+	if m.tenantCol != "" {
+		s += " WHERE " + m.tenantCol + "=" + m.placeholder(1)
+	}
+	return s
+}
+
+// distinctString returns the DISTINCT clause of SELECT statements, if any.
+func (m *mapper) distinctString() string {
+	switch {
+	case len(m.distinctOn) > 0:
+		return "DISTINCT ON (" + strings.Join(m.distinctOn, m.sep()) + ") "
+	case m.distinct:
+		return "DISTINCT "
+	}
+	return ""
+}
+
+// InsertString returns an INSERT statement of the mapped columns into
+// table, taking the arguments returned by [InsertArgs].
+func (m *mapper) InsertString(table string) string {
+	m.mustWritable()
+	m.warnDeprecated(nil)
+	return m.insertInto(table) + "(" + m.insertValues(0) + ")"
+}
+
+// insertInto returns the INSERT statement of table up to its VALUES lists.
+func (m *mapper) insertInto(table string) string {
+	cols := m.writableColumns()
+	if m.tenantCol != "" {
+		cols += m.sep() + m.tenantCol
+	}
+	return "INSERT INTO " + m.tableName(table) + " (" + cols + ") VALUES "
+}
+
+// insertValues returns a VALUES list of [InsertString]: placeholders,
+// numbered after n, or the expressions of database stamped timestamps, then
+// the tenant placeholder.
+func (m *mapper) insertValues(n int) string {
+	first := n
+	var b strings.Builder
+	written := 0
+	for j := range m.cols {
+		if m.readOnlyColumn(j) {
+			continue
+		}
+		if written > 0 {
+			b.WriteString(m.sep())
+		}
+		written++
+		if expr := m.autoExpr(j, false); expr != "" {
+			b.WriteString(expr)
+			continue
+		}
+		n++
+		b.WriteString(m.castExpr(j, m.placeholder(n)))
+	}
+	if m.tenantCol != "" {
+		b.WriteString(m.sep() + m.placeholder(n+1))
+	} else if n-first == len(m.cols) && !m.hasCast() {
+		return m.placeholders(first+1, n-first)
+	}
+	return b.String()
+}
+
+// InsertArgs returns the values of rec for [InsertString]: [Values], with
+// autocreate fields stamped when zero and autoupdate ones stamped anyway,
+// and zero uuid primary keys generated. Database stamped columns are left
+// out. When rec is a pointer, its stamped fields are set too.
+func (m *mapper) InsertArgs(rec any) []any {
+	args, err := m.insertArgs(rec)
+	if err != nil {
+		panic(err)
+	}
+	return args
+}
+
+func (m *mapper) insertArgs(rec any) ([]any, error) {
+	vals, err := m.stampedValues(rec, false)
+	if err != nil {
+		return nil, err
+	}
+	args := vals[:0]
+	for j, v := range vals {
+		if !m.readOnlyColumn(j) && m.autoExpr(j, false) == "" {
+			args = append(args, v)
+		}
+	}
+	return args, nil
+}
+
+// UpdateString returns an UPDATE statement of table setting the mapped
+// columns but the primary key, which makes the WHERE clause, and autocreate
+// fields. It takes the arguments returned by [UpdateArgs].
+//
+// It panics with [ErrNoPrimaryKey] when no mapped column is tagged pk, or
+// with [ErrNoColumns] when nothing is left to set.
+func (m *mapper) UpdateString(table string) string {
+	m.mustWritable()
+	s, err := m.updateString(table)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (m *mapper) updateString(table string) (string, error) {
+	m.warnDeprecated(nil)
+	pks := m.pkIndexesE()
+	if len(pks) == 0 {
+		return "", ErrNoPrimaryKey
+	}
+	var b strings.Builder
+	b.WriteString("UPDATE " + m.tableName(table) + " SET ")
+	n := 0
+	set := 0
+	for j, col := range m.cols {
+		if m.fields[j].opts.Has("pk") || m.createdOnly(j) || m.readOnlyColumn(j) {
+			continue
+		}
+		if set > 0 {
+			b.WriteString(m.sep())
+		}
+		set++
+		if expr := m.autoExpr(j, true); expr != "" {
+			b.WriteString(col + "=" + expr)
+			continue
+		}
+		n++
+		b.WriteString(col + "=" + m.castExpr(j, m.placeholder(n)))
+	}
+	if set == 0 {
+		return "", ErrNoColumns
+	}
+	b.WriteString(m.pkWhere(pks, n+1))
+	return b.String(), nil
+}
+
+// UpdateArgs returns the values of rec for [UpdateString]: the mapped
+// columns but the primary key, then the primary key. Autoupdate fields are
+// stamped, and set in rec when it is a pointer.
+func (m *mapper) UpdateArgs(rec any) []any {
+	args, err := m.updateArgs(rec)
+	if err != nil {
+		panic(err)
+	}
+	return args
+}
+
+func (m *mapper) updateArgs(rec any) ([]any, error) {
+	vals, err := m.stampedValues(rec, true)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]any, 0, len(vals))
+	var keys []any
+	for j, v := range vals {
+		switch {
+		case m.fields[j].opts.Has("pk"):
+			keys = append(keys, v)
+		case !m.createdOnly(j) && !m.readOnlyColumn(j) && m.autoExpr(j, true) == "":
+			args = append(args, v)
+		}
+	}
+	return append(args, keys...), nil
+}
+
+// createdOnly tells whether column j is autocreate but not autoupdate, thus
+// left alone by updates.
+func (m *mapper) createdOnly(j int) bool {
+	opts := m.fields[j].opts
+	return opts.Has("autocreate") && !opts.Has("autoupdate")
+}
+
+// DeleteString returns a DELETE statement of the row of table whose primary
+// key is given as arguments, see [KeyArgs].
+//
+// It panics with [ErrNoPrimaryKey] when no mapped column is tagged pk.
+func (m *mapper) DeleteString(table string) string {
+	m.mustWritable()
+	s, err := m.deleteString(table)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (m *mapper) deleteString(table string) (string, error) {
+	pks := m.pkIndexesE()
+	if len(pks) == 0 {
+		return "", ErrNoPrimaryKey
+	}
+	return "DELETE FROM " + m.tableName(table) + m.pkWhere(pks, 1), nil
+}
+
+// KeyArgs returns the primary key values of rec, for [DeleteString] or
+// lookups by key, thus also from read only mappers.
+func (m *mapper) KeyArgs(rec any) []any {
+	keys, err := m.keyArgs(rec)
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}
+
+func (m *mapper) keyArgs(rec any) ([]any, error) {
+	v, p, err := m.structOf(rec)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]any, 0, 1)
+	for j := range m.fields {
+		if m.fields[j].opts.Has("pk") {
+			keys = append(keys, m.fieldValue(v, p, j))
+		}
+	}
+	return keys, nil
+}
+
+// pkIndexes returns the indexes of primary key columns, or panics with
+// [ErrNoPrimaryKey].
+func (m *mapper) pkIndexes() []int {
+	pks := m.pkIndexesE()
+	if len(pks) == 0 {
+		panic(ErrNoPrimaryKey)
+	}
+	return pks
+}
+
+// pkIndexesE returns the indexes of primary key columns, if any.
+func (m *mapper) pkIndexesE() []int {
+	var pks []int
+	for j, f := range m.fields {
+		if f.opts.Has("pk") {
+			pks = append(pks, j)
+		}
+	}
+	return pks
+}
+
+// pkWhere returns a WHERE clause on the primary key columns pks, and the
+// tenant column, numbering placeholders from n.
+func (m *mapper) pkWhere(pks []int, n int) string {
+	var b strings.Builder
+	for i, j := range pks {
+		if i == 0 {
+			b.WriteString(" WHERE ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		b.WriteString(m.cols[j] + "=" + m.castExpr(j, m.placeholder(n+i)))
+	}
+	if m.tenantCol != "" {
+		b.WriteString(" AND " + m.tenantCol + "=" + m.placeholder(n+len(pks)))
+	}
+	return b.String()
+}
+
+// placeholder returns the nth placeholder, counting from 1.
+func (m *mapper) placeholder(n int) string {
+	if m.Dialect == Postgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return string(m.Mark)
+}
+
+// placeholders returns count placeholders, numbered from n, separated as
+// columns are.
+func (m *mapper) placeholders(n, count int) string {
+	if m.Dialect != Postgres && n == 1 && count == len(m.cols) {
+		return m.allMarks()
+	}
+	var b strings.Builder
+	for i := range count {
+		if i > 0 {
+			b.WriteString(m.sep())
+		}
+		b.WriteString(m.placeholder(n + i))
+	}
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package mapper
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type buildUser struct {
+	ID    int64 `mapper:"id,pk"`
+	Name  string
+	Email string
+}
+
+func TestStatementStrings(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(buildUser{}, "*")
+
+	is.Equal(dut.SelectString("users"), "SELECT id,name,email FROM users")
+	is.Equal(dut.InsertString("users"), "INSERT INTO users (id,name,email) VALUES (?,?,?)")
+	is.Equal(dut.UpdateString("users"), "UPDATE users SET name=?,email=? WHERE id=?")
+	is.Equal(dut.DeleteString("users"), "DELETE FROM users WHERE id=?")
+
+	u := buildUser{1, "n", "e"}
+	is.Equal(dut.UpdateArgs(&u), []any{"n", "e", int64(1)})
+	is.Equal(dut.KeyArgs(u), []any{int64(1)})
+
+	pg := dut.With(WithDialect(Postgres), WithSeparator(", "))
+	is.Equal(pg.InsertString("users"), "INSERT INTO users (id, name, email) VALUES ($1, $2, $3)")
+	is.Equal(pg.UpdateString("users"), "UPDATE users SET name=$1, email=$2 WHERE id=$3")
+	is.Equal(pg.DeleteString("users"), "DELETE FROM users WHERE id=$1")
+}
+
+func TestStatementStringsKeys(t *testing.T) {
+	is := is.New(t)
+	type Membership struct {
+		UserID  int64 `mapper:"user_id,pk"`
+		GroupID int64 `mapper:"group_id,pk"`
+		Role    string
+	}
+	dut := Mapper(Membership{}, "*").SetOptions(WithDialect(Postgres))
+	is.Equal(dut.UpdateString("members"), "UPDATE members SET role=$1 WHERE user_id=$2 AND group_id=$3")
+	is.Equal(dut.UpdateArgs(Membership{1, 2, "admin"}), []any{"admin", int64(1), int64(2)})
+
+	defer func() {
+		is.Equal(recover(), ErrNoPrimaryKey)
+	}()
+	Mapper(Membership{}, "role").DeleteString("members")
+}
+
+func TestStatementStringsAuto(t *testing.T) {
+	is := is.New(t)
+	type Post struct {
+		ID        int64        `mapper:"id,pk"`
+		Title     string       `mapper:"title"`
+		CreatedAt time.Time    `mapper:"created_at,autocreate"`
+		UpdatedAt sql.NullTime `mapper:"updated_at,autoupdate"`
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	dut := Mapper(Post{}, "*").SetOptions(WithClock(func() time.Time { return now }))
+
+	is.Equal(dut.InsertString("posts"), "INSERT INTO posts (id,title,created_at,updated_at) VALUES (?,?,?,?)")
+	is.Equal(dut.UpdateString("posts"), "UPDATE posts SET title=?,updated_at=? WHERE id=?")
+
+	p := Post{ID: 1, Title: "t"}
+	is.Equal(dut.InsertArgs(&p), []any{int64(1), "t", now, sql.NullTime{Time: now, Valid: true}})
+	is.Equal(p.CreatedAt, now)
+
+	created := now.Add(-time.Hour)
+	p = Post{ID: 1, Title: "t", CreatedAt: created}
+	is.Equal(dut.InsertArgs(p)[2], created) // kept when set
+	is.Equal(dut.UpdateArgs(&p), []any{"t", sql.NullTime{Time: now, Valid: true}, int64(1)})
+	is.Equal(p.UpdatedAt.Time, now)
+
+	type Comment struct {
+		ID        int64      `mapper:"id,pk"`
+		Body      string     `mapper:"body"`
+		CreatedAt time.Time  `mapper:"created_at,autocreate=now()"`
+		UpdatedAt *time.Time `mapper:"updated_at,autoupdate=now()"`
+	}
+	db := Mapper(Comment{}, "*").SetOptions(WithDialect(Postgres))
+	is.Equal(db.InsertString("comments"), "INSERT INTO comments (id,body,created_at,updated_at) VALUES ($1,$2,now(),now())")
+	is.Equal(db.UpdateString("comments"), "UPDATE comments SET body=$1,updated_at=now() WHERE id=$2")
+	is.Equal(db.InsertArgs(Comment{ID: 1, Body: "b"}), []any{int64(1), "b"})
+	is.Equal(db.UpdateArgs(Comment{ID: 1, Body: "b"}), []any{"b", int64(1)})
+
+	type Bad struct {
+		CreatedAt string `mapper:"created_at,autocreate"`
+	}
+	_, err := MapperE(Bad{}, "*")
+	is.True(err != nil)
+}
+
+func TestSelectDistinct(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(buildUser{}, "*")
+	is.Equal(dut.With(WithDistinct()).SelectString("users"), "SELECT DISTINCT id,name,email FROM users")
+	is.Equal(dut.With(WithDistinctOn("email", "name")).SelectString("users"), "SELECT DISTINCT ON (email,name) id,name,email FROM users")
+
+	_, err := MapperWithOptionsE(buildUser{}, []MapperOption{WithDistinctOn("mail")}, "*")
+	var missing *ErrMissingColumns
+	is.True(errors.As(err, &missing))
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+	dut.With(WithDistinctOn("mail"))
+}
+
+func TestStatementStringsExpr(t *testing.T) {
+	is := is.New(t)
+	type Line struct {
+		ID    int64   `mapper:"id,pk"`
+		Price float64 `mapper:"price"`
+		Qty   int64   `mapper:"qty"`
+		Total float64 `mapper:"total,expr=price*qty"`
+	}
+	dut := Mapper(Line{}, "*").SetOptions(WithTable("lines"))
+	is.Equal(dut.SelectString(""), "SELECT id,price,qty,price*qty AS total FROM lines")
+	is.Equal(dut.InsertString(""), "INSERT INTO lines (id,price,qty) VALUES (?,?,?)")
+	is.Equal(dut.UpdateString(""), "UPDATE lines SET price=?,qty=? WHERE id=?")
+	l := Line{1, 2.5, 2, 5}
+	is.Equal(dut.InsertArgs(l), []any{int64(1), 2.5, int64(2)})
+	is.Equal(dut.UpdateArgs(l), []any{2.5, int64(2), int64(1)})
+	is.Equal(dut.NamedMarks(), ":id,:price,:qty")
+	is.Equal(dut.NamedSetString(), "id=:id,price=:price,qty=:qty")
+	is.Equal(dut.ValuesMap(l), map[string]any{"id": int64(1), "price": 2.5, "qty": int64(2)})
+	is.Equal(dut.SquirrelSetMap(l), map[string]any{"id": int64(1), "price": 2.5, "qty": int64(2)})
+	is.Equal(dut.With(WithDialect(Postgres)).CreateTableString(""), "CREATE TABLE lines (\n  id bigint,\n  price double precision,\n  qty bigint,\n  PRIMARY KEY (id)\n)")
+
+	type Stats struct {
+		Country string `mapper:"country"`
+		Users   int64  `mapper:"users,expr=count(*)"`
+	}
+	stats := Mapper(Stats{}, "*")
+	is.Equal(stats.SelectString("users")+stats.GroupBy("country"), "SELECT country,count(*) AS users FROM users GROUP BY country")
+}
+
+func TestStatementStringsCast(t *testing.T) {
+	is := is.New(t)
+	type Order struct {
+		ID     string `mapper:"id,pk,cast=uuid"`
+		Status string `mapper:"status,cast=order_status"`
+		Note   string `mapper:"note"`
+	}
+	pg := MapperWithOptions(Order{}, []MapperOption{WithDialect(Postgres), WithTable("orders")}, "*")
+	is.Equal(pg.SelectString(""), "SELECT id::uuid,status::order_status,note FROM orders")
+	is.Equal(pg.InsertString(""), "INSERT INTO orders (id,status,note) VALUES ($1::uuid,$2::order_status,$3)")
+	is.Equal(pg.UpdateString(""), "UPDATE orders SET status=$1::order_status,note=$2 WHERE id=$3::uuid")
+
+	my := pg.With(WithDialect(MySQL))
+	is.Equal(my.SelectString(""), "SELECT CAST(id AS uuid) AS id,CAST(status AS order_status) AS status,note FROM orders")
+	is.Equal(my.InsertString(""), "INSERT INTO orders (id,status,note) VALUES (CAST(? AS uuid),CAST(? AS order_status),?)")
+
+	type Bad struct {
+		At string `mapper:"at,cast=text); DROP TABLE x; --"`
+	}
+	_, err := MapperE(Bad{}, "*")
+	is.True(err != nil)
+
+	for _, typ := range []string{"varchar(20)", "numeric(12, 2)", "timestamp with time zone", "public.order_status"} {
+		is.NoErr(checkCast(TagOptions{"cast": typ}))
+	}
+	for _, typ := range []string{"text) OR (true", "text(", "numeric(a)", "numeric(1,2,3)", "a..b", "text -- x", "(1)"} {
+		is.True(checkCast(TagOptions{"cast": typ}) != nil) // typ
+	}
+}
@@ -0,0 +1,305 @@
+package mapper
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// BulkUpdate updates the rows of recs, a slice of structs or struct
+// pointers, in table in as few round trips as it can, found by keyCols,
+// the primary key by default. The [Postgres] dialect joins a VALUES list:
+//
+//	UPDATE users SET name=_v.name FROM (VALUES ($1::bigint,$2::text),($3,$4)) AS _v(id,name) WHERE users.id=_v.id
+//
+// whose first row is cast to the column types, see [CreateTableString],
+// while others use CASE expressions:
+//
+//	UPDATE users SET name=CASE WHEN id=? THEN ? WHEN id=? THEN ? ELSE name END WHERE id IN (?,?)
+//
+// Columns are set as with [Update], autoupdate fields stamped, and
+// statements are split under the limits of the dialect, see
+// [BatchOptions]. It returns the rows affected, and a [BatchError] per
+// failed statement.
+func (m *mapper) BulkUpdate(ctx context.Context, db Execer, table string, recs any, keyCols ...string) (int64, error) {
+	keys, err := m.keyIndexes(keyCols)
+	if err != nil {
+		return 0, err
+	}
+	var sets []int // columns set from records
+	var exprs []string
+	for j, col := range m.cols {
+		if m.fields[j].opts.Has("pk") || m.createdOnly(j) || m.readOnlyColumn(j) || slices.Contains(keys, j) {
+			continue
+		}
+		if expr := m.autoExpr(j, true); expr != "" {
+			exprs = append(exprs, col+"="+expr)
+			continue
+		}
+		sets = append(sets, j)
+	}
+	if len(sets)+len(exprs) == 0 {
+		return 0, ErrNoColumns
+	}
+	var tenant []any
+	if tenant, err = m.withTenant(ctx, nil); err != nil {
+		return 0, err
+	}
+	list, err := records(recs)
+	if err != nil {
+		return 0, err
+	}
+	rows := make([][]any, len(list))
+	for i, rec := range list {
+		if err := m.validate(rec); err != nil {
+			return 0, err
+		}
+		vals, err := m.stampedValues(rec, true)
+		if err != nil {
+			return 0, err
+		}
+		for _, j := range append(keys, sets...) {
+			rows[i] = append(rows[i], vals[j])
+		}
+	}
+
+	name := m.tableName(table)
+	if m.Dialect == Postgres {
+		return m.execBatch(ctx, db, name, rows, 0, len(tenant), BatchOptions{}, func(chunk [][]any) (string, []any) {
+			return m.bulkUpdateFrom(name, keys, sets, exprs, chunk, tenant)
+		})
+	}
+	perRow := len(keys) + len(sets)*(len(keys)+1)
+	return m.execBatch(ctx, db, name, rows, perRow, len(tenant), BatchOptions{}, func(chunk [][]any) (string, []any) {
+		return m.bulkUpdateCase(name, keys, sets, exprs, chunk, tenant)
+	})
+}
+
+// keyIndexes returns the indexes of columns cols, or of the primary key
+// when empty.
+func (m *mapper) keyIndexes(cols []string) ([]int, error) {
+	if len(cols) == 0 {
+		if pks := m.pkIndexesE(); len(pks) > 0 {
+			return pks, nil
+		}
+		return nil, ErrNoPrimaryKey
+	}
+	if missing := m.unknown(cols); len(missing) > 0 {
+		return nil, &ErrMissingColumns{Cols: missing}
+	}
+	keys := make([]int, len(cols))
+	for i, col := range cols {
+		keys[i] = fieldSlice(m.cols).index(col)
+	}
+	return keys, nil
+}
+
+// bulkUpdateFrom returns an UPDATE ... FROM (VALUES ...) statement of
+// chunk, records holding the values of keys then sets.
+func (m *mapper) bulkUpdateFrom(table string, keys, sets []int, exprs []string, chunk [][]any, tenant []any) (string, []any) {
+	cols := append(keys[:len(keys):len(keys)], sets...)
+	var b strings.Builder
+	b.WriteString("UPDATE " + table + " SET ")
+	for i, j := range sets {
+		if i > 0 {
+			b.WriteString(m.sep())
+		}
+		b.WriteString(m.cols[j] + "=_v." + m.cols[j])
+	}
+	for i, expr := range exprs {
+		if i > 0 || len(sets) > 0 {
+			b.WriteString(m.sep())
+		}
+		b.WriteString(expr)
+	}
+	b.WriteString(" FROM (VALUES ")
+	var args []any
+	for r, row := range chunk {
+		if r > 0 {
+			b.WriteString(m.sep())
+		}
+		b.WriteString("(")
+		for i, j := range cols {
+			if i > 0 {
+				b.WriteString(m.sep())
+			}
+			args = append(args, row[i])
+			mark := m.castExpr(j, m.placeholder(len(args)))
+			if typ, err := m.columnTypeOf(j); r == 0 && mark == m.placeholder(len(args)) && err == nil {
+				mark += "::" + typ // VALUES types come from the first row
+			}
+			b.WriteString(mark)
+		}
+		b.WriteString(")")
+	}
+	b.WriteString(") AS _v(")
+	for i, j := range cols {
+		if i > 0 {
+			b.WriteString(m.sep())
+		}
+		b.WriteString(m.cols[j])
+	}
+	b.WriteString(") WHERE ")
+	for i, j := range keys {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		b.WriteString(table + "." + m.cols[j] + "=_v." + m.cols[j])
+	}
+	if len(tenant) > 0 {
+		args = append(args, tenant...)
+		b.WriteString(" AND " + table + "." + m.tenantCol + "=" + m.placeholder(len(args)))
+	}
+	return b.String(), args
+}
+
+// bulkUpdateCase returns an UPDATE statement of chunk setting columns with
+// CASE expressions, records holding the values of keys then sets.
+func (m *mapper) bulkUpdateCase(table string, keys, sets []int, exprs []string, chunk [][]any, tenant []any) (string, []any) {
+	var b strings.Builder
+	var args []any
+	// match writes the condition on the keys of row.
+	match := func(row []any) {
+		if len(keys) > 1 {
+			b.WriteString("(")
+		}
+		for i, j := range keys {
+			if i > 0 {
+				b.WriteString(" AND ")
+			}
+			args = append(args, row[i])
+			b.WriteString(m.cols[j] + "=" + m.castExpr(j, m.placeholder(len(args))))
+		}
+		if len(keys) > 1 {
+			b.WriteString(")")
+		}
+	}
+	b.WriteString("UPDATE " + table + " SET ")
+	for s, j := range sets {
+		if s > 0 {
+			b.WriteString(m.sep())
+		}
+		b.WriteString(m.cols[j] + "=CASE")
+		for _, row := range chunk {
+			b.WriteString(" WHEN ")
+			match(row)
+			args = append(args, row[len(keys)+s])
+			b.WriteString(" THEN " + m.castExpr(j, m.placeholder(len(args))))
+		}
+		b.WriteString(" ELSE " + m.cols[j] + " END")
+	}
+	for i, expr := range exprs {
+		if i > 0 || len(sets) > 0 {
+			b.WriteString(m.sep())
+		}
+		b.WriteString(expr)
+	}
+	b.WriteString(" WHERE ")
+	m.keysIn(&b, keys, chunk, &args)
+	if len(tenant) > 0 {
+		args = append(args, tenant...)
+		b.WriteString(" AND " + m.tenantCol + "=" + m.placeholder(len(args)))
+	}
+	return b.String(), args
+}
+
+// keysIn writes an IN condition matching the keys of chunk, records
+// starting with the values of keys, appending them to args.
+func (m *mapper) keysIn(b *strings.Builder, keys []int, chunk [][]any, args *[]any) {
+	cols := make([]string, len(keys))
+	for i, j := range keys {
+		cols[i] = m.cols[j]
+	}
+	if len(keys) == 1 {
+		b.WriteString(cols[0] + " IN (")
+	} else {
+		b.WriteString("(" + strings.Join(cols, m.sep()) + ") IN (")
+	}
+	for r, row := range chunk {
+		if r > 0 {
+			b.WriteString(m.sep())
+		}
+		if len(keys) > 1 {
+			b.WriteString("(")
+		}
+		for i, j := range keys {
+			if i > 0 {
+				b.WriteString(m.sep())
+			}
+			*args = append(*args, row[i])
+			b.WriteString(m.castExpr(j, m.placeholder(len(*args))))
+		}
+		if len(keys) > 1 {
+			b.WriteString(")")
+		}
+	}
+	b.WriteString(")")
+}
+
+// BulkDelete deletes the rows of table whose primary key is in keys, a
+// slice of records, of []any holding the values of the key columns, or of
+// values for single column keys, passed as is:
+//
+//	users.BulkDelete(ctx, db, "", []int64{1, 2, 3})
+//	// DELETE FROM users WHERE id IN (?,?,?)
+//
+// Composite keys give (k1,k2) IN ((?,?),(?,?)) conditions. Statements are
+// split under the limits of the dialect, see [BatchOptions]. It returns
+// the rows affected, and a [BatchError] per failed statement.
+func (m *mapper) BulkDelete(ctx context.Context, db Execer, table string, keys any) (int64, error) {
+	if err := m.writable(); err != nil {
+		return 0, err
+	}
+	pks, err := m.keyIndexes(nil)
+	if err != nil {
+		return 0, err
+	}
+	tenant, err := m.withTenant(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	rv := reflect.ValueOf(keys)
+	if rv.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("keys of type %T not a slice", keys)
+	}
+	rows := make([][]any, rv.Len())
+	for i := range rows {
+		k := rv.Index(i)
+		if k.Kind() == reflect.Interface {
+			k = k.Elem()
+		}
+		switch t := k.Interface().(type) {
+		case []any:
+			rows[i] = t
+		default:
+			if e := reflect.Indirect(k); !e.IsValid() || e.Kind() != reflect.Struct || m.checkType(e.Type()) != nil {
+				rows[i] = []any{t}
+				break
+			}
+			vals, err := m.ValuesE(t)
+			if err != nil {
+				return 0, err
+			}
+			for _, j := range pks {
+				rows[i] = append(rows[i], vals[j])
+			}
+		}
+		if len(rows[i]) != len(pks) {
+			return 0, fmt.Errorf("key %d has %d values, primary key has %d columns", i, len(rows[i]), len(pks))
+		}
+	}
+	name := m.tableName(table)
+	return m.execBatch(ctx, db, name, rows, len(pks), len(tenant), BatchOptions{}, func(chunk [][]any) (string, []any) {
+		var b strings.Builder
+		var args []any
+		b.WriteString("DELETE FROM " + name + " WHERE ")
+		m.keysIn(&b, pks, chunk, &args)
+		if len(tenant) > 0 {
+			args = append(args, tenant...)
+			b.WriteString(" AND " + m.tenantCol + "=" + m.placeholder(len(args)))
+		}
+		return b.String(), args
+	})
+}
@@ -0,0 +1,86 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestBulkUpdate(t *testing.T) {
+	is := is.New(t)
+	type Item struct {
+		ID    int64   `mapper:"id,pk"`
+		Name  string  `mapper:"name"`
+		Price float64 `mapper:"price"`
+	}
+	fc := &fakeConnector{}
+	db := sql.OpenDB(fc)
+	defer db.Close()
+	ctx := context.Background()
+	items := []Item{{1, "a", 1.5}, {2, "b", 2.5}}
+
+	pg := Mapper(Item{}, "*").SetOptions(WithDialect(Postgres), WithTable("items"))
+	n, err := pg.BulkUpdate(ctx, db, "", items)
+	is.NoErr(err)
+	is.Equal(n, int64(1))
+	is.Equal(fc.lastCall().query, "UPDATE items SET name=_v.name,price=_v.price FROM (VALUES ($1::bigint,$2::text,$3::double precision),($4,$5,$6)) AS _v(id,name,price) WHERE items.id=_v.id")
+	is.Equal(fc.lastCall().args, []driver.Value{int64(1), "a", 1.5, int64(2), "b", 2.5})
+
+	my := pg.With(WithDialect(MySQL))
+	_, err = my.BulkUpdate(ctx, db, "", items)
+	is.NoErr(err)
+	is.Equal(fc.lastCall().query, "UPDATE items SET name=CASE WHEN id=? THEN ? WHEN id=? THEN ? ELSE name END,price=CASE WHEN id=? THEN ? WHEN id=? THEN ? ELSE price END WHERE id IN (?,?)")
+	is.Equal(fc.lastCall().args, []driver.Value{int64(1), "a", int64(2), "b", int64(1), 1.5, int64(2), 2.5, int64(1), int64(2)})
+
+	_, err = my.BulkUpdate(ctx, db, "", items, "id", "name")
+	is.NoErr(err)
+	is.Equal(fc.lastCall().query, "UPDATE items SET price=CASE WHEN (id=? AND name=?) THEN ? WHEN (id=? AND name=?) THEN ? ELSE price END WHERE (id,name) IN ((?,?),(?,?))")
+
+	_, err = my.BulkUpdate(ctx, db, "", items, "nope")
+	is.True(err != nil)
+}
+
+func TestBulkDelete(t *testing.T) {
+	is := is.New(t)
+	type Item struct {
+		ID   int64  `mapper:"id,pk"`
+		Name string `mapper:"name"`
+	}
+	type Line struct {
+		Order int64 `mapper:"order_id,pk"`
+		No    int   `mapper:"no,pk"`
+	}
+	fc := &fakeConnector{}
+	db := sql.OpenDB(fc)
+	defer db.Close()
+	ctx := context.Background()
+
+	items := Mapper(Item{}, "*").SetOptions(WithDialect(Postgres), WithTable("items"))
+	_, err := items.BulkDelete(ctx, db, "", []int64{1, 2, 3})
+	is.NoErr(err)
+	is.Equal(fc.lastCall().query, "DELETE FROM items WHERE id IN ($1,$2,$3)")
+	_, err = items.BulkDelete(ctx, db, "", []*Item{{ID: 4}, {ID: 5}})
+	is.NoErr(err)
+	is.Equal(fc.lastCall().args, []driver.Value{int64(4), int64(5)})
+
+	lines := Mapper(Line{}, "*").SetOptions(WithTable("lines"))
+	_, err = lines.BulkDelete(ctx, db, "", [][]any{{int64(1), 1}, {int64(1), 2}})
+	is.NoErr(err)
+	is.Equal(fc.lastCall().query, "DELETE FROM lines WHERE (order_id,no) IN ((?,?),(?,?))")
+	is.Equal(fc.lastCall().args, []driver.Value{int64(1), int64(1), int64(1), int64(2)})
+	_, err = lines.BulkDelete(ctx, db, "", []Line{{1, 3}})
+	is.NoErr(err)
+	is.Equal(fc.lastCall().args, []driver.Value{int64(1), int64(3)})
+
+	fc.calls = nil
+	keys := make([]int64, 70000)
+	n, err := items.BulkDelete(ctx, db, "", keys)
+	is.NoErr(err)
+	is.Equal(n, int64(2)) // split under 65535 placeholders
+
+	_, err = lines.BulkDelete(ctx, db, "", []int64{1})
+	is.True(err != nil) // a value for a composite key
+}
@@ -0,0 +1,85 @@
+package mapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkCast returns an error when the cast= type of opts could be anything
+// but a type name, as varchar(20) or timestamp with time zone.
+func checkCast(opts TagOptions) error {
+	if !opts.Has("cast") {
+		return nil
+	}
+	typ := opts.Get("cast")
+	if strings.TrimSpace(typ) == "" {
+		return fmt.Errorf("cast without a type")
+	}
+	if !typeName(typ) {
+		return fmt.Errorf("cast to %q is not a type name", typ)
+	}
+	return nil
+}
+
+// typeName tells whether typ is a type name: words of possibly schema
+// qualified identifiers, optionally followed by a (n) or (n,m) modifier.
+func typeName(typ string) bool {
+	name, mods, ok := strings.Cut(typ, "(")
+	if ok {
+		mods, ok = strings.CutSuffix(mods, ")")
+		if !ok {
+			return false
+		}
+		n, m, two := strings.Cut(mods, ",")
+		if !digits(strings.TrimSpace(n)) || two && !digits(strings.TrimSpace(m)) {
+			return false
+		}
+	}
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return false
+	}
+	for _, w := range words {
+		for part := range strings.SplitSeq(w, ".") {
+			if !identifier(part) || strings.ContainsRune(part, '$') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// digits tells whether s is made of decimal digits only.
+func digits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// castExpr returns expr cast to the cast= type of column j, if any.
+func (m *mapper) castExpr(j int, expr string) string {
+	typ := m.fields[j].opts.Get("cast")
+	if typ == "" {
+		return expr
+	}
+	if m.Dialect == Postgres {
+		return expr + "::" + typ
+	}
+	return "CAST(" + expr + " AS " + typ + ")"
+}
+
+// hasCast tells whether some column is cast.
+func (m *mapper) hasCast() bool {
+	for _, f := range m.fields {
+		if f.opts.Has("cast") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package mapper
+
+import (
+	"bytes"
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// Change is a column whose value differs between two records, see
+// [Changes].
+type Change struct {
+	Column        string
+	Before, After any // values as sent to the database, nil for NULL
+}
+
+// Changes returns the columns whose values differ from before to after,
+// records of m, in column order, without touching a database, for change
+// data capture, cache invalidation or events:
+//
+//	for _, c := range users.Changes(old, u) {
+//	  bus.Publish(UserChanged{ID: u.ID, Column: c.Column, Value: c.After})
+//	}
+//
+// Before is nil for a creation, and after for a deletion, which change
+// every column not NULL. Fields are compared rather than values, so that
+// encrypted ones compare alike, while expressions are left out. It panics
+// when before or after are not records of m, see [ChangesE].
+func (m *mapper) Changes(before, after any) []Change {
+	res, err := m.ChangesE(before, after)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// ChangesE is like [Changes] but returns an error instead of panicking.
+func (m *mapper) ChangesE(before, after any) ([]Change, error) {
+	var recs [2]struct {
+		v reflect.Value
+		p unsafe.Pointer
+	}
+	for i, rec := range []any{before, after} {
+		if rec == nil {
+			continue
+		}
+		var err error
+		if recs[i].v, recs[i].p, err = m.structOf(rec); err != nil {
+			return nil, err
+		}
+	}
+	var res []Change
+	for j, col := range m.cols {
+		if m.isExpr(j) {
+			continue
+		}
+		c := Change{Column: col}
+		var fields [2]any
+		for i, vals := range []*any{&c.Before, &c.After} {
+			if r := recs[i]; r.v.IsValid() {
+				fields[i] = r.v.FieldByIndex(m.fields[j].Index).Interface()
+				*vals = nullOf(m.fieldValue(r.v, r.p, j))
+			}
+		}
+		if before != nil && after != nil && sameValue(fields[0], fields[1]) || c.Before == nil && c.After == nil {
+			continue
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// nullOf returns nil when v is written as NULL, and v otherwise.
+func nullOf(v any) any {
+	if v != nil && isNull(reflect.ValueOf(v)) {
+		return nil
+	}
+	return v
+}
+
+// sameValue tells whether field values a and b are alike.
+func sameValue(a, b any) bool {
+	switch a := a.(type) {
+	case time.Time:
+		b, ok := b.(time.Time)
+		return ok && a.Equal(b)
+	case []byte:
+		b, ok := b.([]byte)
+		return ok && bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(a, b)
+}
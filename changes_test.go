@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestChanges(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID    int64    `mapper:"id,pk"`
+		Name  string   `mapper:"name"`
+		Tags  []string `mapper:"tags"`
+		Email *string  `mapper:"email"`
+	}
+	users := Mapper(User{}, "*")
+	before := User{ID: 1, Name: "a", Tags: []string{"x"}}
+	after := before
+	after.Tags = []string{"x", "y"}
+	is.Equal(users.Changes(before, before), nil)
+
+	changes := users.Changes(&before, &after)
+	is.Equal(len(changes), 1)
+	is.Equal(changes[0].Column, "tags")
+	is.Equal(changes[0].Before, []string{"x"})
+	is.Equal(changes[0].After, []string{"x", "y"})
+	email := "a@b.c"
+	after.Email = &email
+	is.Equal(users.Changes(before, after)[1], Change{Column: "email", After: &email})
+
+	is.Equal(len(users.Changes(nil, before)), 3) // NULL email left out
+	is.Equal(users.Changes(before, nil)[0], Change{Column: "id", Before: int64(1)})
+	_, err := users.ChangesE(before, buildUser{})
+	is.True(err != nil)
+}
@@ -0,0 +1,94 @@
+package mapper
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Cipher encrypts the values of fields tagged encrypted, see [WithCipher].
+type Cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// WithCipher sets the cipher of fields tagged encrypted, which MUST be
+// strings or byte slices, stored as bytes:
+//
+//	type User struct {
+//	  ID  int64  `mapper:"id,pk"`
+//	  SSN string `mapper:"ssn,encrypted"`
+//	}
+//
+//	var users = Mapper(User{}, "*").SetOptions(WithCipher(keyring))
+//
+// [Values] then gives ciphertexts, and scans decrypt them. Without a
+// cipher, statements and scans fail. Encrypted columns cannot be
+// searched, as ciphertexts of a value differ each time.
+func WithCipher(c Cipher) MapperOption {
+	return func(m *mapper) {
+		m.cipher = c
+	}
+}
+
+// errNoCipher is returned when encrypted fields are used without a cipher.
+var errNoCipher = errors.New("encrypted field without a cipher, see WithCipher")
+
+// Keyring is an AES-GCM [Cipher] supporting key rotation: ciphertexts are
+// prefixed with the ID of their key, so values encrypted with retired keys
+// still decrypt while new ones use the current key.
+type Keyring struct {
+	current string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring returns a keyring of keys, by ID, encrypting with the key of
+// ID current. Keys MUST be 16, 24 or 32 bytes long, for AES-128, AES-192
+// or AES-256, and IDs cannot hold colons.
+func NewKeyring(current string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("no key %q", current)
+	}
+	k := &Keyring{current: current, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key ID %q", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		if k.aeads[id], err = cipher.NewGCM(block); err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+	}
+	return k, nil
+}
+
+// Encrypt returns the ID of the current key, a colon, a random nonce and
+// the sealed plaintext.
+func (k *Keyring) Encrypt(plaintext []byte) ([]byte, error) {
+	aead := k.aeads[k.current]
+	out := make([]byte, len(k.current)+1+aead.NonceSize(), len(k.current)+1+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	copy(out, k.current+":")
+	nonce := out[len(k.current)+1:]
+	rand.Read(nonce)
+	return aead.Seal(out, nonce, plaintext, []byte(k.current)), nil
+}
+
+// Decrypt opens ciphertext with the key it names.
+func (k *Keyring) Decrypt(ciphertext []byte) ([]byte, error) {
+	id, rest, ok := bytes.Cut(ciphertext, []byte(":"))
+	aead, known := k.aeads[string(id)]
+	if !ok || !known {
+		return nil, errors.New("ciphertext of unknown key")
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], id)
+}
@@ -0,0 +1,53 @@
+package mapper
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCipher(t *testing.T) {
+	is := is.New(t)
+	type Patient struct {
+		ID    int64  `mapper:"id,pk"`
+		SSN   string `mapper:"ssn,encrypted"`
+		Notes []byte `mapper:"notes,encrypted"`
+	}
+	old, err := NewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	is.NoErr(err)
+	dut := Mapper(Patient{}, "*").SetOptions(WithCipher(old))
+
+	vals := dut.Values(Patient{1, "123-45", nil})
+	is.Equal(vals[2], nil) // NULL stays NULL
+	c := vals[1].([]byte)
+	is.True(bytes.HasPrefix(c, []byte("k1:")))
+	is.True(!bytes.Contains(c, []byte("123-45")))
+
+	// Rotated: k2 encrypts, k1 still decrypts.
+	rotated, err := NewKeyring("k2", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32), "k2": bytes.Repeat([]byte{2}, 16)})
+	is.NoErr(err)
+	dut = dut.With(WithCipher(rotated))
+	is.True(bytes.HasPrefix(dut.Values(Patient{SSN: "x"})[1].([]byte), []byte("k2:")))
+
+	rows := queryFake(t, []string{"id", "ssn", "notes"}, []driver.Value{int64(1), c, nil})
+	is.True(rows.Next())
+	p, err := ScanRow[Patient](dut, rows)
+	is.NoErr(err)
+	is.Equal(p.SSN, "123-45")
+
+	rows = queryFake(t, []string{"id", "ssn", "notes"}, []driver.Value{int64(1), []byte("k3:garbage"), nil})
+	is.True(rows.Next())
+	_, err = ScanRow[Patient](dut, rows)
+	is.True(err != nil)
+
+	_, err = Mapper(Patient{}, "*").Values(Patient{})[1].(driver.Valuer).Value()
+	is.Equal(err, errNoCipher)
+
+	type Bad struct {
+		N int `mapper:"n,encrypted"`
+	}
+	_, err = MapperE(Bad{}, "*")
+	is.True(err != nil)
+}
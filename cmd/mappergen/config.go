@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// configFile is the name of the configuration file looked up in the output
+// directory when -config is not given.
+const configFile = "mappergen.json"
+
+// fileConfig is the content of a configuration file, setting the house
+// style of generated code:
+//
+//	{
+//	  "key": "db",
+//	  "fieldMapper": "snake",
+//	  "initialisms": ["SKU", "VAT"],
+//	  "nullable": "pointer",
+//	  "fields": {"usr_nm": "UserName"}
+//	}
+//
+// key and fieldMapper are the defaults of the -key and -fieldmapper flags,
+// which take precedence. initialisms are kept upper case in field names,
+// on top of the usual ones like ID or URL. nullable tells how to type NULL
+// columns, "sql" for sql.Null types, the default, or "pointer". fields sets
+// field names by column, overriding naming rules.
+type fileConfig struct {
+	Key         string            `json:"key"`
+	FieldMapper string            `json:"fieldMapper"`
+	Initialisms []string          `json:"initialisms"`
+	Nullable    string            `json:"nullable"`
+	Fields      map[string]string `json:"fields"`
+}
+
+// loadConfig applies the configuration file at path to cfg. A missing file
+// is fine unless required.
+func loadConfig(cfg *config, path string, required bool) error {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var fc fileConfig
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if fc.Key != "" {
+		cfg.key = fc.Key
+	}
+	if fc.FieldMapper != "" {
+		if cfg.fieldMapper, err = fieldMapperNamed(fc.FieldMapper); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	for _, s := range fc.Initialisms {
+		cfg.initialisms[strings.ToUpper(s)] = true
+	}
+	switch fc.Nullable {
+	case "", "sql":
+	case "pointer":
+		cfg.pointers = true
+	default:
+		return fmt.Errorf("%s: unknown nullable %q, want sql or pointer", path, fc.Nullable)
+	}
+	cfg.fields = fc.Fields
+	return nil
+}
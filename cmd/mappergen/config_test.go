@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dav-m85/mapper"
+	"github.com/matryer/is"
+)
+
+func TestLoadConfig(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, configFile)
+	is.NoErr(os.WriteFile(path, []byte(`{
+		"key": "db",
+		"initialisms": ["sku"],
+		"nullable": "pointer",
+		"fields": {"usr_nm": "UserName"}
+	}`), 0o644))
+
+	cfg := defaultConfig()
+	is.NoErr(loadConfig(cfg, path, true))
+	is.Equal(cfg.key, "db")
+	is.Equal(cfg.fieldName("item_sku"), "ItemSKU")
+	is.Equal(cfg.fieldName("usr_nm"), "UserName")
+	is.Equal(cfg.goType(mapper.TableColumn{Type: "text", Nullable: true}, mapper.Postgres), "*string")
+
+	src, err := generateTable(cfg, "app", "Item", "items", []mapper.TableColumn{{Name: "usr_nm", Type: "text"}}, mapper.Postgres)
+	is.NoErr(err)
+	is.Equal(string(src), `// Code generated by mappergen; DO NOT EDIT.
+
+package app
+
+import (
+	"github.com/dav-m85/mapper"
+)
+
+// Item is a row of table items.
+type Item struct {
+	UserName string `+"`db:\"usr_nm\"`"+`
+}
+
+// ItemMapper maps Item to table items.
+var ItemMapper = mapper.MapperWithKey(Item{}, "db", "*").SetOptions(mapper.WithDialect(mapper.Postgres))
+`)
+
+	is.NoErr(loadConfig(defaultConfig(), filepath.Join(dir, "none.json"), false))
+	is.True(loadConfig(defaultConfig(), filepath.Join(dir, "none.json"), true) != nil)
+	is.NoErr(os.WriteFile(path, []byte(`{"nulable": "pointer"}`), 0o644))
+	is.True(loadConfig(defaultConfig(), path, true) != nil) // unknown field
+}
+
+func TestRunConfig(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(dir, "user.go"), []byte(userSrc), 0o644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, configFile), []byte(`{"key": "db", "fieldMapper": "snake"}`), 0o644))
+
+	is.NoErr(run([]string{"-type", "User", dir}))
+	got, err := os.ReadFile(filepath.Join(dir, "user_mapper.go"))
+	is.NoErr(err)
+	is.Equal(string(got), userWant)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dav-m85/mapper"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// dialects are the supported database/sql drivers, by name.
+var dialects = map[string]mapper.Dialect{
+	"pgx":    mapper.Postgres,
+	"mysql":  mapper.MySQL,
+	"sqlite": mapper.SQLite,
+}
+
+// dialectNames are the names of the mapper dialect constants.
+var dialectNames = map[mapper.Dialect]string{
+	mapper.Postgres: "Postgres",
+	mapper.MySQL:    "MySQL",
+	mapper.SQLite:   "SQLite",
+}
+
+// fromDB returns the columns of table, introspected through driver and
+// dsn, and the dialect of driver.
+func fromDB(ctx context.Context, driver, dsn, table string) ([]mapper.TableColumn, mapper.Dialect, error) {
+	d, ok := dialects[driver]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown driver %q, want pgx, mysql or sqlite", driver)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, "", err
+	}
+	defer db.Close()
+	cols, err := mapper.TableColumns(ctx, db, d, table)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(cols) == 0 {
+		return nil, "", fmt.Errorf("table %s not found or without columns", table)
+	}
+	return cols, d, nil
+}
+
+// generateTable returns the source of struct typ holding a row of table,
+// and of a mapper of it.
+func generateTable(cfg *config, pkgName, typ, table string, cols []mapper.TableColumn, d mapper.Dialect) ([]byte, error) {
+	return generateRow(cfg, pkgName, typ, "table "+table, "", cols, d)
+}
+
+// generateRow returns the source of struct typ holding a row of columns
+// cols, described by source, and of a mapper of it. A non empty query is
+// written along as a constant.
+func generateRow(cfg *config, pkgName, typ, source, query string, cols []mapper.TableColumn, d mapper.Dialect) ([]byte, error) {
+	var fields bytes.Buffer
+	imports := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, c := range cols {
+		if seen[c.Name] {
+			return nil, fmt.Errorf("column %s appears more than once, alias it", c.Name)
+		}
+		seen[c.Name] = true
+		t := cfg.goType(c, d)
+		if pkg, _, ok := strings.Cut(t, "."); ok {
+			imports[map[string]string{"sql": "database/sql", "time": "time"}[pkg]] = true
+		}
+		tag := c.Name
+		if !safeColumn(c.Name) {
+			tag += ",raw" // an expression, as count(*)
+		}
+		if c.Generated {
+			tag += ",generated" // left out of writes
+		}
+		fmt.Fprintf(&fields, "\t%s %s `%s:%s`\n", cfg.fieldName(c.Name), t, cfg.key, strconv.Quote(tag))
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by mappergen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	for _, imp := range []string{"database/sql", "time"} {
+		if imports[imp] {
+			fmt.Fprintf(&b, "\t%q\n", imp)
+		}
+	}
+	b.WriteString("\n\t\"github.com/dav-m85/mapper\"\n)\n\n")
+	if query != "" {
+		lit := "`" + query + "`"
+		if strings.Contains(query, "`") {
+			lit = strconv.Quote(query)
+		}
+		fmt.Fprintf(&b, "// %[1]sQuery is the query returning %[1]s rows.\nconst %[1]sQuery = %[2]s\n\n", typ, lit)
+	}
+	fmt.Fprintf(&b, "// %[1]s is a row of %[2]s.\ntype %[1]s struct {\n%[3]s}\n\n", typ, source, fields.String())
+	if cfg.key == "mapper" {
+		fmt.Fprintf(&b, "// %[1]sMapper maps %[1]s to %[2]s.\nvar %[1]sMapper = mapper.MapperWithOptions(%[1]s{}, []mapper.MapperOption{mapper.WithDialect(mapper.%[3]s)}, \"*\")\n", typ, source, dialectNames[d])
+	} else {
+		fmt.Fprintf(&b, "// %[1]sMapper maps %[1]s to %[2]s.\nvar %[1]sMapper = mapper.MapperWithKey(%[1]s{}, %[4]q, \"*\").SetOptions(mapper.WithDialect(mapper.%[3]s))\n", typ, source, dialectNames[d], cfg.key)
+	}
+	return format.Source(b.Bytes())
+}
+
+// safeColumn tells whether col is a plain word, which mapper accepts as is.
+func safeColumn(col string) bool {
+	for _, r := range col {
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return col != ""
+}
+
+// goType returns the Go type of column c. NULL columns get sql.Null types,
+// or pointers if so configured. Unknown types are strings.
+func (cfg *config) goType(c mapper.TableColumn, d mapper.Dialect) string {
+	has := func(words ...string) bool {
+		for _, w := range words {
+			if strings.Contains(c.Type, w) {
+				return true
+			}
+		}
+		return false
+	}
+	var t string
+	switch {
+	case has("bool"), d == mapper.MySQL && strings.HasPrefix(c.Type, "tinyint(1)"):
+		t = "bool"
+	case has("bytea", "blob", "binary"):
+		return "[]byte" // nil is NULL
+	case has("interval", "point"):
+		t = "string"
+	case has("timestamp", "date", "time"):
+		t = "time.Time"
+	case has("char", "text", "clob", "uuid", "json", "numeric", "decimal", "enum"):
+		t = "string"
+	case has("bigint", "int8", "bigserial"), d == mapper.SQLite && has("int"):
+		t = "int64"
+	case has("smallint", "int2", "smallserial"):
+		t = "int16"
+	case has("int", "serial"):
+		t = "int32"
+	case has("double", "float8"), d != mapper.Postgres && has("real"), d == mapper.Postgres && has("float"):
+		t = "float64"
+	case has("real", "float"):
+		t = "float32"
+	default:
+		t = "string"
+	}
+	if !c.Nullable {
+		return t
+	}
+	if cfg.pointers {
+		return "*" + t
+	}
+	switch t {
+	case "bool":
+		return "sql.NullBool"
+	case "time.Time":
+		return "sql.NullTime"
+	case "string":
+		return "sql.NullString"
+	case "int64":
+		return "sql.NullInt64"
+	case "int32":
+		return "sql.NullInt32"
+	case "int16":
+		return "sql.NullInt16"
+	case "float64":
+		return "sql.NullFloat64"
+	}
+	return "*" + t
+}
+
+// defaultInitialisms are kept upper case in field names.
+var defaultInitialisms = []string{
+	"API", "CPU", "CSS", "DNS", "HTML", "HTTP", "ID", "IP", "JSON", "SQL",
+	"TTL", "UI", "URI", "URL", "UUID", "XML",
+}
+
+// fieldName returns an exported Go name for column col, as UserID for
+// user_id, unless configured otherwise.
+func (cfg *config) fieldName(col string) string {
+	if name, ok := cfg.fields[col]; ok {
+		return name
+	}
+	return cfg.goName(col)
+}
+
+// goName returns an exported Go name made of the words of s.
+func (cfg *config) goName(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if up := strings.ToUpper(part); cfg.initialisms[up] {
+			b.WriteString(up)
+			continue
+		}
+		rs := []rune(part)
+		b.WriteRune(unicode.ToUpper(rs[0]))
+		b.WriteString(string(rs[1:]))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// typeName returns a struct name for table, singular when it looks plural,
+// as User for users.
+func (cfg *config) typeName(table string) string {
+	if _, name, ok := strings.Cut(table, "."); ok {
+		table = name
+	}
+	switch {
+	case strings.HasSuffix(table, "ies"):
+		table = strings.TrimSuffix(table, "ies") + "y"
+	case strings.HasSuffix(table, "s") && !strings.HasSuffix(table, "ss") && !strings.HasSuffix(table, "us"):
+		table = strings.TrimSuffix(table, "s")
+	}
+	return cfg.goName(table)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/dav-m85/mapper"
+	"github.com/matryer/is"
+)
+
+const usersWant = `// Code generated by mappergen; DO NOT EDIT.
+
+package app
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/dav-m85/mapper"
+)
+
+// User is a row of table users.
+type User struct {
+	ID        int64          ` + "`mapper:\"id\"`" + `
+	UserURL   sql.NullString ` + "`mapper:\"user_url\"`" + `
+	Score     *float32       ` + "`mapper:\"score\"`" + `
+	CreatedAt time.Time      ` + "`mapper:\"created_at\"`" + `
+	Avatar    []byte         ` + "`mapper:\"avatar\"`" + `
+	Total     float64        ` + "`mapper:\"total,generated\"`" + `
+}
+
+// UserMapper maps User to table users.
+var UserMapper = mapper.MapperWithOptions(User{}, []mapper.MapperOption{mapper.WithDialect(mapper.Postgres)}, "*")
+`
+
+func TestGenerateTable(t *testing.T) {
+	is := is.New(t)
+	src, err := generateTable(defaultConfig(), "app", defaultConfig().typeName("public.users"), "users", []mapper.TableColumn{
+		{Name: "id", Type: "bigint"},
+		{Name: "user_url", Type: "character varying", Nullable: true},
+		{Name: "score", Type: "real", Nullable: true},
+		{Name: "created_at", Type: "timestamp with time zone"},
+		{Name: "avatar", Type: "bytea", Nullable: true},
+		{Name: "total", Type: "double precision", Generated: true},
+	}, mapper.Postgres)
+	is.NoErr(err)
+	is.Equal(string(src), usersWant)
+}
+
+func TestGoType(t *testing.T) {
+	is := is.New(t)
+	for _, c := range []struct {
+		typ  string
+		d    mapper.Dialect
+		want string
+	}{
+		{"integer", mapper.SQLite, "int64"},
+		{"integer", mapper.Postgres, "int32"},
+		{"tinyint(1)", mapper.MySQL, "bool"},
+		{"double precision", mapper.Postgres, "float64"},
+		{"interval", mapper.Postgres, "string"},
+		{"datetime", mapper.MySQL, "time.Time"},
+		{"numeric(12,2)", mapper.Postgres, "string"},
+	} {
+		is.Equal(defaultConfig().goType(mapper.TableColumn{Type: c.typ}, c.d), c.want) // c.typ
+	}
+	is.Equal(defaultConfig().typeName("categories"), "Category")
+	is.Equal(defaultConfig().typeName("status"), "Status")
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"math/big"
+	"net"
+	"net/netip"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dav-m85/mapper"
+)
+
+// config holds the mapper settings to generate code for, and the naming
+// conventions of generated structs.
+type config struct {
+	columns     []string
+	key         string
+	fieldMapper mapper.FieldMapper
+
+	initialisms map[string]bool   // kept upper case in field names
+	fields      map[string]string // field names by column, overriding rules
+	pointers    bool              // NULL columns as pointers rather than sql.Null types
+}
+
+// defaultConfig returns the configuration used when no file or flag says
+// otherwise.
+func defaultConfig() *config {
+	cfg := &config{
+		columns:     []string{"*"},
+		key:         "mapper",
+		fieldMapper: strings.ToLower,
+		initialisms: make(map[string]bool),
+	}
+	for _, s := range defaultInitialisms {
+		cfg.initialisms[s] = true
+	}
+	return cfg
+}
+
+func fieldMapperNamed(name string) (mapper.FieldMapper, error) {
+	switch name {
+	case "lower":
+		return strings.ToLower, nil
+	case "snake":
+		return mapper.SnakeCase, nil
+	case "direct":
+		return func(s string) string { return s }, nil
+	}
+	return nil, fmt.Errorf("unknown field mapper %q, want lower, snake or direct", name)
+}
+
+// pkg is a parsed package, test files excluded.
+type pkg struct {
+	name  string
+	files []*ast.File
+}
+
+func parsePackage(dir string) (*pkg, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	p := &pkg{}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if p.name == "" {
+			p.name = f.Name.Name
+		}
+		if f.Name.Name == p.name {
+			p.files = append(p.files, f)
+		}
+	}
+	if p.name == "" {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return p, nil
+}
+
+// lookup returns the struct type named name, and the file declaring it.
+func (p *pkg) lookup(name string) (*ast.StructType, *ast.File, error) {
+	ts, f := p.typeSpec(name)
+	if ts == nil {
+		return nil, nil, fmt.Errorf("type %s not found in package %s", name, p.name)
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not a struct", name)
+	}
+	return st, f, nil
+}
+
+// typeSpec returns the declaration of the type named name, and its file,
+// or nil if p declares none.
+func (p *pkg) typeSpec(name string) (*ast.TypeSpec, *ast.File) {
+	for _, f := range p.files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+					return ts, f
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// mappedField is a struct field and its column.
+type mappedField struct {
+	name, col string
+}
+
+// resolve maps the fields of st, declared in file f, to columns. It builds
+// a struct with the same field names, tags and, as far as typeOf tells,
+// types at runtime, so mapper itself resolves and checks them.
+func (p *pkg) resolve(name string, st *ast.StructType, f *ast.File, cfg *config) ([]mappedField, error) {
+	r := &resolver{pkg: p, cfg: cfg, seen: map[string]bool{name: true}}
+	target := reflect.New(r.structOf(f, st)).Interface()
+	m, err := mapper.MapperWithOptionsE(target, []mapper.MapperOption{mapper.WithFieldMapper(cfg.fieldMapper)}, cfg.columns...)
+	if err != nil {
+		if inner := errors.Unwrap(err); inner != nil {
+			err = inner // drop the synthetic type name
+		}
+		return nil, fmt.Errorf("mapping %s: %w", name, err)
+	}
+	var res []mappedField
+	for _, f := range m.Fields() {
+		if err := checkConverted(f); err != nil {
+			return nil, fmt.Errorf("mapping %s: %s: %w", name, f.Name, err)
+		}
+		res = append(res, mappedField{name: f.Name, col: f.Column})
+	}
+	return res, nil
+}
+
+// convertedOptions are the tag options of fields mappers convert when
+// scanning and binding them, which generated code does not.
+var convertedOptions = []string{"bitmask", "compress", "encrypted", "gob", "tz", "uuid"}
+
+// convertedTypes are the types mapper registers converters for.
+var convertedTypes = map[reflect.Type]bool{
+	reflect.TypeFor[big.Rat]():          true,
+	reflect.TypeFor[big.Int]():          true,
+	reflect.TypeFor[net.IP]():           true,
+	reflect.TypeFor[net.HardwareAddr](): true,
+	reflect.TypeFor[netip.Addr]():       true,
+	reflect.TypeFor[netip.Prefix]():     true,
+}
+
+// checkConverted returns an error if f needs a runtime conversion.
+func checkConverted(f mapper.FieldInfo) error {
+	for _, o := range convertedOptions {
+		if f.Options.Has(o) {
+			return fmt.Errorf("%s fields are converted at runtime, use a mapper", o)
+		}
+	}
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if convertedTypes[t] {
+		return fmt.Errorf("%s fields are converted at runtime, use a mapper", t)
+	}
+	return nil
+}
+
+// resolver builds the runtime types of package declarations.
+type resolver struct {
+	*pkg
+	cfg  *config
+	seen map[string]bool // types being built, which recursive types refer to
+}
+
+// structOf returns a struct type with the exported fields of st, their
+// tags under the mapper key.
+func (r *resolver) structOf(f *ast.File, st *ast.StructType) reflect.Type {
+	var sfs []reflect.StructField
+	for _, fd := range st.Fields.List {
+		names := fd.Names
+		if len(names) == 0 { // embedded, named after its type
+			names = []*ast.Ident{ast.NewIdent(embeddedName(fd.Type))}
+		}
+		var tag string
+		if fd.Tag != nil {
+			s, _ := strconv.Unquote(fd.Tag.Value)
+			tag = reflect.StructTag(s).Get(r.cfg.key)
+		}
+		typ := r.typeOf(f, fd.Type)
+		for _, n := range names {
+			if !n.IsExported() {
+				continue
+			}
+			sfs = append(sfs, reflect.StructField{
+				Name: n.Name,
+				Type: typ,
+				Tag:  reflect.StructTag(`mapper:` + strconv.Quote(tag)),
+			})
+		}
+	}
+	return reflect.StructOf(sfs)
+}
+
+// typeOf returns the type of x, as declared in file f, without type
+// checking the package: builtin types, types composed of them, types
+// declared in the package, by their underlying type, and the standard
+// library types of knownTypes. Others are any.
+func (r *resolver) typeOf(f *ast.File, x ast.Expr) reflect.Type {
+	switch t := x.(type) {
+	case *ast.Ident:
+		if typ, ok := builtinTypes[t.Name]; ok {
+			return typ
+		}
+		ts, tf := r.typeSpec(t.Name)
+		if ts == nil || r.seen[t.Name] {
+			return anyType
+		}
+		r.seen[t.Name] = true
+		defer delete(r.seen, t.Name)
+		if st, ok := ts.Type.(*ast.StructType); ok {
+			return r.structOf(tf, st)
+		}
+		return r.typeOf(tf, ts.Type)
+	case *ast.ParenExpr:
+		return r.typeOf(f, t.X)
+	case *ast.StarExpr:
+		return reflect.PointerTo(r.typeOf(f, t.X))
+	case *ast.ArrayType:
+		elem := r.typeOf(f, t.Elt)
+		if t.Len == nil {
+			return reflect.SliceOf(elem)
+		}
+		lit, ok := t.Len.(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			return anyType
+		}
+		n, err := strconv.Atoi(lit.Value)
+		if err != nil {
+			return anyType
+		}
+		return reflect.ArrayOf(n, elem)
+	case *ast.MapType:
+		key := r.typeOf(f, t.Key)
+		if !key.Comparable() {
+			return anyType
+		}
+		return reflect.MapOf(key, r.typeOf(f, t.Value))
+	case *ast.StructType:
+		return r.structOf(f, t)
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return anyType
+		}
+		if typ, ok := knownTypes[importPath(f, pkg.Name)+"."+t.Sel.Name]; ok {
+			return typ
+		}
+	}
+	return anyType
+}
+
+// importPath returns the path of the package imported as name by f.
+func importPath(f *ast.File, name string) string {
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil && imp.Name.Name == name || imp.Name == nil && path.Base(p) == name {
+			return p
+		}
+	}
+	return name
+}
+
+var anyType = reflect.TypeFor[any]()
+
+var builtinTypes = map[string]reflect.Type{
+	"any":        anyType,
+	"bool":       reflect.TypeFor[bool](),
+	"byte":       reflect.TypeFor[byte](),
+	"complex64":  reflect.TypeFor[complex64](),
+	"complex128": reflect.TypeFor[complex128](),
+	"float32":    reflect.TypeFor[float32](),
+	"float64":    reflect.TypeFor[float64](),
+	"int":        reflect.TypeFor[int](),
+	"int8":       reflect.TypeFor[int8](),
+	"int16":      reflect.TypeFor[int16](),
+	"int32":      reflect.TypeFor[int32](),
+	"int64":      reflect.TypeFor[int64](),
+	"rune":       reflect.TypeFor[rune](),
+	"string":     reflect.TypeFor[string](),
+	"uint":       reflect.TypeFor[uint](),
+	"uint8":      reflect.TypeFor[uint8](),
+	"uint16":     reflect.TypeFor[uint16](),
+	"uint32":     reflect.TypeFor[uint32](),
+	"uint64":     reflect.TypeFor[uint64](),
+	"uintptr":    reflect.TypeFor[uintptr](),
+}
+
+// knownTypes are the standard library types fields commonly have, by
+// import path and name.
+var knownTypes = map[string]reflect.Type{
+	"database/sql.NullBool":    reflect.TypeFor[sql.NullBool](),
+	"database/sql.NullByte":    reflect.TypeFor[sql.NullByte](),
+	"database/sql.NullFloat64": reflect.TypeFor[sql.NullFloat64](),
+	"database/sql.NullInt16":   reflect.TypeFor[sql.NullInt16](),
+	"database/sql.NullInt32":   reflect.TypeFor[sql.NullInt32](),
+	"database/sql.NullInt64":   reflect.TypeFor[sql.NullInt64](),
+	"database/sql.NullString":  reflect.TypeFor[sql.NullString](),
+	"database/sql.NullTime":    reflect.TypeFor[sql.NullTime](),
+	"encoding/json.RawMessage": reflect.TypeFor[json.RawMessage](),
+	"math/big.Int":             reflect.TypeFor[big.Int](),
+	"math/big.Rat":             reflect.TypeFor[big.Rat](),
+	"net.HardwareAddr":         reflect.TypeFor[net.HardwareAddr](),
+	"net.IP":                   reflect.TypeFor[net.IP](),
+	"net/netip.Addr":           reflect.TypeFor[netip.Addr](),
+	"net/netip.Prefix":         reflect.TypeFor[netip.Prefix](),
+	"time.Duration":            reflect.TypeFor[time.Duration](),
+	"time.Time":                reflect.TypeFor[time.Time](),
+}
+
+func embeddedName(x ast.Expr) string {
+	switch t := x.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// generate returns the source of mappers for types of p.
+func generate(p *pkg, types []string, cfg *config) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by mappergen; DO NOT EDIT.\n\npackage %s\n\nimport \"slices\"\n", p.name)
+	for _, name := range types {
+		st, f, err := p.lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		fields, err := p.resolve(name, st, f, cfg)
+		if err != nil {
+			return nil, err
+		}
+		writeMapper(&b, name, fields)
+	}
+	return format.Source(b.Bytes())
+}
+
+func writeMapper(b *bytes.Buffer, name string, fields []mappedField) {
+	typ := name + "Mapper"
+	colsVar := strings.ToLower(typ[:1]) + typ[1:] + "Columns"
+	cols := make([]string, len(fields))
+	quoted := make([]string, len(fields))
+	for j, f := range fields {
+		cols[j] = f.col
+		quoted[j] = strconv.Quote(f.col)
+	}
+	field := func(expr string) string {
+		s := make([]string, len(fields))
+		for j, f := range fields {
+			s[j] = fmt.Sprintf(expr, f.name)
+		}
+		return strings.Join(s, ", ")
+	}
+
+	fmt.Fprintf(b, `
+// %[1]s maps %[2]s to columns %[3]s.
+type %[1]s struct{}
+
+var %[9]s = []string{%[4]s}
+
+// Columns returns a copy of the mapped columns.
+func (%[1]s) Columns() []string {
+	return slices.Clone(%[9]s)
+}
+
+// ColumnsString returns the mapped columns, comma separated.
+func (%[1]s) ColumnsString() string {
+	return %[5]q
+}
+
+// Marks returns a placeholder per column, comma separated.
+func (%[1]s) Marks() string {
+	return %[6]q
+}
+
+// Addrs returns the addresses of the mapped fields of dest, for Scan.
+func (%[1]s) Addrs(dest *%[2]s) []any {
+	return []any{%[7]s}
+}
+
+// Values returns the mapped fields of rec, for Exec.
+func (%[1]s) Values(rec *%[2]s) []any {
+	return []any{%[8]s}
+}
+`, typ, name, strings.Join(cols, ","), strings.Join(quoted, ", "),
+		strings.Join(cols, ","), strings.Repeat(",?", len(cols))[1:],
+		field("&dest.%s"), field("rec.%s"), colsVar)
+}
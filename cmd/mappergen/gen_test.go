@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const userSrc = `package app
+
+type User struct {
+	ID        int
+	FirstName string ` + "`db:\"name\"`" + `
+	Password  string ` + "`db:\",ignore\"`" + `
+	CreatedAt int64
+	secret    string
+}
+`
+
+const userWant = `// Code generated by mappergen; DO NOT EDIT.
+
+package app
+
+import "slices"
+
+// UserMapper maps User to columns id,name,created_at.
+type UserMapper struct{}
+
+var userMapperColumns = []string{"id", "name", "created_at"}
+
+// Columns returns a copy of the mapped columns.
+func (UserMapper) Columns() []string {
+	return slices.Clone(userMapperColumns)
+}
+
+// ColumnsString returns the mapped columns, comma separated.
+func (UserMapper) ColumnsString() string {
+	return "id,name,created_at"
+}
+
+// Marks returns a placeholder per column, comma separated.
+func (UserMapper) Marks() string {
+	return "?,?,?"
+}
+
+// Addrs returns the addresses of the mapped fields of dest, for Scan.
+func (UserMapper) Addrs(dest *User) []any {
+	return []any{&dest.ID, &dest.FirstName, &dest.CreatedAt}
+}
+
+// Values returns the mapped fields of rec, for Exec.
+func (UserMapper) Values(rec *User) []any {
+	return []any{rec.ID, rec.FirstName, rec.CreatedAt}
+}
+`
+
+func TestRun(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(dir, "user.go"), []byte(userSrc), 0o644))
+
+	is.NoErr(run([]string{"-type", "User", "-key", "db", "-fieldmapper", "snake", dir}))
+	got, err := os.ReadFile(filepath.Join(dir, "user_mapper.go"))
+	is.NoErr(err)
+	is.Equal(string(got), userWant)
+
+	err = run([]string{"-type", "User", "-columns", "id,nope", dir})
+	is.Equal(err.Error(), "mapping User: Some fields are missing from target: nope")
+}
+
+const orderSrc = `package app
+
+import "time"
+
+type Address struct {
+	Street string
+	City   string
+}
+
+type Order struct {
+	ID        int64
+	Billing   Address   ` + "`mapper:\",prefix=billing_\"`" + `
+	CreatedAt time.Time ` + "`mapper:\"created_at,autocreate\"`" + `
+}
+
+type Account struct {
+	ID    string ` + "`mapper:\"id,uuid\"`" + `
+	Token string ` + "`mapper:\"token,encrypted\"`" + `
+}
+`
+
+const orderWant = `// Code generated by mappergen; DO NOT EDIT.
+
+package app
+
+import "slices"
+
+// OrderMapper maps Order to columns id,billing_street,billing_city,created_at.
+type OrderMapper struct{}
+
+var orderMapperColumns = []string{"id", "billing_street", "billing_city", "created_at"}
+
+// Columns returns a copy of the mapped columns.
+func (OrderMapper) Columns() []string {
+	return slices.Clone(orderMapperColumns)
+}
+
+// ColumnsString returns the mapped columns, comma separated.
+func (OrderMapper) ColumnsString() string {
+	return "id,billing_street,billing_city,created_at"
+}
+
+// Marks returns a placeholder per column, comma separated.
+func (OrderMapper) Marks() string {
+	return "?,?,?,?"
+}
+
+// Addrs returns the addresses of the mapped fields of dest, for Scan.
+func (OrderMapper) Addrs(dest *Order) []any {
+	return []any{&dest.ID, &dest.Billing.Street, &dest.Billing.City, &dest.CreatedAt}
+}
+
+// Values returns the mapped fields of rec, for Exec.
+func (OrderMapper) Values(rec *Order) []any {
+	return []any{rec.ID, rec.Billing.Street, rec.Billing.City, rec.CreatedAt}
+}
+`
+
+func TestRunTypes(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(dir, "order.go"), []byte(orderSrc), 0o644))
+
+	is.NoErr(run([]string{"-type", "Order", dir}))
+	got, err := os.ReadFile(filepath.Join(dir, "order_mapper.go"))
+	is.NoErr(err)
+	is.Equal(string(got), orderWant)
+
+	err = run([]string{"-type", "Account", dir})
+	is.Equal(err.Error(), "mapping Account: ID: uuid fields are converted at runtime, use a mapper")
+	err = run([]string{"-type", "Account", "-columns", "token", dir})
+	is.Equal(err.Error(), "mapping Account: Token: encrypted fields are converted at runtime, use a mapper")
+}
@@ -0,0 +1,181 @@
+// Command mappergen writes mappers as plain Go code, with no reflection at
+// runtime. It is meant for go:generate:
+//
+//	//go:generate mappergen -type User
+//
+// which writes user_mapper.go next to the file holding the directive, with
+// a UserMapper type having the Columns, ColumnsString, Marks, Addrs and
+// Values methods of a mapper built with Mapper(User{}, "*"). Generated code
+// binds fields as they are, so fields mappers convert, as those tagged uuid
+// or encrypted or of type big.Rat, are rejected. Converters the program
+// registers are not known to mappergen.
+//
+// Flags mirror mapper options:
+//
+//	-type        struct types to generate mappers for, comma separated
+//	-columns     mapped columns, comma separated, "*" for all
+//	-key         struct tag key, "mapper" by default
+//	-fieldmapper column names of untagged fields: lower, snake or direct
+//	-output      output file, <type>_mapper.go by default
+//
+// With -from-db, mappergen instead writes a struct holding a row of a live
+// database table, with mapper tags, and a mapper variable of it:
+//
+//	mappergen -from-db -driver pgx -dsn "$DATABASE_URL" -table users
+//
+// writes users.go with a User struct and a UserMapper variable. -type sets
+// the struct name. Drivers are pgx, mysql and sqlite.
+//
+// Given -query instead of -table, it writes the result struct of the query
+// held in a SQL file, found by running it with LIMIT 0, along with the
+// query as a constant:
+//
+//	mappergen -from-db -driver pgx -dsn "$DATABASE_URL" -query sales_report.sql
+//
+// writes sales_report_query.go with SalesReport, SalesReportMapper and
+// SalesReportQuery. Queries cannot have parameters.
+//
+// Naming conventions, the tag key and the typing of NULL columns can be set
+// in a mappergen.json file of the output directory, or the file given with
+// -config. See fileConfig for its content.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "mappergen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("mappergen", flag.ContinueOnError)
+	var (
+		types   = fs.String("type", "", "struct types to generate mappers for, comma separated")
+		columns = fs.String("columns", "*", "mapped columns, comma separated")
+		key     = fs.String("key", "mapper", "struct tag key")
+		fm      = fs.String("fieldmapper", "lower", "column names of untagged fields: lower, snake or direct")
+		output  = fs.String("output", "", "output file")
+		cfgPath = fs.String("config", "", "configuration file, "+configFile+" in the output directory by default")
+
+		fromDBMode = fs.Bool("from-db", false, "generate a struct from a database table")
+		driver     = fs.String("driver", "", "database driver with -from-db: pgx, mysql or sqlite")
+		dsn        = fs.String("dsn", "", "data source name with -from-db")
+		table      = fs.String("table", "", "table to generate a struct for with -from-db")
+		queryFile  = fs.String("query", "", "SQL file holding a query to generate a result struct for with -from-db")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	cfg := defaultConfig()
+	if *cfgPath != "" {
+		if err := loadConfig(cfg, *cfgPath, true); err != nil {
+			return err
+		}
+	} else if err := loadConfig(cfg, filepath.Join(dir, configFile), false); err != nil {
+		return err
+	}
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "columns":
+			cfg.columns = strings.Split(*columns, ",")
+		case "key":
+			cfg.key = *key
+		case "fieldmapper":
+			cfg.fieldMapper, err = fieldMapperNamed(*fm)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if *fromDBMode && *queryFile != "" {
+		b, err := os.ReadFile(*queryFile)
+		if err != nil {
+			return err
+		}
+		query := cleanQuery(string(b))
+		cols, d, err := fromQuery(context.Background(), *driver, *dsn, query)
+		if err != nil {
+			return err
+		}
+		base := strings.TrimSuffix(filepath.Base(*queryFile), filepath.Ext(*queryFile))
+		typ := *types
+		if typ == "" {
+			typ = cfg.goName(base)
+		}
+		src, err := generateRow(cfg, packageName(dir), typ, "query "+filepath.Base(*queryFile), query, cols, d)
+		if err != nil {
+			return err
+		}
+		if *output == "" {
+			*output = filepath.Join(dir, base+"_query.go")
+		}
+		return os.WriteFile(*output, src, 0o644)
+	}
+
+	if *fromDBMode {
+		if *table == "" {
+			return fmt.Errorf("-table or -query is required with -from-db")
+		}
+		cols, d, err := fromDB(context.Background(), *driver, *dsn, *table)
+		if err != nil {
+			return err
+		}
+		typ := *types
+		if typ == "" {
+			typ = cfg.typeName(*table)
+		}
+		src, err := generateTable(cfg, packageName(dir), typ, *table, cols, d)
+		if err != nil {
+			return err
+		}
+		if *output == "" {
+			*output = filepath.Join(dir, strings.ReplaceAll(*table, ".", "_")+".go")
+		}
+		return os.WriteFile(*output, src, 0o644)
+	}
+
+	if *types == "" {
+		return fmt.Errorf("-type is required")
+	}
+	pkg, err := parsePackage(dir)
+	if err != nil {
+		return err
+	}
+	names := strings.Split(*types, ",")
+	src, err := generate(pkg, names, cfg)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = filepath.Join(dir, strings.ToLower(names[0])+"_mapper.go")
+	}
+	return os.WriteFile(*output, src, 0o644)
+}
+
+// packageName returns the package of the Go files in dir, or else the
+// name of dir.
+func packageName(dir string) string {
+	if p, err := parsePackage(dir); err == nil {
+		return p.name
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "main"
+	}
+	return strings.ToLower(defaultConfig().goName(filepath.Base(abs)))
+}
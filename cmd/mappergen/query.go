@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dav-m85/mapper"
+)
+
+// fromQuery returns the result columns of query, run with LIMIT 0 through
+// driver and dsn, and the dialect of driver. Columns whose nullability the
+// driver does not report are nullable.
+func fromQuery(ctx context.Context, driver, dsn, query string) ([]mapper.TableColumn, mapper.Dialect, error) {
+	d, ok := dialects[driver]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown driver %q, want pgx, mysql or sqlite", driver)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, "", err
+	}
+	defer db.Close()
+	rows, err := db.QueryContext(ctx, "SELECT * FROM ("+query+") mappergen_q LIMIT 0")
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	cts, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, "", err
+	}
+	cols := make([]mapper.TableColumn, len(cts))
+	for i, ct := range cts {
+		nullable, ok := ct.Nullable()
+		cols[i] = mapper.TableColumn{
+			Name:     ct.Name(),
+			Type:     strings.ToLower(ct.DatabaseTypeName()),
+			Nullable: nullable || !ok,
+		}
+	}
+	return cols, d, rows.Err()
+}
+
+// cleanQuery trims spaces and trailing semicolons off query.
+func cleanQuery(query string) string {
+	return strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+}
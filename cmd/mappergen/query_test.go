@@ -0,0 +1,36 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestFromQuery(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	dsn := filepath.Join(dir, "test.db")
+	db, err := sql.Open("sqlite", dsn)
+	is.NoErr(err)
+	_, err = db.Exec(`CREATE TABLE sales (id INTEGER NOT NULL, amount REAL, sold_at DATETIME)`)
+	is.NoErr(err)
+	is.NoErr(db.Close())
+
+	query := "SELECT id, amount, sold_at FROM sales WHERE amount > 10;\n"
+	is.NoErr(os.WriteFile(filepath.Join(dir, "big_sales.sql"), []byte(query), 0o644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "doc.go"), []byte("package report\n"), 0o644))
+
+	is.NoErr(run([]string{"-from-db", "-driver", "sqlite", "-dsn", dsn, "-query", filepath.Join(dir, "big_sales.sql"), dir}))
+	src, err := os.ReadFile(filepath.Join(dir, "big_sales_query.go"))
+	is.NoErr(err)
+	got := string(src)
+	is.True(strings.Contains(got, "package report\n"))
+	is.True(strings.Contains(got, "const BigSalesQuery = `SELECT id, amount, sold_at FROM sales WHERE amount > 10`\n"))
+	is.True(strings.Contains(got, "// BigSales is a row of query big_sales.sql.\n"))
+	is.True(strings.Contains(got, "\tAmount sql.NullFloat64 `mapper:\"amount\"`\n"))
+	is.True(strings.Contains(got, "var BigSalesMapper = mapper.MapperWithOptions(BigSales{}"))
+}
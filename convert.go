@@ -0,0 +1,146 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// codec converts the values of a Go type to and from the database, see
+// [RegisterConverter].
+type codec struct {
+	t      reflect.Type
+	toDB   func(v any) (driver.Value, error)
+	fromDB func(src any) (any, error)
+
+	// zeroNull tells the zero value of t is NULL, which then scans as
+	// such into fields of type t.
+	zeroNull bool
+}
+
+var (
+	codecsMu sync.Mutex
+	codecs   atomic.Pointer[map[reflect.Type]*codec] // replaced on registration
+)
+
+// RegisterConverter has fields of type T, or *T, converted by toDB and
+// fromDB by every mapper, so third party types need no Valuer and Scanner
+// wrappers:
+//
+//	mapper.RegisterConverter(
+//	  func(d decimal.Decimal) (driver.Value, error) { return d.String(), nil },
+//	  func(src any) (decimal.Decimal, error) {
+//	    switch s := src.(type) {
+//	    case string:
+//	      return decimal.NewFromString(s)
+//	    case []byte:
+//	      return decimal.NewFromString(string(s))
+//	    }
+//	    return decimal.Decimal{}, fmt.Errorf("cannot scan %T into a decimal", src)
+//	  },
+//	)
+//
+// [Values] then gives toDB results, failing the statement on error, and
+// scans call fromDB with the driver value. Nil *T fields are NULL, and
+// NULL scans into *T fields as nil, so fromDB never sees nil.
+//
+// Registering T again replaces its converter. Converters apply to fields
+// of type T exactly, not to types defined from it, and are resolved when
+// mappers are built: register them first, as in an init function.
+func RegisterConverter[T any](toDB func(T) (driver.Value, error), fromDB func(src any) (T, error)) {
+	register(newCodec(toDB, fromDB))
+}
+
+func newCodec[T any](toDB func(T) (driver.Value, error), fromDB func(src any) (T, error)) *codec {
+	return &codec{
+		t:      reflect.TypeFor[T](),
+		toDB:   func(v any) (driver.Value, error) { return toDB(v.(T)) },
+		fromDB: func(src any) (any, error) { return fromDB(src) },
+	}
+}
+
+func register(c *codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	reg := make(map[reflect.Type]*codec)
+	if p := codecs.Load(); p != nil {
+		for t, c := range *p {
+			reg[t] = c
+		}
+	}
+	reg[c.t] = c
+	codecs.Store(&reg)
+}
+
+// codecOf returns the codec of fields of type t, or of pointers to it, if
+// registered.
+func codecOf(t reflect.Type) *codec {
+	p := codecs.Load()
+	if p == nil {
+		return nil
+	}
+	if c, ok := (*p)[t]; ok {
+		return c
+	}
+	if t.Kind() == reflect.Pointer {
+		return (*p)[t.Elem()]
+	}
+	return nil
+}
+
+// value returns the database value of v, a field of type c.t or *c.t.
+func (c *codec) value(v any) any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && c.t.Kind() != reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		v = rv.Elem().Interface()
+	}
+	d, err := c.toDB(v)
+	if err != nil {
+		return failingValue{err}
+	}
+	return d
+}
+
+// failingValue fails the statements it is an argument of.
+type failingValue struct {
+	err error
+}
+
+func (f failingValue) Value() (driver.Value, error) {
+	return nil, f.err
+}
+
+// codecScanner scans database values into the field pointed to by dest.
+type codecScanner struct {
+	c    *codec
+	dest reflect.Value
+}
+
+func (s codecScanner) Scan(src any) error {
+	f := s.dest.Elem()
+	ptr := f.Kind() == reflect.Pointer && s.c.t.Kind() != reflect.Pointer
+	if src == nil {
+		if !ptr && !s.c.zeroNull {
+			return fmt.Errorf("cannot scan NULL into a %s", s.c.t)
+		}
+		f.SetZero()
+		return nil
+	}
+	v, err := s.c.fromDB(src)
+	if err != nil {
+		return err
+	}
+	if ptr {
+		p := reflect.New(s.c.t)
+		p.Elem().Set(reflect.ValueOf(v))
+		f.Set(p)
+		return nil
+	}
+	f.Set(reflect.ValueOf(v))
+	return nil
+}
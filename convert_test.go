@@ -0,0 +1,55 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// convertID is a third party identifier, stored as "id-42".
+type convertID struct{ n int }
+
+type convertRecord struct {
+	ID     convertID  `mapper:"id"`
+	Parent *convertID `mapper:"parent"`
+}
+
+func TestRegisterConverter(t *testing.T) {
+	is := is.New(t)
+	RegisterConverter(
+		func(id convertID) (driver.Value, error) {
+			if id.n < 0 {
+				return nil, errors.New("negative id")
+			}
+			return "id-" + strconv.Itoa(id.n), nil
+		},
+		func(src any) (convertID, error) {
+			s, _ := src.(string)
+			n, err := strconv.Atoi(strings.TrimPrefix(s, "id-"))
+			return convertID{n}, err
+		},
+	)
+	dut := Mapper(convertRecord{}, "*")
+
+	is.Equal(dut.Values(convertRecord{convertID{1}, &convertID{2}}), []any{"id-1", "id-2"})
+	is.Equal(dut.Values(convertRecord{convertID{1}, nil}), []any{"id-1", nil})
+	_, err := dut.Values(convertRecord{ID: convertID{-1}})[0].(driver.Valuer).Value()
+	is.Equal(err.Error(), "negative id")
+
+	rows := queryFake(t, []string{"id", "parent"},
+		[]driver.Value{"id-3", "id-4"},
+		[]driver.Value{"id-5", nil},
+	)
+	var got []convertRecord
+	is.NoErr(ForEach(dut, rows, func(rec *convertRecord) error {
+		got = append(got, *rec)
+		return nil
+	}))
+	is.Equal(got[0].ID, convertID{3})
+	is.Equal(*got[0].Parent, convertID{4})
+	is.Equal(got[1], convertRecord{convertID{5}, nil})
+}
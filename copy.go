@@ -0,0 +1,52 @@
+package mapper
+
+import "reflect"
+
+// CopyFromSource has the method set of pgx.CopyFromSource, which values
+// returned by [CopyFromSource] can be used as, without this package
+// depending on pgx.
+type CopyFromSource interface {
+	Next() bool
+	Values() ([]any, error)
+	Err() error
+}
+
+// CopyFromSource streams records, a slice of structs or struct pointers, as
+// rows of the mapped columns. It feeds pgx COPY, the fastest bulk load path
+// into Postgres:
+//
+//	conn.CopyFrom(ctx, pgx.Identifier{"users"}, users.Columns(), users.CopyFromSource(us))
+func (m *mapper) CopyFromSource(records any) CopyFromSource {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic("records not a slice")
+	}
+	return &copySource{m: m, recs: v, i: -1}
+}
+
+type copySource struct {
+	m    *mapper
+	recs reflect.Value
+	i    int
+	err  error
+}
+
+func (s *copySource) Next() bool {
+	if s.err != nil || s.i+1 >= s.recs.Len() {
+		return false
+	}
+	s.i++
+	return true
+}
+
+func (s *copySource) Values() ([]any, error) {
+	vs, err := s.m.ValuesE(s.recs.Index(s.i).Interface())
+	if err != nil {
+		s.err = err
+	}
+	return vs, err
+}
+
+func (s *copySource) Err() error {
+	return s.err
+}
@@ -0,0 +1,29 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCopyFromSource(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(scanRecord{}, "*")
+
+	src := dut.CopyFromSource([]*scanRecord{{1, "a"}, {2, "b"}})
+	var got [][]any
+	for src.Next() {
+		vs, err := src.Values()
+		is.NoErr(err)
+		got = append(got, vs)
+	}
+	is.NoErr(src.Err())
+	is.Equal(got, [][]any{{int64(1), "a"}, {int64(2), "b"}})
+
+	src = dut.CopyFromSource([]int{1})
+	is.True(src.Next())
+	_, err := src.Values()
+	is.True(err != nil)
+	is.Equal(src.Err(), err)
+	is.True(!src.Next())
+}
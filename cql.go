@@ -0,0 +1,75 @@
+package mapper
+
+import (
+	"errors"
+	"strings"
+)
+
+// CQLInsert returns an INSERT statement of the mapped columns into table.
+// It and the other CQL helpers write CQL for github.com/gocql/gocql, whose
+// Bind and Scan take the slices of [Values] and [Addrs] as is:
+//
+//	err := session.Query(users.CQLInsert("users"), users.Values(u)...).Exec()
+//	iter := session.Query(users.CQLSelect("users") + " WHERE id = ?", id).Iter()
+//	for iter.Scan(users.Addrs(&u)...) {
+//	  ...
+//	}
+//
+// CQL only knows ? markers and commas, so Mark, Comma and Separator are
+// ignored.
+func (m *mapper) CQLInsert(table string) string {
+	m.mustWritable()
+	return "INSERT INTO " + m.tableName(table) + " (" + strings.Join(m.cols, ",") +
+		") VALUES (" + strings.Repeat(",?", len(m.cols))[1:] + ")"
+}
+
+// CQLSelect returns a SELECT statement of the mapped columns from table,
+// to be completed with a WHERE clause.
+func (m *mapper) CQLSelect(table string) string {
+	return "SELECT " + strings.Join(m.cols, ",") + " FROM " + m.tableName(table)
+}
+
+// CQLUpdate returns an UPDATE statement of table setting the mapped
+// columns but keys, which make the WHERE clause, along with the values of
+// rec in matching order:
+//
+//	stmt, values := users.CQLUpdate("users", u, "id")
+//	err := session.Query(stmt, values...).Exec()
+//
+// It panics when keys are empty or not mapped, or when no column is left to
+// set.
+func (m *mapper) CQLUpdate(table string, rec any, keys ...string) (string, []any) {
+	if len(keys) == 0 {
+		panic(errors.New("CQLUpdate needs at least one key"))
+	}
+	if missing := m.unknown(keys); len(missing) > 0 {
+		panic(&ErrMissingColumns{Cols: missing})
+	}
+	if len(keys) >= len(m.cols) {
+		panic(ErrNoColumns)
+	}
+	vals := m.Values(rec)
+	args := make([]any, 0, len(vals))
+	var b strings.Builder
+	b.WriteString("UPDATE " + m.tableName(table) + " SET ")
+	for j, col := range m.cols {
+		if fieldSlice(keys).index(col) != -1 {
+			continue
+		}
+		if len(args) > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(col + "=?")
+		args = append(args, vals[j])
+	}
+	for i, key := range keys {
+		if i == 0 {
+			b.WriteString(" WHERE ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		b.WriteString(key + "=?")
+		args = append(args, vals[fieldSlice(m.cols).index(key)])
+	}
+	return b.String(), args
+}
@@ -0,0 +1,34 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCQL(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID    int
+		Name  string
+		Email string
+	}
+	dut := Mapper(User{}, "*").SetOptions(WithMark('$'), WithSeparator(", "))
+
+	is.Equal(dut.CQLInsert("users"), "INSERT INTO users (id,name,email) VALUES (?,?,?)")
+	is.Equal(dut.CQLSelect("ks.users"), "SELECT id,name,email FROM ks.users")
+
+	stmt, args := dut.CQLUpdate("users", &User{1, "n", "e"}, "id")
+	is.Equal(stmt, "UPDATE users SET name=?,email=? WHERE id=?")
+	is.Equal(args, []any{"n", "e", 1})
+
+	stmt, args = dut.CQLUpdate("users", User{1, "n", "e"}, "email", "id")
+	is.Equal(stmt, "UPDATE users SET name=? WHERE email=? AND id=?")
+	is.Equal(args, []any{"n", "e", 1})
+
+	defer func() {
+		_, ok := recover().(*ErrMissingColumns)
+		is.True(ok)
+	}()
+	dut.CQLUpdate("users", User{}, "nope")
+}
@@ -0,0 +1,315 @@
+package mapper
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// CSVOption configures [WriteCSV].
+type CSVOption func(c *csvConfig)
+
+type csvConfig struct {
+	comma  rune
+	format func(col string, v any) (string, error)
+}
+
+// CSVComma sets the field delimiter, comma (',') by default.
+func CSVComma(comma rune) CSVOption {
+	return func(c *csvConfig) {
+		c.comma = comma
+	}
+}
+
+// CSVFormat sets how values are turned into text. It defaults to
+// [FormatValueE]. See [CSVFormatE] for formats which can fail.
+func CSVFormat(format func(col string, v any) string) CSVOption {
+	return CSVFormatE(func(col string, v any) (string, error) {
+		return format(col, v), nil
+	})
+}
+
+// CSVFormatE is like [CSVFormat] for formats returning errors, which fail
+// [WriteCSV].
+func CSVFormatE(format func(col string, v any) (string, error)) CSVOption {
+	return func(c *csvConfig) {
+		c.format = format
+	}
+}
+
+// WriteCSV writes records, a slice of structs or struct pointers, as CSV to
+// w: a header row with the mapped columns, then one row per record with its
+// values, anonymized, see [ExportValues].
+//
+//	users.WriteCSV(os.Stdout, us, CSVComma(';'))
+func (m *mapper) WriteCSV(w io.Writer, records any, opts ...CSVOption) error {
+	c := csvConfig{comma: ',', format: func(_ string, v any) (string, error) { return FormatValueE(v) }}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	recs := reflect.ValueOf(records)
+	if recs.Kind() != reflect.Slice && recs.Kind() != reflect.Array {
+		return fmt.Errorf("records not a slice: %s", recs.Kind())
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = c.comma
+	if err := cw.Write(m.cols); err != nil {
+		return err
+	}
+	row := make([]string, len(m.cols))
+	for i := 0; i < recs.Len(); i++ {
+		vs, err := m.ExportValuesE(recs.Index(i).Interface())
+		if err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+		for j, v := range vs {
+			if row[j], err = c.format(m.cols[j], v); err != nil {
+				return fmt.Errorf("record %d, column %s: %w", i, m.cols[j], err)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// FormatValue is like [FormatValueE] but panics on error.
+func FormatValue(v any) string {
+	s, err := FormatValueE(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// FormatValueE returns v as text: nil and nil pointers are empty, times are
+// RFC 3339, byte slices are taken as is, [driver.Valuer] are formatted
+// after their Value, other values with [fmt.Sprint]. It fails with the
+// errors of Value, as those of values which cannot be converted, see
+// [Values].
+func FormatValueE(v any) (string, error) {
+	if vr, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return "", nil
+		}
+		dv, err := vr.Value()
+		if err != nil {
+			return "", err
+		}
+		v = dv
+	}
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return x, nil
+	case []byte:
+		return string(x), nil
+	case time.Time:
+		return x.Format(time.RFC3339Nano), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "", nil
+		}
+		return FormatValueE(rv.Elem().Interface())
+	}
+	return fmt.Sprint(v), nil
+}
+
+// CSVError locates a failure while reading CSV.
+type CSVError struct {
+	Line   int
+	Column string // empty for errors about the whole line
+	Err    error
+}
+
+func (e *CSVError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %s: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *CSVError) Unwrap() error { return e.Err }
+
+// CSVDecoder reads records of type T from CSV whose header row names
+// mapped columns, in any order. Mapped columns absent from the header are
+// left untouched.
+type CSVDecoder[T any] struct {
+	m    *mapper
+	r    *csv.Reader
+	idx  []int // mapper position of each CSV column
+	err  error
+	init bool
+}
+
+// NewCSVDecoder returns a decoder reading from r. Only [CSVComma] applies.
+// T must be the struct type m was built from.
+func NewCSVDecoder[T any](m *mapper, r io.Reader, opts ...CSVOption) *CSVDecoder[T] {
+	c := csvConfig{comma: ','}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	cr := csv.NewReader(r)
+	cr.Comma = c.comma
+	cr.ReuseRecord = true
+	return &CSVDecoder[T]{m: m, r: cr}
+}
+
+// Decode reads the next record into rec, converting text to field types. It
+// returns io.EOF when there are no more records, and a [*CSVError] when a
+// line cannot be read.
+func (d *CSVDecoder[T]) Decode(rec *T) error {
+	if !d.init {
+		d.init = true
+		d.err = d.readHeader()
+	}
+	if d.err != nil {
+		return d.err
+	}
+	row, err := d.r.Read()
+	if err != nil {
+		return err // io.EOF, or a *csv.ParseError with its position
+	}
+	v, _, err := d.m.pointedStruct(rec)
+	if err != nil {
+		return err
+	}
+	for i, s := range row {
+		f := d.m.fields[d.idx[i]]
+		if err := setString(v.FieldByIndex(f.Index), s); err != nil {
+			line, _ := d.r.FieldPos(i)
+			return &CSVError{Line: line, Column: d.m.cols[d.idx[i]], Err: err}
+		}
+	}
+	return nil
+}
+
+func (d *CSVDecoder[T]) readHeader() error {
+	header, err := d.r.Read()
+	if err == io.EOF {
+		return &CSVError{Line: 1, Err: errors.New("missing header")}
+	}
+	if err != nil {
+		return err
+	}
+	d.idx = make([]int, len(header))
+	for i, col := range header {
+		j := fieldSlice(d.m.cols).index(col)
+		if j == -1 {
+			return &CSVError{Line: 1, Column: col, Err: errors.New("not mapped")}
+		}
+		d.idx[i] = j
+	}
+	return nil
+}
+
+// ReadCSV reads every record of r, see [CSVDecoder].
+//
+//	us, err := ReadCSV[User](users, f)
+func ReadCSV[T any](m *mapper, r io.Reader, opts ...CSVOption) ([]T, error) {
+	d := NewCSVDecoder[T](m, r, opts...)
+	var res []T
+	for {
+		var rec T
+		err := d.Decode(&rec)
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		res = append(res, rec)
+	}
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// setString converts s to the type of v, and sets it. Empty strings set nil
+// pointers, NULL [sql.Scanner] and zero values.
+func setString(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if s == "" {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setString(v.Elem(), s)
+	}
+	if v.Addr().Type().Implements(scannerType) {
+		var src any = s
+		if s == "" {
+			src = nil
+		}
+		return v.Addr().Interface().(sql.Scanner).Scan(src)
+	}
+	if v.Type() == timeType {
+		if s == "" {
+			v.SetZero()
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if s == "" && v.Kind() != reflect.String && v.Kind() != reflect.Slice {
+		v.SetZero()
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("cannot convert text to %s", v.Type())
+		}
+		v.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("cannot convert text to %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package mapper
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type csvRecord struct {
+	ID      int
+	Name    string
+	Note    *string
+	Score   sql.NullFloat64
+	Created time.Time
+}
+
+func TestWriteCSV(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(csvRecord{}, "*")
+	note := "hello; world"
+	recs := []csvRecord{
+		{1, "a", &note, sql.NullFloat64{Float64: 1.5, Valid: true}, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{2, "b", nil, sql.NullFloat64{}, time.Time{}},
+	}
+
+	var b strings.Builder
+	is.NoErr(dut.WriteCSV(&b, recs, CSVComma(';')))
+	is.Equal(b.String(), `id;name;note;score;created
+1;a;"hello; world";1.5;2024-06-01T00:00:00Z
+2;b;;;0001-01-01T00:00:00Z
+`)
+
+	b.Reset()
+	upper := func(col string, v any) string { return strings.ToUpper(FormatValue(v)) }
+	is.NoErr(dut.Subset("name").WriteCSV(&b, recs, CSVFormat(upper)))
+	is.Equal(b.String(), "name\nA\nB\n")
+}
+
+// csvFailing is a value failing to convert for the database.
+type csvFailing struct{}
+
+var errCSVFailing = errors.New("no value")
+
+func (csvFailing) Value() (driver.Value, error) { return nil, errCSVFailing }
+
+func TestWriteCSVError(t *testing.T) {
+	is := is.New(t)
+	type Row struct {
+		ID  int        `mapper:"id"`
+		Bad csvFailing `mapper:"bad"`
+	}
+	dut := Mapper(Row{}, "*")
+	var b strings.Builder
+	err := dut.WriteCSV(&b, []Row{{ID: 1}})
+	is.True(errors.Is(err, errCSVFailing))
+	is.Equal(err.Error(), "record 0, column bad: no value")
+
+	failing := func(col string, v any) (string, error) { return "", errCSVFailing }
+	err = dut.WriteCSV(&b, []Row{{ID: 1}}, CSVFormatE(failing))
+	is.True(errors.Is(err, errCSVFailing))
+
+	_, err = FormatValueE(csvFailing{})
+	is.Equal(err, errCSVFailing)
+}
+
+func TestReadCSV(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(csvRecord{}, "*")
+
+	recs, err := ReadCSV[csvRecord](dut, strings.NewReader(`name;id;note;score;created
+a;1;"hello; world";1.5;2024-06-01T00:00:00Z
+b;2;;;
+`), CSVComma(';'))
+	is.NoErr(err)
+	note := "hello; world"
+	is.Equal(recs, []csvRecord{
+		{1, "a", &note, sql.NullFloat64{Float64: 1.5, Valid: true}, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{2, "b", nil, sql.NullFloat64{}, time.Time{}},
+	})
+
+	_, err = ReadCSV[csvRecord](dut, strings.NewReader("id,name\n1,a\nx,b\n"))
+	var cerr *CSVError
+	is.True(errors.As(err, &cerr))
+	is.Equal(cerr.Error(), `line 3, column id: strconv.ParseInt: parsing "x": invalid syntax`)
+
+	_, err = ReadCSV[csvRecord](dut, strings.NewReader("id,nmae\n"))
+	is.Equal(err.Error(), "line 1, column nmae: not mapped")
+}
@@ -0,0 +1,241 @@
+package mapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CreateTableString returns a CREATE TABLE statement of table with the
+// mapped columns, in the [Dialect] of m, so tests and small services can
+// bootstrap their schema from the structs they map:
+//
+//	type User struct {
+//	  ID    int64  `mapper:"id,pk"`
+//	  Email string `mapper:"email,notnull,type=varchar(320)"`
+//	  Karma int    `mapper:"karma,notnull,default=0"`
+//	}
+//
+// Column types follow field types, or the type= tag option. Tag options
+// notnull, default= and pk add NOT NULL, DEFAULT and PRIMARY KEY clauses.
+//
+// It panics when m has no dialect or some field type has no known column
+// type.
+func (m *mapper) CreateTableString(table string) string {
+	s, err := m.CreateTableStringE(table)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// CreateTableStringE is like [CreateTableString] but returns an error
+// instead of panicking.
+func (m *mapper) CreateTableStringE(table string) (string, error) {
+	table = m.tableName(table)
+	if err := checkColumn(table, nil); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	var pks []string
+	n := 0
+	b.WriteString("CREATE TABLE " + table + " (")
+	for j, f := range m.fields {
+		if m.isExpr(j) {
+			continue
+		}
+		def, err := m.columnDef(j)
+		if err != nil {
+			return "", err
+		}
+		if n > 0 {
+			b.WriteByte(',')
+		}
+		n++
+		b.WriteString("\n  " + def)
+		if f.opts.Has("pk") {
+			pks = append(pks, m.cols[j])
+		}
+	}
+	if len(pks) > 0 {
+		b.WriteString(",\n  PRIMARY KEY (" + strings.Join(pks, ", ") + ")")
+	}
+	b.WriteString("\n)")
+	return b.String(), nil
+}
+
+// columnDef returns the definition of column j, as in "id bigint NOT NULL".
+func (m *mapper) columnDef(j int) (string, error) {
+	f := m.fields[j]
+	typ, err := m.columnTypeOf(j)
+	if err != nil {
+		return "", err
+	}
+	def := m.cols[j] + " " + typ
+	if expr := f.opts.Get("generated"); expr != "" {
+		def += " GENERATED ALWAYS AS (" + expr + ") STORED"
+	}
+	if f.opts.Has("notnull") {
+		def += " NOT NULL"
+	}
+	if f.opts.Has("default") {
+		def += " DEFAULT " + f.opts.Get("default")
+	}
+	return def, nil
+}
+
+// columnTypeOf returns the type of column j, from its type= tag option or
+// its field type.
+func (m *mapper) columnTypeOf(j int) (string, error) {
+	if typ := m.fields[j].opts.Get("type"); typ != "" {
+		return typ, nil
+	}
+	if typ := uuidColumnType(m.fields[j].uuid, m.Dialect); typ != "" {
+		return typ, nil
+	}
+	t := m.fields[j].Type
+	if m.fields[j].isBlob() {
+		t = reflect.TypeFor[[]byte]() // compressed or ciphertexts
+	} else if m.fields[j].flags != nil {
+		t = reflect.TypeFor[int64]()
+	}
+	typ, err := columnType(t, m.Dialect)
+	if err != nil {
+		return "", fmt.Errorf("column %s: %w", m.cols[j], err)
+	}
+	return typ, nil
+}
+
+// columnType returns the column type of fields of type t in dialect d.
+func columnType(t reflect.Type, d Dialect) (string, error) {
+	t, _ = nullableType(t)
+	var types *[kinds]string
+	switch d {
+	case Postgres:
+		types = &postgresTypes
+	case MySQL:
+		types = &mysqlTypes
+	case SQLite:
+		types = &sqliteTypes
+	default:
+		return "", fmt.Errorf("mapper: no DDL support for dialect %q, see WithDialect", d)
+	}
+	k := t.Kind()
+	switch t {
+	case timeType:
+		k = timeKind
+	case bytesType:
+		k = bytesKind
+	case ratType:
+		k = ratKind
+	case bigIntType:
+		k = bigIntKind
+	case ipType, addrType:
+		k = inetKind
+	case prefixType:
+		k = cidrKind
+	case macType:
+		k = macKind
+	}
+	if k < kinds && types[k] != "" {
+		return types[k], nil
+	}
+	return "", errors.New("no column type for " + t.String() + ", use the type= tag option")
+}
+
+// nullableType returns the type held by t, a pointer or a database/sql
+// Null type, and whether t can hold NULL.
+func nullableType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem(), true
+	}
+	if t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" && t.NumField() == 2 && t.Field(1).Name == "Valid" {
+		return t.Field(0).Type, true
+	}
+	return t, false
+}
+
+// Column types by reflect.Kind, with the extra kinds below.
+const (
+	timeKind = reflect.UnsafePointer + 1 + iota
+	bytesKind
+	ratKind
+	bigIntKind
+	inetKind
+	cidrKind
+	macKind
+	kinds
+)
+
+var postgresTypes = [kinds]string{
+	reflect.Bool:    "boolean",
+	reflect.Int:     "bigint",
+	reflect.Int8:    "smallint",
+	reflect.Int16:   "smallint",
+	reflect.Int32:   "integer",
+	reflect.Int64:   "bigint",
+	reflect.Uint:    "numeric(20)",
+	reflect.Uint8:   "smallint",
+	reflect.Uint16:  "integer",
+	reflect.Uint32:  "bigint",
+	reflect.Uint64:  "numeric(20)",
+	reflect.Float32: "real",
+	reflect.Float64: "double precision",
+	reflect.String:  "text",
+	timeKind:        "timestamp with time zone",
+	bytesKind:       "bytea",
+	ratKind:         "numeric",
+	bigIntKind:      "numeric",
+	inetKind:        "inet",
+	cidrKind:        "cidr",
+	macKind:         "macaddr",
+}
+
+var mysqlTypes = [kinds]string{
+	reflect.Bool:    "boolean",
+	reflect.Int:     "bigint",
+	reflect.Int8:    "tinyint",
+	reflect.Int16:   "smallint",
+	reflect.Int32:   "int",
+	reflect.Int64:   "bigint",
+	reflect.Uint:    "bigint unsigned",
+	reflect.Uint8:   "tinyint unsigned",
+	reflect.Uint16:  "smallint unsigned",
+	reflect.Uint32:  "int unsigned",
+	reflect.Uint64:  "bigint unsigned",
+	reflect.Float32: "float",
+	reflect.Float64: "double",
+	reflect.String:  "varchar(255)",
+	timeKind:        "datetime(6)",
+	bytesKind:       "longblob",
+	ratKind:         "decimal(65,30)",
+	bigIntKind:      "decimal(65,0)",
+	inetKind:        "varchar(45)",
+	cidrKind:        "varchar(49)",
+	macKind:         "varchar(17)",
+}
+
+var sqliteTypes = [kinds]string{
+	reflect.Bool:    "boolean",
+	reflect.Int:     "integer",
+	reflect.Int8:    "integer",
+	reflect.Int16:   "integer",
+	reflect.Int32:   "integer",
+	reflect.Int64:   "integer",
+	reflect.Uint:    "integer",
+	reflect.Uint8:   "integer",
+	reflect.Uint16:  "integer",
+	reflect.Uint32:  "integer",
+	reflect.Uint64:  "integer",
+	reflect.Float32: "real",
+	reflect.Float64: "real",
+	reflect.String:  "text",
+	timeKind:        "datetime",
+	bytesKind:       "blob",
+	ratKind:         "numeric",
+	bigIntKind:      "numeric",
+	inetKind:        "text",
+	cidrKind:        "text",
+	macKind:         "text",
+}
@@ -0,0 +1,49 @@
+package mapper
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestCreateTableString(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID      int64  `mapper:"id,pk"`
+		Email   string `mapper:"email,notnull,type=varchar(320)"`
+		Karma   int    `mapper:"karma,notnull,default=0"`
+		Bio     sql.NullString
+		Avatar  []byte
+		Created *time.Time
+	}
+	dut := Mapper(User{}, "*")
+
+	is.Equal(dut.With(WithDialect(Postgres)).CreateTableString("users"), `CREATE TABLE users (
+  id bigint,
+  email varchar(320) NOT NULL,
+  karma bigint NOT NULL DEFAULT 0,
+  bio text,
+  avatar bytea,
+  created timestamp with time zone,
+  PRIMARY KEY (id)
+)`)
+	is.Equal(dut.With(WithDialect(SQLite)).Subset("id", "bio").CreateTableString("users"), `CREATE TABLE users (
+  id integer,
+  bio text,
+  PRIMARY KEY (id)
+)`)
+
+	_, err := dut.CreateTableStringE("users")
+	is.True(err != nil) // no dialect
+
+	type Bad struct {
+		ID   int
+		Tags []string
+	}
+	_, err = Mapper(Bad{}, "*").With(WithDialect(MySQL)).CreateTableStringE("bad")
+	is.Equal(err.Error(), "column tags: no column type for []string, use the type= tag option")
+	_, err = dut.With(WithDialect(MySQL)).CreateTableStringE("users; --")
+	is.True(err != nil) // unsafe table
+}
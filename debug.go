@@ -0,0 +1,118 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DebugSQL returns query with args written in place of its placeholders,
+// escaped for the mapper dialect, so that it can be pasted in a SQL console
+// when investigating a generated statement:
+//
+//	log.Print(users.DebugSQL(users.UpdateString(""), users.UpdateArgs(u)...))
+//	// /* DebugSQL */ UPDATE users SET name='O''Hara' WHERE id=42
+//
+// It is meant for debugging only: statements run by the application MUST
+// keep passing arguments apart, which is what guards them against SQL
+// injection. Placeholders within quotes and comments are left alone, as
+// are those without an argument.
+func (m *mapper) DebugSQL(query string, args ...any) string {
+	var b strings.Builder
+	b.WriteString("/* DebugSQL */ ")
+	next := 0 // argument of the next Mark placeholder
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(query) && query[end] != c {
+				if query[end] == '\\' && c != '"' && m.Dialect == MySQL {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(query))
+			b.WriteString(query[i:end])
+			i = end - 1
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i:], "*/")
+			if end == -1 {
+				end = len(query) - i - 2
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 1
+		case m.Dialect == Postgres && c == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			end := i + 1
+			for end < len(query) && query[end] >= '0' && query[end] <= '9' {
+				end++
+			}
+			n, _ := strconv.Atoi(query[i+1 : end])
+			if n >= 1 && n <= len(args) {
+				b.WriteString(m.literal(args[n-1]))
+			} else {
+				b.WriteString(query[i:end])
+			}
+			i = end - 1
+		case m.Dialect != Postgres && rune(c) == m.Mark && next < len(args):
+			b.WriteString(m.literal(args[next]))
+			next++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// literal returns the SQL literal of v in the mapper dialect.
+func (m *mapper) literal(v any) string {
+	if _, ok := v.(Redacted); ok {
+		return m.quote(redacted)
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return "/* " + strings.ReplaceAll(err.Error(), "*/", "* /") + " */ NULL"
+		}
+	}
+	switch t := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return m.quote(t)
+	case []byte:
+		if m.Dialect == Postgres {
+			return `'\x` + hex.EncodeToString(t) + "'::bytea"
+		}
+		return "X'" + hex.EncodeToString(t) + "'"
+	case bool:
+		if t {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return m.quote(t.Format("2006-01-02 15:04:05.999999-07:00"))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(t)
+	}
+	return m.quote(fmt.Sprint(v))
+}
+
+// quote returns s as a string literal.
+func (m *mapper) quote(s string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	if m.Dialect == MySQL {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + s + "'"
+}
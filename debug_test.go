@@ -0,0 +1,27 @@
+package mapper
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDebugSQL(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID   int64  `mapper:"id,pk"`
+		Name string `mapper:"name"`
+	}
+	dut := Mapper(User{}, "*").SetOptions(WithTable("users"))
+	u := User{42, `O'Hara \o/`}
+	is.Equal(dut.DebugSQL(dut.UpdateString(""), dut.UpdateArgs(u)...), `/* DebugSQL */ UPDATE users SET name='O''Hara \o/' WHERE id=42`)
+	is.Equal(dut.With(WithDialect(MySQL)).DebugSQL("SELECT '?', `a?` -- ?\nFROM t WHERE a=? /* ? */ AND b=? AND c=?", "it's", nil),
+		"/* DebugSQL */ SELECT '?', `a?` -- ?\nFROM t WHERE a='it''s' /* ? */ AND b=NULL AND c=?")
+
+	pg := dut.With(WithDialect(Postgres))
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	is.Equal(pg.DebugSQL("SELECT $2, $1, '$1', $3, $4, $5", []byte{0xca, 0xfe}, true, at, sql.NullInt64{Int64: 7, Valid: true}),
+		`/* DebugSQL */ SELECT TRUE, '\xcafe'::bytea, '$1', '2024-05-01 10:00:00+00:00', 7, $5`)
+}
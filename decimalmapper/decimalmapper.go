@@ -0,0 +1,42 @@
+// Package decimalmapper registers a converter of shopspring/decimal values,
+// so fields of type decimal.Decimal, or *decimal.Decimal, round trip
+// NUMERIC columns exactly. It lives in its own module to keep mapper free
+// of dependencies, and is imported for its side effect:
+//
+//	import _ "github.com/dav-m85/mapper/decimalmapper"
+//
+//	type Order struct {
+//	  ID    int64           `mapper:"id,pk"`
+//	  Total decimal.Decimal `mapper:"total"`
+//	}
+package decimalmapper
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/dav-m85/mapper"
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	mapper.RegisterConverter(value, scan)
+}
+
+func value(d decimal.Decimal) (driver.Value, error) {
+	return d.String(), nil
+}
+
+func scan(src any) (decimal.Decimal, error) {
+	switch v := src.(type) {
+	case string:
+		return decimal.NewFromString(v)
+	case []byte:
+		return decimal.NewFromString(string(v))
+	case int64:
+		return decimal.NewFromInt(v), nil
+	case float64:
+		return decimal.NewFromFloat(v), nil
+	}
+	return decimal.Decimal{}, fmt.Errorf("cannot scan %T %v into a decimal.Decimal", src, src)
+}
@@ -0,0 +1,32 @@
+package decimalmapper
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/dav-m85/mapper"
+	"github.com/matryer/is"
+	"github.com/shopspring/decimal"
+)
+
+func TestDecimal(t *testing.T) {
+	is := is.New(t)
+	type Order struct {
+		ID    int64            `mapper:"id,pk"`
+		Total decimal.Decimal  `mapper:"total"`
+		Tip   *decimal.Decimal `mapper:"tip"`
+	}
+	m := mapper.Mapper(Order{}, "*")
+	vals := m.Values(Order{1, decimal.RequireFromString("12345678901234567890.01"), nil})
+	is.Equal(vals[1], "12345678901234567890.01")
+	is.Equal(vals[2], nil)
+
+	var o Order
+	addrs := m.Addrs(&o)
+	is.NoErr(addrs[1].(sql.Scanner).Scan([]byte("0.10")))
+	is.NoErr(addrs[2].(sql.Scanner).Scan("3"))
+	is.True(o.Total.Equal(decimal.RequireFromString("0.1")))
+	is.True(o.Tip.Equal(decimal.NewFromInt(3)))
+	is.NoErr(addrs[2].(sql.Scanner).Scan(nil))
+	is.Equal(o.Tip, nil)
+}
@@ -0,0 +1,67 @@
+package mapper
+
+import (
+	"reflect"
+	"strings"
+)
+
+// defaulter sets the zero field fv to its default.
+type defaulter func(m *mapper, fv reflect.Value)
+
+// defaultOf returns the defaulter of a field of type t with options opts,
+// nil when it has none Go can compute.
+func defaultOf(t reflect.Type, opts TagOptions) defaulter {
+	if !opts.Has("default") {
+		return nil
+	}
+	def := opts.Get("default")
+	switch strings.ToLower(def) {
+	case "null", "":
+		return nil
+	case "now", "now()", "current_timestamp":
+		if !isTime(t) {
+			return nil
+		}
+		return func(m *mapper, fv reflect.Value) { stamp(fv, m.now()) }
+	case "uuid", "uuid()", "gen_random_uuid()":
+		if t.Kind() != reflect.String && (t.Kind() != reflect.Array || !t.ConvertibleTo(uuidArrayType)) {
+			return nil
+		}
+		return func(m *mapper, fv reflect.Value) { setUUID(fv, m.uuid) }
+	}
+	if len(def) >= 2 && def[0] == '\'' && def[len(def)-1] == '\'' {
+		def = strings.ReplaceAll(def[1:len(def)-1], "''", "'")
+	}
+	lit := reflect.New(t).Elem()
+	if err := setString(lit, def); err != nil || lit.IsZero() {
+		return nil // an expression, left to the database
+	}
+	return func(_ *mapper, fv reflect.Value) {
+		if lit.Kind() == reflect.Pointer { // not shared between records
+			fv.Set(reflect.New(t.Elem()))
+			fv.Elem().Set(lit.Elem())
+			return
+		}
+		fv.Set(lit)
+	}
+}
+
+// setDefaults sets the zero fields of v having a default to it, v being
+// a copy of the record when not addressable.
+func (m *mapper) setDefaults(v reflect.Value) reflect.Value {
+	copied := false
+	for _, f := range m.fields {
+		if f.def == nil {
+			continue
+		}
+		if !v.CanAddr() && !copied {
+			c := reflect.New(v.Type()).Elem()
+			c.Set(v)
+			v, copied = c, true
+		}
+		if fv := v.FieldByIndex(f.Index); fv.IsZero() {
+			f.def(m, fv)
+		}
+	}
+	return v
+}
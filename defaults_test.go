@@ -0,0 +1,47 @@
+package mapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDefaults(t *testing.T) {
+	is := is.New(t)
+	type Member struct {
+		ID      string    `mapper:"id,pk,default=gen_random_uuid()"`
+		Role    string    `mapper:"role,default='o''neil'"`
+		Karma   int       `mapper:"karma,default=10"`
+		Active  bool      `mapper:"active,default=true"`
+		Level   *int      `mapper:"level,default=3"`
+		Seq     int64     `mapper:"seq,default=nextval('seq')"`
+		Created time.Time `mapper:"created,default=now()"`
+	}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	dut := Mapper(Member{}, "*").With(WithClock(func() time.Time { return now }))
+
+	m := Member{}
+	args := dut.InsertArgs(&m)
+	is.True(len(m.ID) == 36)
+	is.Equal(m.Role, "o'neil")
+	is.Equal(m.Karma, 10)
+	is.True(m.Active)
+	is.Equal(*m.Level, 3)
+	is.Equal(m.Seq, int64(0)) // left to the database
+	is.True(m.Created.Equal(now))
+	is.Equal(args[1:4], []any{"o'neil", 10, true})
+
+	other := Member{}
+	dut.InsertArgs(&other)
+	is.True(other.Level != m.Level) // not shared
+	is.True(other.ID != m.ID)
+
+	args = dut.InsertArgs(Member{Role: "admin", Karma: 1})
+	is.Equal(args[1:3], []any{"admin", 1}) // kept when set
+	is.Equal(args[3], true)                // applied to copies too
+
+	u := Member{ID: "x"}
+	dut.UpdateArgs(&u)
+	is.Equal(u.Role, "") // not on update
+}
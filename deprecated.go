@@ -0,0 +1,68 @@
+package mapper
+
+import "sync"
+
+// WithWarnings has warn called with a warning the first time a deprecated
+// column is used by a statement builder or [Subset] of the mapper, to log
+// it:
+//
+//	WithWarnings(func(msg string) { slog.Warn(msg) })
+//
+// Fields tagged deprecated map to columns being retired, optionally with a
+// hint as the tag value, and keep working meanwhile:
+//
+//	Name string `mapper:"name,deprecated=use full_name"`
+func WithWarnings(warn func(msg string)) MapperOption {
+	return func(m *mapper) {
+		m.warn = warn
+		m.warned = new(sync.Map)
+	}
+}
+
+// Warnings returns a warning per deprecated mapped column, see
+// [WithWarnings].
+func (m *mapper) Warnings() []string {
+	var res []string
+	for j := range m.fields {
+		if msg := m.deprecation(j); msg != "" {
+			res = append(res, msg)
+		}
+	}
+	return res
+}
+
+// deprecation returns the warning of column j if deprecated.
+func (m *mapper) deprecation(j int) string {
+	opts := m.fields[j].opts
+	if !opts.Has("deprecated") {
+		return ""
+	}
+	msg := "column " + m.cols[j] + " is deprecated"
+	if hint := opts.Get("deprecated"); hint != "" {
+		msg += ": " + hint
+	}
+	return msg
+}
+
+// warnDeprecated reports the deprecated columns among those at positions
+// idx, or every column when idx is nil, unless already reported.
+func (m *mapper) warnDeprecated(idx []int) {
+	if m.warn == nil {
+		return
+	}
+	report := func(j int) {
+		if msg := m.deprecation(j); msg != "" {
+			if _, seen := m.warned.LoadOrStore(m.cols[j], true); !seen {
+				m.warn(msg)
+			}
+		}
+	}
+	if idx == nil {
+		for j := range m.fields {
+			report(j)
+		}
+	}
+	for _, j := range idx {
+		report(j)
+	}
+}
@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDeprecated(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID       int64  `mapper:"id,pk"`
+		Name     string `mapper:"name,deprecated=use full_name"`
+		Nick     string `mapper:"nick,deprecated"`
+		FullName string `mapper:"full_name"`
+	}
+	var warnings []string
+	dut := MapperWithOptions(User{}, []MapperOption{WithWarnings(func(msg string) {
+		warnings = append(warnings, msg)
+	})}, "*")
+	is.Equal(dut.Warnings(), []string{
+		"column name is deprecated: use full_name",
+		"column nick is deprecated",
+	})
+
+	dut.Subset("id", "full_name")
+	is.Equal(warnings, nil)
+	dut.Subset("id", "nick")
+	is.Equal(warnings, []string{"column nick is deprecated"})
+	dut.SelectString("users")
+	dut.UpdateString("users")
+	is.Equal(warnings, []string{"column nick is deprecated", "column name is deprecated: use full_name"})
+
+	is.Equal(Mapper(User{}, "id", "full_name").Warnings(), nil)
+	Mapper(User{}, "*").InsertString("users") // no warnings without WithWarnings
+}
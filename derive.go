@@ -0,0 +1,230 @@
+package mapper
+
+import "slices"
+
+// Subset returns a mapper restricted to cols, in m column order. Like
+// [Mapper], it panics when some of cols are not mapped by m, so typos
+// surface early rather than as broken SQL. "*" selects every column.
+//
+//	var users = Mapper(User{}, "*")
+//	var names = users.Subset("id", "name")
+func (m *mapper) Subset(cols ...string) *mapper {
+	s, err := m.SubsetE(cols...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// SubsetE is like [Subset] but returns [ErrNoColumns], [ErrJoker] or an
+// [*ErrMissingColumns] instead of panicking.
+func (m *mapper) SubsetE(cols ...string) (*mapper, error) {
+	if len(cols) == 0 {
+		return nil, ErrNoColumns
+	}
+	if fieldSlice(cols).joker() {
+		if m.noJoker {
+			return nil, ErrJoker
+		}
+		return m.Clone(), nil
+	}
+	if missing := m.unknown(cols); len(missing) > 0 {
+		return nil, &ErrMissingColumns{Cols: missing}
+	}
+	idx := make([]int, 0, len(cols))
+	for j, col := range m.cols {
+		if fieldSlice(cols).index(col) != -1 {
+			idx = append(idx, j)
+		}
+	}
+	m.warnDeprecated(idx)
+	s := m.pick(idx)
+	s.joker = false // columns are now explicit
+	return s, nil
+}
+
+// Exclude returns a mapper with every column of m but cols, which is
+// handy to leave out a few sensitive or heavy columns:
+//
+//	var public = users.Exclude("password_hash", "internal_notes")
+//
+// Like [Subset], it panics when some of cols are not mapped by m.
+func (m *mapper) Exclude(cols ...string) *mapper {
+	s, err := m.ExcludeE(cols...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ExcludeE is like [Exclude] but returns an [*ErrMissingColumns], or
+// [ErrNoColumns] when nothing is left, instead of panicking.
+func (m *mapper) ExcludeE(cols ...string) (*mapper, error) {
+	if missing := m.unknown(cols); len(missing) > 0 {
+		return nil, &ErrMissingColumns{Cols: missing}
+	}
+	idx := make([]int, 0, len(m.cols))
+	for j, col := range m.cols {
+		if fieldSlice(cols).index(col) == -1 {
+			idx = append(idx, j)
+		}
+	}
+	if len(idx) == 0 {
+		return nil, ErrNoColumns
+	}
+	return m.pick(idx), nil
+}
+
+// Rename returns a mapper where columns are renamed following names, which
+// maps old column names to new ones. Order is kept. This fits a struct
+// mapped to differently named columns in another table or view:
+//
+//	var archived = users.Rename(map[string]string{"name": "user_name"})
+//
+// It panics when some old names are not mapped by m, when two columns end
+// up with the same name, or when a new name is not a safe identifier.
+func (m *mapper) Rename(names map[string]string) *mapper {
+	s, err := m.RenameE(names)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// RenameE is like [Rename] but returns an [*ErrMissingColumns], an
+// [*ErrDuplicateColumn] or an [*ErrUnsafeColumn] instead of panicking.
+func (m *mapper) RenameE(names map[string]string) (*mapper, error) {
+	var missing []string
+	for old := range names {
+		if fieldSlice(m.cols).index(old) == -1 {
+			missing = append(missing, old)
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return nil, &ErrMissingColumns{Cols: missing}
+	}
+	c := m.Clone()
+	c.cols = make([]string, 0, len(m.cols))
+	c.fields = make([]field, 0, len(m.fields))
+	for j, col := range m.cols {
+		f := m.fields[j]
+		if n, ok := names[col]; ok {
+			if err := checkColumn(n, f.opts); err != nil {
+				return nil, err
+			}
+			col = n
+			f.source = sourceRename
+		}
+		if k := fieldSlice(c.cols).index(col); k != -1 {
+			return nil, &ErrDuplicateColumn{
+				Col:     col,
+				Fields:  []string{c.fields[k].Name, f.Name},
+				Sources: []string{c.fields[k].source, f.source},
+			}
+		}
+		c.cols = append(c.cols, col)
+		c.fields = append(c.fields, f)
+	}
+	return c, nil
+}
+
+// Reorder returns a mapper whose columns come in the order of cols, so
+// generated lists match an order imposed from outside, like an existing
+// statement, a COPY target or a CSV layout. Columns of m not in cols follow,
+// in their original order; use [Subset] to drop them.
+//
+// It panics when some of cols are not mapped by m or are listed twice.
+func (m *mapper) Reorder(cols ...string) *mapper {
+	s, err := m.ReorderE(cols...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ReorderE is like [Reorder] but returns an [*ErrMissingColumns] or an
+// [*ErrDuplicateColumn] instead of panicking.
+func (m *mapper) ReorderE(cols ...string) (*mapper, error) {
+	if missing := m.unknown(cols); len(missing) > 0 {
+		return nil, &ErrMissingColumns{Cols: missing}
+	}
+	idx := make([]int, 0, len(m.cols))
+	for k, col := range cols {
+		if fieldSlice(cols[:k]).index(col) != -1 {
+			return nil, &ErrDuplicateColumn{Col: col}
+		}
+		idx = append(idx, fieldSlice(m.cols).index(col))
+	}
+	for j, col := range m.cols {
+		if fieldSlice(cols).index(col) == -1 {
+			idx = append(idx, j)
+		}
+	}
+	return m.pick(idx), nil
+}
+
+// Union returns a mapper with the columns of m followed by those of other
+// not already in m. Both MUST share the same target type. Subsets built for
+// distinct features can then be composed back into wider mappers:
+//
+//	var profile = users.Subset("id", "name").Union(users.Subset("email"))
+//
+// It panics when targets differ, or when a column maps to different fields
+// in m and other.
+func (m *mapper) Union(other *mapper) *mapper {
+	s, err := m.UnionE(other)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// UnionE is like [Union] but returns an [*ErrTypeMismatch] or an
+// [*ErrDuplicateColumn] instead of panicking.
+func (m *mapper) UnionE(other *mapper) (*mapper, error) {
+	if other.elem != m.elem {
+		return nil, &ErrTypeMismatch{Got: other.elem, Want: m.elem}
+	}
+	c := m.pick(nil)
+	c.cols = append(c.cols, m.cols...)
+	c.fields = append(c.fields, m.fields...)
+	for j, col := range other.cols {
+		if k := fieldSlice(m.cols).index(col); k != -1 {
+			if !slices.Equal(m.fields[k].Index, other.fields[j].Index) {
+				return nil, &ErrDuplicateColumn{
+					Col:     col,
+					Fields:  []string{m.fields[k].Name, other.fields[j].Name},
+					Sources: []string{m.fields[k].source, other.fields[j].source},
+				}
+			}
+			continue
+		}
+		c.cols = append(c.cols, col)
+		c.fields = append(c.fields, other.fields[j])
+	}
+	return c, nil
+}
+
+// unknown returns those of cols not mapped by m.
+func (m *mapper) unknown(cols []string) (missing []string) {
+	for _, col := range cols {
+		if fieldSlice(m.cols).index(col) == -1 {
+			missing = append(missing, col)
+		}
+	}
+	return
+}
+
+// pick returns a copy of m mapping the columns at positions idx of m, in
+// that order.
+func (m *mapper) pick(idx []int) *mapper {
+	c := m.Clone()
+	c.cols = make([]string, len(idx))
+	c.fields = make([]field, len(idx))
+	for k, j := range idx {
+		c.cols[k] = m.cols[j]
+		c.fields[k] = m.fields[j]
+	}
+	return c
+}
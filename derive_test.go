@@ -0,0 +1,97 @@
+package mapper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type deriveRecord struct {
+	ID    int
+	Name  string
+	Email string
+}
+
+func TestSubset(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(deriveRecord{}, "*")
+
+	sub := dut.Subset("email", "id")
+	is.Equal(sub.Columns(), []string{"id", "email"})
+	r := &deriveRecord{ID: 1, Email: "e"}
+	is.Equal(sub.Values(r), []any{1, "e"})
+	is.Equal(sub.Addrs(r), []any{&r.ID, &r.Email})
+	is.Equal(dut.Columns(), []string{"id", "name", "email"})
+
+	_, err := dut.SubsetE("id", "nmae")
+	var missing *ErrMissingColumns
+	is.True(errors.As(err, &missing))
+	is.Equal(missing.Cols, []string{"nmae"})
+}
+
+func TestExclude(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(deriveRecord{}, "*")
+
+	is.Equal(dut.Exclude("name").Columns(), []string{"id", "email"})
+
+	_, err := dut.ExcludeE("id", "name", "email")
+	is.True(errors.Is(err, ErrNoColumns))
+	_, err = dut.ExcludeE("nmae")
+	var missing *ErrMissingColumns
+	is.True(errors.As(err, &missing))
+}
+
+func TestRename(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(deriveRecord{}, "*")
+
+	ren := dut.Rename(map[string]string{"name": "user_name"})
+	is.Equal(ren.Columns(), []string{"id", "user_name", "email"})
+	is.Equal(ren.ColumnsString(), "id,user_name,email")
+	is.Equal(dut.ColumnsString(), "id,name,email")
+
+	_, err := dut.RenameE(map[string]string{"name": "email"})
+	var dup *ErrDuplicateColumn
+	is.True(errors.As(err, &dup))
+	_, err = dut.RenameE(map[string]string{"nmae": "x"})
+	var missing *ErrMissingColumns
+	is.True(errors.As(err, &missing))
+	_, err = dut.RenameE(map[string]string{"name": "name;"})
+	var unsafe *ErrUnsafeColumn
+	is.True(errors.As(err, &unsafe))
+}
+
+func TestReorder(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(deriveRecord{}, "*")
+
+	re := dut.Reorder("email", "id")
+	is.Equal(re.Columns(), []string{"email", "id", "name"})
+	r := &deriveRecord{ID: 1, Name: "n", Email: "e"}
+	is.Equal(re.Values(r), []any{"e", 1, "n"})
+	is.Equal(re.Addrs(r), []any{&r.Email, &r.ID, &r.Name})
+
+	_, err := dut.ReorderE("id", "id")
+	var dup *ErrDuplicateColumn
+	is.True(errors.As(err, &dup))
+}
+
+func TestUnion(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(deriveRecord{}, "*")
+
+	u := dut.Subset("email").Union(dut.Subset("id", "email"))
+	is.Equal(u.Columns(), []string{"email", "id"})
+	r := &deriveRecord{ID: 1, Email: "e"}
+	is.Equal(u.Values(r), []any{"e", 1})
+
+	_, err := dut.UnionE(Mapper(scanRecord{}, "*"))
+	var mismatch *ErrTypeMismatch
+	is.True(errors.As(err, &mismatch))
+
+	_, err = dut.UnionE(dut.Rename(map[string]string{"name": "x", "email": "name"}))
+	var dup *ErrDuplicateColumn
+	is.True(errors.As(err, &dup))
+}
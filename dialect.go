@@ -0,0 +1,11 @@
+package mapper
+
+// Dialect identifies a SQL database flavor, for the few helpers that need to
+// speak it, such as [ValidateSchema]. Set it with [WithDialect].
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+	SQLite   Dialect = "sqlite"
+)
@@ -0,0 +1,424 @@
+package mapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// eagerBatch bounds the keys of a single eager loading query.
+const eagerBatch = 500
+
+// Eager returns a mapper of m whose [Query] results come with related
+// records loaded, following paths of relation names, see [Rel]. Relations
+// are named after their target table, and nested ones are dotted:
+//
+//	us, err := Query[User](ctx, users.Eager("orders", "orders.lines"), db, users.SelectString(""))
+//
+// Related records are loaded by a query per relation and up to 500 parent
+// records, as in SELECT ... FROM orders WHERE user_id IN (?,?,?), see
+// [EagerJoin] for a single query.
+//
+// It panics when a path does not name declared relations.
+func (m *mapper) Eager(paths ...string) *mapper {
+	for _, p := range paths {
+		cur := m
+		for name := range strings.SplitSeq(p, ".") {
+			cur = cur.relNamed(name).target
+		}
+	}
+	return m.With(func(c *mapper) {
+		c.eager = append(c.eager[:len(c.eager):len(c.eager)], paths...)
+	})
+}
+
+// relNamed returns the relation of m whose target table is name, or panics.
+func (m *mapper) relNamed(name string) relation {
+	for _, r := range m.rels {
+		if r.target.Table() == name {
+			return r
+		}
+	}
+	panic(fmt.Errorf("no relation of %s named %s, see Rel", m.elem, name))
+}
+
+// loadEager loads the relations of paths into recs, a slice of m records
+// or of pointers to them.
+func (m *mapper) loadEager(ctx context.Context, db Queryer, recs reflect.Value, paths []string) error {
+	if recs.Len() == 0 {
+		return nil
+	}
+	// Group nested paths by first relation, in order.
+	var names []string
+	nested := make(map[string][]string)
+	for _, p := range paths {
+		name, rest, _ := strings.Cut(p, ".")
+		if _, ok := nested[name]; !ok {
+			names = append(names, name)
+			nested[name] = nil
+		}
+		if rest != "" {
+			nested[name] = append(nested[name], rest)
+		}
+	}
+	for _, name := range names {
+		if err := m.loadRel(ctx, db, recs, m.relNamed(name), nested[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRel loads the records related to recs by r, and their own relations
+// of paths.
+func (m *mapper) loadRel(ctx context.Context, db Queryer, recs reflect.Value, r relation, paths []string) error {
+	t := r.target
+	// Keys of recs, and the target column holding them.
+	keyField, keyCol := m.fields[m.pkIndexes()[0]], r.foreignKey
+	if r.kind == BelongsTo {
+		keyField, keyCol = m.fields[fieldSlice(m.cols).index(r.foreignKey)], t.cols[t.pkIndexes()[0]]
+	}
+	var keys []any
+	seen := make(map[any]bool)
+	for i := range recs.Len() {
+		k := eagerKey(reflect.Indirect(recs.Index(i)).FieldByIndex(keyField.Index))
+		if k != nil && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+
+	children := reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(t.elem)), 0, len(keys))
+	for len(keys) > 0 {
+		batch := keys[:min(eagerBatch, len(keys))]
+		keys = keys[len(batch):]
+		var err error
+		if children, err = t.queryIn(ctx, db, keyCol, batch, children); err != nil {
+			return err
+		}
+	}
+	if len(paths) > 0 {
+		if err := t.loadEager(ctx, db, children, paths); err != nil {
+			return err
+		}
+	}
+
+	// Children by key.
+	byKey := make(map[any][]reflect.Value)
+	childKey := t.fields[fieldSlice(t.cols).index(keyCol)]
+	for i := range children.Len() {
+		c := children.Index(i)
+		k := eagerKey(c.Elem().FieldByIndex(childKey.Index))
+		byKey[k] = append(byKey[k], c)
+	}
+	for i := range recs.Len() {
+		rec := reflect.Indirect(recs.Index(i))
+		cs := byKey[eagerKey(rec.FieldByIndex(keyField.Index))]
+		fv := rec.FieldByIndex(r.field)
+		fv.SetZero() // loaded afresh, even for a record shared by an identity map
+		for _, c := range cs {
+			setRelated(fv, c)
+		}
+	}
+	return nil
+}
+
+// setRelated sets c, a pointer to a related record, into fv, the relation
+// field holding it, appending it to slices.
+func setRelated(fv, c reflect.Value) {
+	switch {
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Pointer:
+		fv.Set(reflect.Append(fv, c))
+	case fv.Kind() == reflect.Slice:
+		fv.Set(reflect.Append(fv, c.Elem()))
+	case fv.Kind() == reflect.Pointer:
+		fv.Set(c)
+	default:
+		fv.Set(c.Elem())
+	}
+}
+
+// queryIn appends to recs pointers to the m records whose col is in keys,
+// shared within an identity map, see [IdentityContext].
+func (m *mapper) queryIn(ctx context.Context, db Queryer, col string, keys []any, recs reflect.Value) (reflect.Value, error) {
+	query := m.SelectString("")
+	args, err := m.TenantArgs(ctx)
+	if err != nil {
+		return recs, err
+	}
+	if len(args) > 0 {
+		query += " AND "
+	} else {
+		query += " WHERE "
+	}
+	query += col + " IN (" + m.placeholders(len(args)+1, len(keys)) + ")"
+	args = append(args, keys...)
+
+	ctx, end := m.startStatement(ctx, m.Table(), query, args)
+	n := recs.Len()
+	defer func() {
+		end(int64(recs.Len()-n), err)
+	}()
+	rows, err := m.queryContext(ctx, db, query, args)
+	if err != nil {
+		return recs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rec := reflect.New(m.elem)
+		if err = m.scan(rows, rec.Interface()); err != nil {
+			return recs, err
+		}
+		if cached := m.identity(ctx, rec); cached.Pointer() != rec.Pointer() {
+			recs = reflect.Append(recs, cached)
+			continue
+		}
+		if err = afterScan(ctx, rec.Interface()); err != nil {
+			return recs, err
+		}
+		recs = reflect.Append(recs, rec)
+	}
+	err = rows.Err()
+	return recs, err
+}
+
+// eagerKey returns the key value held by v, as a driver value for
+// sql.Null types, or nil for NULL.
+func eagerKey(v reflect.Value) any {
+	k := v.Interface()
+	if valuer, ok := k.(driver.Valuer); ok {
+		k, _ = valuer.Value()
+	}
+	return k
+}
+
+// EagerJoin is like [Eager] but loads related records with the query of
+// [EagerSelectString], joining the tables of every path:
+//
+//	users = users.EagerJoin("orders", "orders.lines")
+//	us, err := Query[User](ctx, users, db, users.EagerSelectString()+" WHERE users.id=?", id)
+//
+// Rows of the same primary key make a single record, so m and the targets
+// of paths need one. Joining several [HasMany] relations of a record
+// multiplies its rows, which [Eager] spares.
+func (m *mapper) EagerJoin(paths ...string) *mapper {
+	return m.Eager(paths...).With(func(c *mapper) {
+		c.eagerJoin = true
+	})
+}
+
+// joinPath is a relation of an [EagerJoin] query, whose target table is
+// joined as alias.
+type joinPath struct {
+	parent *joinPath // nil for relations of the queried mapper
+	owner  *mapper
+	rel    relation
+	alias  string
+}
+
+// joinPaths returns the relations of the eager paths of m, parents first,
+// in the order of their columns in [EagerSelectString].
+func (m *mapper) joinPaths() []*joinPath {
+	var res []*joinPath
+	byPath := make(map[string]*joinPath)
+	for _, p := range m.eager {
+		var parent *joinPath
+		owner, path := m, ""
+		for name := range strings.SplitSeq(p, ".") {
+			path += name
+			jp, ok := byPath[path]
+			if !ok {
+				jp = &joinPath{parent: parent, owner: owner, rel: owner.relNamed(name), alias: strings.ReplaceAll(path, ".", "_")}
+				byPath[path] = jp
+				res = append(res, jp)
+			}
+			parent, owner = jp, jp.rel.target
+			path += "."
+		}
+	}
+	return res
+}
+
+// EagerSelectString returns a SELECT statement of the columns of m then
+// those of the relations of [EagerJoin], from the table of m left joined
+// to theirs, to be completed with WHERE, ORDER BY... clauses:
+//
+//	SELECT users.id,users.name,orders.id,orders.user_id,orders_lines.id,orders_lines.sku FROM users
+//	LEFT JOIN orders ON orders.user_id=users.id LEFT JOIN lines orders_lines ON orders_lines.order_id=orders.id
+//
+// Nested relations are aliased after their path. Tables with a tenant
+// column, see [WithTenantColumn], take the tenant as argument, those
+// joined in order then the table of m in a WHERE clause, see [EagerArgs].
+// It panics on expr columns, as [JoinSelectString] does.
+func (m *mapper) EagerSelectString() string {
+	table := m.Table()
+	var b strings.Builder
+	b.WriteString("SELECT " + m.selectList(table+"."))
+	paths := m.joinPaths()
+	for _, jp := range paths {
+		b.WriteString(m.sep() + jp.rel.target.selectList(jp.alias+"."))
+	}
+	b.WriteString(" FROM " + table)
+	n := 0
+	for _, jp := range paths {
+		t, owner := jp.rel.target, table
+		if jp.parent != nil {
+			owner = jp.parent.alias
+		}
+		b.WriteString(" LEFT JOIN " + t.Table())
+		if jp.alias != t.Table() {
+			b.WriteString(" " + jp.alias)
+		}
+		if jp.rel.kind == HasMany {
+			b.WriteString(" ON " + jp.alias + "." + jp.rel.foreignKey + "=" + owner + "." + jp.owner.cols[jp.owner.pkIndexes()[0]])
+		} else {
+			b.WriteString(" ON " + jp.alias + "." + t.cols[t.pkIndexes()[0]] + "=" + owner + "." + jp.rel.foreignKey)
+		}
+		if t.tenantCol != "" {
+			n++
+			b.WriteString(" AND " + jp.alias + "." + t.tenantCol + "=" + m.placeholder(n))
+		}
+	}
+	if m.tenantCol != "" {
+		b.WriteString(" WHERE " + table + "." + m.tenantCol + "=" + m.placeholder(n+1))
+	}
+	return b.String()
+}
+
+// EagerArgs returns args preceded by the tenants of ctx the tables of
+// [EagerSelectString] take, as [TenantArgs] does.
+func (m *mapper) EagerArgs(ctx context.Context, args ...any) ([]any, error) {
+	var tenants []any
+	for _, t := range append(m.joinTargets(), m) {
+		if t.tenantCol == "" {
+			continue
+		}
+		v, err := t.tenant(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, v)
+	}
+	return append(tenants, args...), nil
+}
+
+// joinTargets returns the targets of the relations of [EagerJoin].
+func (m *mapper) joinTargets() []*mapper {
+	var res []*mapper
+	for _, jp := range m.joinPaths() {
+		res = append(res, jp.rel.target)
+	}
+	return res
+}
+
+// joinedRec is a record of an [EagerJoin] query, with its related records
+// by relation.
+type joinedRec struct {
+	ptr     reflect.Value // pointer to the record
+	related map[*joinPath][]*joinedRec
+	seen    map[*joinPath]map[any]*joinedRec
+}
+
+// add adds child, a pointer to a record related to r by jp, unless a
+// record of the same primary key was, and returns the related record.
+func (r *joinedRec) add(jp *joinPath, child reflect.Value) *joinedRec {
+	t := jp.rel.target
+	key := relKey(t, child.Elem(), t.pkIndexes())
+	if r.seen == nil {
+		r.seen = make(map[*joinPath]map[any]*joinedRec)
+		r.related = make(map[*joinPath][]*joinedRec)
+	}
+	if r.seen[jp] == nil {
+		r.seen[jp] = make(map[any]*joinedRec)
+	}
+	if c, ok := r.seen[jp][key]; ok {
+		return c
+	}
+	c := &joinedRec{ptr: child}
+	r.seen[jp][key] = c
+	r.related[jp] = append(r.related[jp], c)
+	return c
+}
+
+// set sets the relation fields of r, an m record, to its related records,
+// set first. Records are shared within an identity map when refs is set,
+// see [IdentityContext], and the [AfterScanner] hooks of fresh ones run.
+func (r *joinedRec) set(ctx context.Context, m *mapper, refs bool) error {
+	fresh := true
+	if refs {
+		cached := m.identity(ctx, r.ptr)
+		fresh = cached.Pointer() == r.ptr.Pointer()
+		r.ptr = cached
+	}
+	for jp, cs := range r.related {
+		fv := r.ptr.Elem().FieldByIndex(jp.rel.field)
+		fv.SetZero() // loaded afresh, even for a record shared by an identity map
+		for _, c := range cs {
+			if err := c.set(ctx, jp.rel.target, refs); err != nil {
+				return err
+			}
+			setRelated(fv, c.ptr)
+		}
+	}
+	if !fresh {
+		return nil
+	}
+	return afterScan(ctx, r.ptr.Interface())
+}
+
+// scanJoined scans rows of an [EagerSelectString] query into pointers to m
+// records, see [joinedRec.set] for refs.
+func (m *mapper) scanJoined(ctx context.Context, rows Rows, refs bool) ([]reflect.Value, error) {
+	paths := m.joinPaths()
+	pks := m.pkIndexes()
+	var recs []*joinedRec
+	seen := make(map[any]*joinedRec)
+	scans := make([]*relScan, len(paths))
+	for i, jp := range paths {
+		scans[i] = newRelScan(jp.rel.target)
+	}
+	for rows.Next() {
+		rec := reflect.New(m.elem)
+		addrs := m.Addrs(rec.Interface())
+		for _, s := range scans {
+			addrs = s.addrs(addrs)
+		}
+		if err := rows.Scan(addrs...); err != nil {
+			return nil, err
+		}
+		key := relKey(m, rec.Elem(), pks)
+		r, ok := seen[key]
+		if !ok {
+			r = &joinedRec{ptr: rec}
+			seen[key] = r
+			recs = append(recs, r)
+		}
+		// Related records of the row, missing for NULL columns.
+		related := make(map[*joinPath]*joinedRec, len(paths))
+		for i, jp := range paths {
+			owner := r
+			if jp.parent != nil {
+				owner = related[jp.parent]
+			}
+			if owner == nil {
+				continue
+			}
+			if child, ok := scans[i].record(); ok {
+				related[jp] = owner.add(jp, child)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	res := make([]reflect.Value, len(recs))
+	for i, r := range recs {
+		if err := r.set(ctx, m, refs); err != nil {
+			return nil, err
+		}
+		res[i] = r.ptr
+	}
+	return res, nil
+}
@@ -0,0 +1,179 @@
+package mapper
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type eagerLine struct {
+	ID      int64 `mapper:"id,pk"`
+	OrderID int64 `mapper:"order_id"`
+	SKU     string
+}
+
+type eagerOrder struct {
+	ID     int64       `mapper:"id,pk"`
+	UserID int64       `mapper:"user_id"`
+	Lines  []eagerLine `mapper:",ignore"`
+	Buyer  *eagerBuyer `mapper:",ignore"`
+}
+
+type eagerBuyer struct {
+	ID     int64         `mapper:"id,pk"`
+	Name   string        `mapper:"name"`
+	Orders []*eagerOrder `mapper:",ignore"`
+}
+
+func TestEager(t *testing.T) {
+	is := is.New(t)
+	conn := &fakeConnector{answer: func(query string, args []driver.Value) ([]string, [][]driver.Value) {
+		switch query {
+		case "SELECT id,name FROM buyers":
+			return []string{"id", "name"}, [][]driver.Value{{int64(1), "ann"}, {int64(2), "bob"}}
+		case "SELECT id,user_id FROM orders WHERE user_id IN (?,?)":
+			return []string{"id", "user_id"}, [][]driver.Value{{int64(10), int64(1)}, {int64(11), int64(1)}, {int64(12), int64(2)}}
+		case "SELECT id,order_id,sku FROM lines WHERE order_id IN (?,?,?)":
+			return []string{"id", "order_id", "sku"}, [][]driver.Value{{int64(100), int64(10), "a"}, {int64(101), int64(12), "b"}}
+		case "SELECT id,name FROM buyers WHERE id IN (?)":
+			return []string{"id", "name"}, [][]driver.Value{{int64(1), "ann"}}
+		case "SELECT id,user_id FROM orders":
+			return []string{"id", "user_id"}, [][]driver.Value{{int64(10), int64(1)}}
+		}
+		t.Fatalf("unexpected query %s", query)
+		return nil, nil
+	}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	ctx := context.Background()
+
+	lines := MapperWithOptions(eagerLine{}, []MapperOption{WithFieldMapper(SnakeCase), WithTable("lines")}, "*")
+	orders := Mapper(eagerOrder{}, "*").SetOptions(WithTable("orders")).Rel(lines, "order_id", HasMany)
+	buyers := Mapper(eagerBuyer{}, "*").SetOptions(WithTable("buyers")).Rel(orders, "user_id", HasMany)
+
+	bs, err := Query[eagerBuyer](ctx, buyers.Eager("orders", "orders.lines"), db, buyers.SelectString(""))
+	is.NoErr(err)
+	is.Equal(len(bs), 2)
+	is.Equal(len(bs[0].Orders), 2)
+	is.Equal(bs[0].Orders[0].Lines, []eagerLine{{100, 10, "a"}})
+	is.Equal(len(bs[0].Orders[1].Lines), 0)
+	is.Equal(bs[1].Orders[0].Lines[0].SKU, "b")
+
+	orders = orders.Rel(Mapper(eagerBuyer{}, "*").SetOptions(WithTable("buyers")), "user_id", BelongsTo)
+	os, err := Query[eagerOrder](ctx, orders.Eager("buyers"), db, orders.SelectString(""))
+	is.NoErr(err)
+	is.Equal(os[0].Buyer.Name, "ann")
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+	buyers.Eager("orders.buyers.orders")
+}
+
+func TestEagerReadOnly(t *testing.T) {
+	is := is.New(t)
+	conn := &fakeConnector{answer: func(query string, args []driver.Value) ([]string, [][]driver.Value) {
+		switch query {
+		case "SELECT id,name FROM buyers":
+			return []string{"id", "name"}, [][]driver.Value{{int64(1), "ann"}, {int64(2), "bob"}}
+		case "SELECT id,user_id FROM orders WHERE user_id IN (?,?)":
+			return []string{"id", "user_id"}, [][]driver.Value{{int64(10), int64(1)}, {int64(12), int64(2)}}
+		}
+		t.Fatalf("unexpected query %s", query)
+		return nil, nil
+	}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	// As many parent keys as target columns, which Marks would write.
+	orders := Mapper(eagerOrder{}, "*").SetOptions(WithTable("orders")).ReadOnly()
+	buyers := Mapper(eagerBuyer{}, "*").SetOptions(WithTable("buyers")).Rel(orders, "user_id", HasMany)
+
+	bs, err := Query[eagerBuyer](context.Background(), buyers.Eager("orders"), db, buyers.SelectString(""))
+	is.NoErr(err)
+	is.Equal(bs[0].Orders[0].ID, int64(10))
+	is.Equal(bs[1].Orders[0].ID, int64(12))
+}
+
+func TestEagerJoin(t *testing.T) {
+	is := is.New(t)
+	lines := MapperWithOptions(eagerLine{}, []MapperOption{WithFieldMapper(SnakeCase), WithTable("lines")}, "*")
+	orders := Mapper(eagerOrder{}, "*").SetOptions(WithTable("orders")).Rel(lines, "order_id", HasMany)
+	buyers := Mapper(eagerBuyer{}, "*").SetOptions(WithTable("buyers")).Rel(orders, "user_id", HasMany).EagerJoin("orders.lines")
+
+	query := buyers.EagerSelectString()
+	is.Equal(query, "SELECT buyers.id,buyers.name,orders.id,orders.user_id,orders_lines.id,orders_lines.order_id,orders_lines.sku FROM buyers"+
+		" LEFT JOIN orders ON orders.user_id=buyers.id LEFT JOIN lines orders_lines ON orders_lines.order_id=orders.id")
+	conn := &fakeConnector{answer: func(q string, args []driver.Value) ([]string, [][]driver.Value) {
+		is.Equal(q, query)
+		return []string{"id", "name", "id", "user_id", "id", "order_id", "sku"}, [][]driver.Value{
+			{int64(1), "ann", int64(10), int64(1), int64(100), int64(10), "a"},
+			{int64(1), "ann", int64(10), int64(1), int64(102), int64(10), "c"},
+			{int64(1), "ann", int64(11), int64(1), nil, nil, nil},
+			{int64(2), "bob", nil, nil, nil, nil, nil},
+		}
+	}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	bs, err := Query[eagerBuyer](context.Background(), buyers, db, query)
+	is.NoErr(err)
+	is.Equal(len(bs), 2)
+	is.Equal(len(bs[0].Orders), 2)
+	is.Equal(bs[0].Orders[0].Lines, []eagerLine{{100, 10, "a"}, {102, 10, "c"}})
+	is.Equal(len(bs[0].Orders[1].Lines), 0)
+	is.Equal(len(bs[1].Orders), 0)
+
+	ctx := IdentityContext(context.Background())
+	refs, err := QueryRefs[eagerBuyer](ctx, buyers, db, query)
+	is.NoErr(err)
+	again, err := QueryRefs[eagerBuyer](ctx, buyers, db, query)
+	is.NoErr(err)
+	is.True(refs[0] == again[0])
+	is.True(refs[0].Orders[0] == again[0].Orders[0])
+}
+
+func TestEagerJoinConverted(t *testing.T) {
+	is := is.New(t)
+	k, err := NewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	is.NoErr(err)
+	notes := Mapper(relNote{}, "*").SetOptions(WithTable("notes"), WithCipher(k))
+	readers := Mapper(relReader{}, "*").SetOptions(WithTable("readers")).Rel(notes, "user_id", HasMany).EagerJoin("notes")
+
+	const id = "6ccd780c-baba-1026-9564-5b8c656024db"
+	vals := notes.Values(relNote{id, 1, "secret"})
+	conn := &fakeConnector{answer: func(q string, args []driver.Value) ([]string, [][]driver.Value) {
+		return []string{"id", "id", "user_id", "body"}, [][]driver.Value{
+			{int64(1), vals[0], int64(1), vals[2]},
+			{int64(2), nil, nil, nil},
+		}
+	}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	rs, err := Query[relReader](context.Background(), readers, db, readers.EagerSelectString())
+	is.NoErr(err)
+	is.Equal(rs[0].Notes, []relNote{{id, 1, "secret"}})
+	is.Equal(len(rs[1].Notes), 0)
+}
+
+func TestEagerJoinTenant(t *testing.T) {
+	is := is.New(t)
+	org := WithTenantColumn("org_id", func(ctx context.Context) any {
+		return ctx.Value(orgKey{})
+	})
+	orders := Mapper(eagerOrder{}, "*").SetOptions(WithTable("orders"), org)
+	buyers := Mapper(eagerBuyer{}, "*").SetOptions(WithTable("buyers"), WithDialect(Postgres), org).Rel(orders, "user_id", HasMany).EagerJoin("orders")
+
+	is.Equal(buyers.EagerSelectString(), "SELECT buyers.id,buyers.name,orders.id,orders.user_id FROM buyers"+
+		" LEFT JOIN orders ON orders.user_id=buyers.id AND orders.org_id=$1 WHERE buyers.org_id=$2")
+	args, err := buyers.EagerArgs(context.WithValue(context.Background(), orgKey{}, int64(7)), "ann")
+	is.NoErr(err)
+	is.Equal(args, []any{int64(7), int64(7), "ann"})
+	_, err = buyers.EagerArgs(context.Background())
+	is.Equal(err, ErrNoTenant)
+}
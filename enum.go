@@ -0,0 +1,63 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// RegisterEnum has fields of type T, or *T, stored as the database values
+// of values by every mapper, sparing Valuer and Scanner implementations:
+//
+//	type Status int
+//
+//	const (
+//	  Active Status = iota
+//	  Banned
+//	)
+//
+//	func init() {
+//	  mapper.RegisterEnum(map[Status]string{Active: "active", Banned: "banned"})
+//	}
+//
+// [Values] then gives "active" for Active, and scans turn "banned" into
+// Banned. Values missing from the registration fail the statement or the
+// scan. Nil *T fields are NULL. It is a converter, see [RegisterConverter].
+//
+// It panics when two Go values share a database value. Registering T
+// again replaces its values.
+func RegisterEnum[T comparable, D string | int64](values map[T]D) {
+	t := reflect.TypeFor[T]()
+	fromDB := make(map[D]T, len(values))
+	for v, d := range values {
+		if prev, ok := fromDB[d]; ok {
+			panic(fmt.Errorf("RegisterEnum: %v and %v are both stored as %v", prev, v, d))
+		}
+		fromDB[d] = v
+	}
+	RegisterConverter(
+		func(v T) (driver.Value, error) {
+			d, ok := values[v]
+			if !ok {
+				return nil, fmt.Errorf("%v is not a registered %s value", v, t)
+			}
+			return d, nil
+		},
+		func(src any) (T, error) {
+			switch s := src.(type) {
+			case []byte:
+				src = string(s)
+			case int32:
+				src = int64(s)
+			case int:
+				src = int64(s)
+			}
+			d, ok := src.(D)
+			v, known := fromDB[d]
+			if !ok || !known {
+				return v, fmt.Errorf("%v is not a registered %s value", src, t)
+			}
+			return v, nil
+		},
+	)
+}
@@ -0,0 +1,52 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type enumStatus int
+
+const (
+	enumActive enumStatus = iota
+	enumBanned
+)
+
+type enumUser struct {
+	ID       int64       `mapper:"id"`
+	Status   enumStatus  `mapper:"status"`
+	Previous *enumStatus `mapper:"previous"`
+}
+
+func TestRegisterEnum(t *testing.T) {
+	is := is.New(t)
+	RegisterEnum(map[enumStatus]string{enumActive: "active", enumBanned: "banned"})
+	dut := Mapper(enumUser{}, "*")
+
+	banned := enumBanned
+	is.Equal(dut.Values(enumUser{1, enumActive, &banned}), []any{int64(1), "active", "banned"})
+	is.Equal(dut.Values(enumUser{1, enumBanned, nil}), []any{int64(1), "banned", nil})
+	_, err := dut.Values(enumUser{Status: 7})[1].(driver.Valuer).Value()
+	is.True(err != nil) // not registered
+
+	rows := queryFake(t, []string{"id", "status", "previous"},
+		[]driver.Value{int64(1), "banned", "active"},
+		[]driver.Value{int64(2), []byte("active"), nil},
+		[]driver.Value{int64(3), "deleted", nil},
+	)
+	var u enumUser
+	is.True(rows.Next())
+	is.NoErr(rows.Scan(dut.Addrs(&u)...))
+	is.Equal(u.Status, enumBanned)
+	is.Equal(*u.Previous, enumActive)
+	is.True(rows.Next())
+	is.NoErr(rows.Scan(dut.Addrs(&u)...))
+	is.Equal(u, enumUser{2, enumActive, nil})
+	is.True(rows.Next())
+	is.True(rows.Scan(dut.Addrs(&u)...) != nil)
+
+	unsafe := dut.With(WithUnsafe())
+	is.Equal(unsafe.Values(&enumUser{1, enumBanned, nil}), []any{int64(1), "banned", nil})
+}
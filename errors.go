@@ -0,0 +1,167 @@
+package mapper
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotStruct is returned when a mapper target is neither a struct nor
+	// a struct pointer.
+	ErrNotStruct = errors.New("Mapper first argument MUST be a struct or a struct pointer")
+
+	// ErrNoColumns is returned when a mapper is built without columns.
+	ErrNoColumns = errors.New("Mapper MUST select at least one field")
+
+	// ErrEmptyKey is returned when a mapper is built with an empty tag key.
+	ErrEmptyKey = errors.New("Mapper MUST have a non empty struct tag key.")
+
+	// ErrJoker is returned when "*" is used while forbidden by
+	// [WithNoJoker].
+	ErrJoker = errors.New("Mapper MUST list columns explicitly, \"*\" is forbidden")
+
+	// ErrNotPointer is returned when a destination is not a pointer.
+	ErrNotPointer = errors.New("destination not a pointer")
+
+	// ErrNilPointer is returned when a destination is a nil pointer.
+	ErrNilPointer = errors.New("destination is a nil pointer")
+
+	// ErrDestNotStruct is returned when a destination is not a struct or
+	// does not point to one.
+	ErrDestNotStruct = errors.New("destination not a struct")
+
+	// ErrNoPrimaryKey is returned when a statement needs a primary key while
+	// no mapped column is tagged pk.
+	ErrNoPrimaryKey = errors.New("Mapper has no primary key, tag some field pk")
+
+	// ErrNoTenant is returned when a statement is run without a tenant in
+	// its context, see [WithTenantColumn].
+	ErrNoTenant = errors.New("no tenant in context")
+
+	// ErrNoTable is returned when a table is needed while none was set and
+	// the target struct is anonymous, see [Table].
+	ErrNoTable = errors.New("Mapper has no table, use WithTable")
+
+	// ErrReadOnly is returned when a read only mapper is used to write, see
+	// [ReadOnly].
+	ErrReadOnly = errors.New("Mapper is read only")
+
+	// ErrNoPartition is returned when a table is routed while no mapped
+	// column is tagged partition, see [RouteTable].
+	ErrNoPartition = errors.New("Mapper has no partition key, tag some field partition")
+)
+
+// ErrTypeMismatch is returned when a destination is not of the mapper
+// target type.
+type ErrTypeMismatch struct {
+	Got, Want reflect.Type
+}
+
+func (e *ErrTypeMismatch) Error() string {
+	return "destination type " + e.Got.String() + " does not match " + e.Want.String()
+}
+
+// ErrDuplicateColumn is returned when two fields map to the same column.
+type ErrDuplicateColumn struct {
+	Col string
+
+	// Fields are the colliding struct fields, when known, and Sources tell
+	// where each got Col from: "tag", "FieldMapper", "field name" or
+	// "Rename".
+	Fields  []string
+	Sources []string
+}
+
+func (e *ErrDuplicateColumn) Error() string {
+	msg := "Field " + e.Col + " is mapped more than once"
+	for i, f := range e.Fields {
+		if i == 0 {
+			msg += ": "
+		} else {
+			msg += " and "
+		}
+		msg += f + " (" + e.Sources[i] + ")"
+	}
+	return msg
+}
+
+// ErrMissingColumns is returned when requested columns match no field.
+type ErrMissingColumns struct {
+	Cols []string
+}
+
+func (e *ErrMissingColumns) Error() string {
+	return "Some fields are missing from target: " + strings.Join(e.Cols, ",")
+}
+
+// ErrUnsafeColumn is returned when a column name could inject SQL. Names
+// are words made of letters, digits, underscores and dollars, possibly
+// qualified as in u.id, or quoted identifiers such as "first name", `order`
+// or [order], which may hold dots, as "a.b". Words need not be valid
+// identifiers in every dialect, they only cannot alter the statement they
+// are part of.
+//
+// Fields tagged raw, as in `mapper:"count(*),raw"`, are not checked, so
+// their column can be any expression. Those MUST NOT come from user input.
+type ErrUnsafeColumn struct {
+	Col string
+}
+
+func (e *ErrUnsafeColumn) Error() string {
+	return "Column " + strconv.Quote(e.Col) + " is not a safe identifier, quote it or tag its field raw"
+}
+
+// ErrDuplicateKey is returned when a statement breaks a unique constraint,
+// see [TranslateError].
+type ErrDuplicateKey struct {
+	// Column is the duplicated column, or columns comma separated, when
+	// the driver tells, and Constraint the broken constraint, or index.
+	Column, Constraint string
+
+	Err error // the driver error
+}
+
+func (e *ErrDuplicateKey) Error() string {
+	return "duplicate key" + errorColumn(e.Column) + ": " + e.Err.Error()
+}
+
+func (e *ErrDuplicateKey) Unwrap() error { return e.Err }
+
+// ErrForeignKey is returned when a statement breaks a foreign key
+// constraint, inserting a dangling reference or deleting a referenced row,
+// see [TranslateError].
+type ErrForeignKey struct {
+	// Column is the referencing column, or columns comma separated, when
+	// the driver tells, and Constraint the broken constraint.
+	Column, Constraint string
+
+	Err error // the driver error
+}
+
+func (e *ErrForeignKey) Error() string {
+	return "foreign key violation" + errorColumn(e.Column) + ": " + e.Err.Error()
+}
+
+func (e *ErrForeignKey) Unwrap() error { return e.Err }
+
+// ErrSerialization is returned when a statement fails on a serialization
+// failure, a deadlock or a lock timeout, see [TranslateError] and
+// [Retryable].
+type ErrSerialization struct {
+	Err error // the driver error
+}
+
+func (e *ErrSerialization) Error() string {
+	return "serialization failure: " + e.Err.Error()
+}
+
+func (e *ErrSerialization) Unwrap() error { return e.Err }
+
+func errorColumn(col string) string {
+	if col == "" {
+		return ""
+	}
+	return " on " + col
+}
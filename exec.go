@@ -0,0 +1,145 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+)
+
+// Queryer runs queries. It is implemented by *sql.DB, *sql.Tx and
+// *sql.Conn, so helpers taking one work the same in and out of
+// transactions:
+//
+//	func (r *UserRepo) Active(ctx context.Context, db mapper.Queryer) ([]User, error) {
+//	  return mapper.Query[User](ctx, users, db, users.SelectString("")+" WHERE active")
+//	}
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// QueryExecer runs queries and statements. It is implemented by *sql.DB,
+// *sql.Tx and *sql.Conn.
+type QueryExecer interface {
+	Queryer
+	Execer
+}
+
+// Execer runs statements. It is implemented by *sql.DB, *sql.Tx and
+// *sql.Conn.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Insert inserts rec into table, see [InsertString] and [InsertArgs]. Its
+// BeforeInsert hook runs first, see [BeforeInserter]. Like [Update] and
+// [Delete], it passes the tenant of ctx, see [WithTenantColumn].
+func (m *mapper) Insert(ctx context.Context, db Execer, table string, rec any) (sql.Result, error) {
+	if err := m.writable(); err != nil {
+		return nil, err
+	}
+	if err := beforeInsert(ctx, rec); err != nil {
+		return nil, err
+	}
+	if err := m.validate(rec); err != nil {
+		return nil, err
+	}
+	args, err := m.insertArgs(rec)
+	if err != nil {
+		return nil, err
+	}
+	if args, err = m.withTenant(ctx, args); err != nil {
+		return nil, err
+	}
+	return m.execTraced(ctx, db, m.tableName(table), m.InsertString(table), args)
+}
+
+// Update updates the row of rec in table, found by primary key, see
+// [UpdateString].
+func (m *mapper) Update(ctx context.Context, db Execer, table string, rec any) (sql.Result, error) {
+	if err := m.writable(); err != nil {
+		return nil, err
+	}
+	if err := m.validate(rec); err != nil {
+		return nil, err
+	}
+	query, err := m.updateString(table)
+	if err != nil {
+		return nil, err
+	}
+	args, err := m.updateArgs(rec)
+	if err != nil {
+		return nil, err
+	}
+	if args, err = m.withTenant(ctx, args); err != nil {
+		return nil, err
+	}
+	return m.execTraced(ctx, db, m.tableName(table), query, args)
+}
+
+// Delete deletes the row of rec in table, found by primary key, see
+// [DeleteString].
+func (m *mapper) Delete(ctx context.Context, db Execer, table string, rec any) (sql.Result, error) {
+	if err := m.writable(); err != nil {
+		return nil, err
+	}
+	query, err := m.deleteString(table)
+	if err != nil {
+		return nil, err
+	}
+	args, err := m.keyArgs(rec)
+	if err != nil {
+		return nil, err
+	}
+	if args, err = m.withTenant(ctx, args); err != nil {
+		return nil, err
+	}
+	return m.execTraced(ctx, db, m.tableName(table), query, args)
+}
+
+// Query runs query and scans every row into a T, running their AfterScan
+// hook, see [AfterScanner], and loads their related records, see [Eager].
+// Like [Insert], [Update] and [Delete], it is traced, see [WithTracer]:
+//
+//	us, err := Query[User](ctx, users, db, users.SelectString("users")+" WHERE karma > ?", 10)
+//
+// T must be the struct type m was built from.
+func Query[T any](ctx context.Context, m *mapper, db Queryer, query string, args ...any) (res []T, err error) {
+	ctx, end := m.startStatement(ctx, "", query, args)
+	defer func() {
+		end(int64(len(res)), err)
+	}()
+	rows, err := m.queryContext(ctx, db, query, args)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	defer rows.Close()
+	if m.eagerJoin {
+		recs, err := m.scanJoined(ctx, rows, false)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		for _, rec := range recs {
+			res = append(res, *rec.Interface().(*T))
+		}
+		return res, nil
+	}
+	for rows.Next() {
+		var rec T
+		if err := m.scan(rows, &rec); err != nil {
+			return nil, err
+		}
+		if err := afterScan(ctx, &rec); err != nil {
+			return nil, err
+		}
+		res = append(res, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+	if len(m.eager) > 0 {
+		if err := m.loadEager(ctx, db, reflect.ValueOf(res), m.eager); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
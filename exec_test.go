@@ -0,0 +1,194 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type hookedUser struct {
+	ID    int64 `mapper:"id,pk"`
+	Email string
+	Upper string `mapper:",ignore"`
+}
+
+func (u *hookedUser) BeforeInsert(ctx context.Context) error {
+	if u.Email == "" {
+		return errors.New("email required")
+	}
+	u.Email = strings.ToLower(u.Email)
+	return nil
+}
+
+func (u *hookedUser) AfterScan(ctx context.Context) error {
+	u.Upper = strings.ToUpper(u.Email)
+	return nil
+}
+
+func TestExec(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	conn := &fakeConnector{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	dut := Mapper(hookedUser{}, "*")
+
+	u := &hookedUser{ID: 1, Email: "A@B.C"}
+	_, err := dut.Insert(ctx, db, "users", u)
+	is.NoErr(err)
+	is.Equal(conn.lastCall(), fakeCall{"INSERT INTO users (id,email) VALUES (?,?)", []driver.Value{int64(1), "a@b.c"}})
+
+	_, err = dut.Insert(ctx, db, "users", &hookedUser{ID: 2})
+	is.Equal(err.Error(), "email required")
+	is.Equal(len(conn.calls), 1) // not run
+
+	_, err = dut.Update(ctx, db, "users", u)
+	is.NoErr(err)
+	is.Equal(conn.lastCall(), fakeCall{"UPDATE users SET email=? WHERE id=?", []driver.Value{"a@b.c", int64(1)}})
+
+	_, err = dut.Delete(ctx, db, "users", u)
+	is.NoErr(err)
+	is.Equal(conn.lastCall(), fakeCall{"DELETE FROM users WHERE id=?", []driver.Value{int64(1)}})
+}
+
+func TestExecErrors(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	conn := &fakeConnector{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	keyless := Mapper(scanRecord{}, "*")
+	_, err := keyless.Update(ctx, db, "records", scanRecord{1, "a"})
+	is.Equal(err, ErrNoPrimaryKey)
+	_, err = keyless.Delete(ctx, db, "records", scanRecord{1, "a"})
+	is.Equal(err, ErrNoPrimaryKey)
+
+	dut := Mapper(hookedUser{}, "*")
+	_, err = dut.Update(ctx, db, "users", &scanRecord{1, "a"})
+	is.True(err != nil) // not a user
+	_, err = dut.Delete(ctx, db, "users", &scanRecord{1, "a"})
+	is.True(err != nil)
+	is.Equal(len(conn.calls), 0)
+}
+
+func TestQueryAfterScan(t *testing.T) {
+	is := is.New(t)
+	conn := &fakeConnector{
+		cols: []string{"id", "email"},
+		rows: [][]driver.Value{{int64(1), "a@b.c"}, {int64(2), "d@e.f"}},
+	}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	dut := Mapper(hookedUser{}, "*")
+
+	us, err := Query[hookedUser](context.Background(), dut, db, dut.SelectString("users")+" WHERE id > ?", 0)
+	is.NoErr(err)
+	is.Equal(us, []hookedUser{{1, "a@b.c", "A@B.C"}, {2, "d@e.f", "D@E.F"}})
+	is.Equal(conn.lastCall(), fakeCall{"SELECT id,email FROM users WHERE id > ?", []driver.Value{int64(0)}})
+
+	u, err := ScanRow[hookedUser](dut, db.QueryRow("SELECT"))
+	is.NoErr(err)
+	is.Equal(u.Upper, "A@B.C")
+}
+
+type recordingTracer struct {
+	stmts []TracedStatement
+	rows  []int64
+}
+
+func (r *recordingTracer) StartStatement(ctx context.Context, s TracedStatement) (context.Context, func(int64, error)) {
+	r.stmts = append(r.stmts, s)
+	return ctx, func(rows int64, err error) {
+		r.rows = append(r.rows, rows)
+	}
+}
+
+func TestExecTracer(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	db := sql.OpenDB(&fakeConnector{})
+	defer db.Close()
+	tr := &recordingTracer{}
+	dut := Mapper(buildUser{}, "*").SetOptions(WithTracer(tr))
+
+	_, err := dut.Update(ctx, db, "", &buildUser{1, "n", "e"})
+	is.NoErr(err)
+	is.Equal(tr.stmts[0].Operation, "UPDATE")
+	is.Equal(tr.stmts[0].Table, "build_users")
+	is.Equal(tr.stmts[0].Target, reflect.TypeFor[buildUser]())
+	is.Equal(tr.rows, []int64{1})
+}
+
+func TestScanTracer(t *testing.T) {
+	is := is.New(t)
+	db := sql.OpenDB(&fakeConnector{answer: func(string, []driver.Value) ([]string, [][]driver.Value) {
+		return []string{"id", "name", "email"}, [][]driver.Value{{int64(1), "n", "e"}}
+	}})
+	defer db.Close()
+	tr := &recordingTracer{}
+	dut := Mapper(buildUser{}, "*").SetOptions(WithTracer(tr))
+
+	_, err := Query[buildUser](context.Background(), dut, db, "SELECT id,name,email FROM build_users")
+	is.NoErr(err)
+	is.Equal(tr.stmts[0].Operation, "SELECT")
+	is.Equal(tr.stmts[0].Table, "build_users")
+
+	rows := queryFake(t, []string{"id", "name", "email"},
+		[]driver.Value{int64(1), "n", "e"},
+		[]driver.Value{int64(2), "m", "f"},
+	)
+	is.NoErr(ForEach(dut, rows, func(*buildUser) error { return nil }))
+	is.Equal(tr.stmts[1], TracedStatement{Operation: "SCAN", Table: "build_users", Target: reflect.TypeFor[buildUser]()})
+	is.Equal(tr.rows[1], int64(2))
+
+	rows = queryFake(t, []string{"id", "name", "email"}, []driver.Value{int64(1), "n", "e"})
+	is.True(rows.Next())
+	_, err = ScanRow[buildUser](dut, rows)
+	is.NoErr(err)
+	is.Equal(tr.stmts[2].Operation, "SCAN")
+	is.Equal(tr.rows[2], int64(1))
+
+	recs, errc := Stream[buildUser](context.Background(), dut, queryFake(t, []string{"id", "name", "email"}, []driver.Value{int64(1), "n", "e"}))
+	for range recs {
+	}
+	is.NoErr(<-errc)
+	is.Equal(tr.stmts[3].Operation, "SCAN")
+	is.Equal(tr.rows[3], int64(1))
+}
+
+func TestExecLogger(t *testing.T) {
+	is := is.New(t)
+	db := sql.OpenDB(&fakeConnector{})
+	defer db.Close()
+	var logged []string
+	dut := Mapper(buildUser{}, "*").SetOptions(WithLogger(func(ctx context.Context, query string, args []any, err error, dur time.Duration) {
+		is.NoErr(err)
+		is.True(dur >= 0)
+		logged = append(logged, fmt.Sprint(query, args))
+	}))
+
+	_, err := dut.Delete(context.Background(), db, "users", buildUser{ID: 3})
+	is.NoErr(err)
+	is.Equal(logged, []string{"DELETE FROM users WHERE id=?[3]"})
+
+	is.NoErr(ForEach(dut, queryFake(t, []string{"id", "name", "email"}), func(*buildUser) error { return nil }))
+	is.Equal(len(logged), 1) // scans are not logged
+}
+
+var (
+	_ Queryer = (*sql.DB)(nil)
+	_ Queryer = (*sql.Tx)(nil)
+	_ Queryer = (*sql.Conn)(nil)
+	_ Execer  = (*sql.DB)(nil)
+	_ Execer  = (*sql.Tx)(nil)
+	_ Execer  = (*sql.Conn)(nil)
+)
@@ -0,0 +1,63 @@
+package mapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isExpr tells whether column j is an expression.
+func (m *mapper) isExpr(j int) bool {
+	return m.fields[j].opts.Has("expr")
+}
+
+// hasExpr tells whether some column is an expression.
+func (m *mapper) hasExpr() bool {
+	for j := range m.fields {
+		if m.isExpr(j) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectList returns the columns of SELECT statements, prefixed with
+// prefix, expressions aliased and casts applied, see [checkCast].
+//
+// It panics on expressions when prefix is set, as their columns cannot be
+// qualified with it.
+func (m *mapper) selectList(prefix string) string {
+	if !m.hasExpr() && !m.hasCast() {
+		return m.ColumnsStringPrefix(prefix)
+	}
+	s := make([]string, len(m.cols))
+	for j, col := range m.cols {
+		switch {
+		case m.isExpr(j) && prefix != "":
+			panic(fmt.Errorf("%s: expression columns cannot be joined", col))
+		case m.isExpr(j):
+			s[j] = m.castExpr(j, m.fields[j].opts.Get("expr")) + " AS " + col
+		case m.fields[j].opts.Has("cast") && m.Dialect == Postgres:
+			s[j] = m.castExpr(j, prefix+col)
+		case m.fields[j].opts.Has("cast"):
+			s[j] = m.castExpr(j, prefix+col) + " AS " + col
+		default:
+			s[j] = prefix + col
+		}
+	}
+	return strings.Join(s, m.sep())
+}
+
+// writableColumns returns the columns of INSERT statements, expressions
+// and generated columns left out.
+func (m *mapper) writableColumns() string {
+	var s []string
+	for j, col := range m.cols {
+		if !m.readOnlyColumn(j) {
+			s = append(s, col)
+		}
+	}
+	if len(s) == len(m.cols) {
+		return m.ColumnsString()
+	}
+	return strings.Join(s, m.sep())
+}
@@ -0,0 +1,161 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeConnector is a database/sql connector answering every query with the
+// same canned result set.
+type fakeConnector struct {
+	cols  []string
+	types []string // database type names of cols, optional
+	rows  [][]driver.Value
+
+	// answer, when set, gives the columns and rows of each query instead.
+	answer func(query string, args []driver.Value) ([]string, [][]driver.Value)
+
+	// execErr, when set, fails the statements it returns an error for.
+	execErr func(query string, args []driver.Value) error
+
+	// affected is the count of rows affected by each statement, 1 when
+	// zero.
+	affected int64
+
+	prepared atomic.Int32 // statements prepared so far
+
+	mu         sync.Mutex
+	calls      []fakeCall // statements run so far, BEGIN, COMMIT and ROLLBACK included
+	commitErrs []error    // returned by the next commits, in turn
+}
+
+// fakeCall is a statement run on a fakeConnector.
+type fakeCall struct {
+	query string
+	args  []driver.Value
+}
+
+func (c *fakeConnector) record(query string, args []driver.Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, fakeCall{query, args})
+}
+
+// lastCall returns the last statement run.
+func (c *fakeConnector) lastCall() fakeCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.calls) == 0 {
+		return fakeCall{}
+	}
+	return c.calls[len(c.calls)-1]
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{c}, nil }
+func (c *fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use a connector") }
+
+type fakeConn struct{ c *fakeConnector }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.c.prepared.Add(1)
+	return &fakeStmt{c.c, query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.c.record("BEGIN", nil)
+	return fakeTx{c.c}, nil
+}
+
+type fakeTx struct{ c *fakeConnector }
+
+func (tx fakeTx) Commit() error {
+	tx.c.record("COMMIT", nil)
+	tx.c.mu.Lock()
+	defer tx.c.mu.Unlock()
+	if len(tx.c.commitErrs) == 0 {
+		return nil
+	}
+	err := tx.c.commitErrs[0]
+	tx.c.commitErrs = tx.c.commitErrs[1:]
+	return err
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.c.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeStmt struct {
+	c     *fakeConnector
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.record(s.query, args)
+	if s.c.execErr != nil {
+		if err := s.c.execErr(s.query, args); err != nil {
+			return nil, err
+		}
+	}
+	if s.c.affected != 0 {
+		return driver.RowsAffected(s.c.affected), nil
+	}
+	return driver.RowsAffected(1), nil
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.record(s.query, args)
+	if s.c.answer != nil {
+		cols, rows := s.c.answer(s.query, args)
+		return &fakeRows{cols: cols, rows: rows}, nil
+	}
+	return &fakeRows{cols: s.c.cols, types: s.c.types, rows: s.c.rows}, nil
+}
+
+type fakeRows struct {
+	cols  []string
+	types []string
+	rows  [][]driver.Value
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(i int) string {
+	if r.types == nil {
+		return ""
+	}
+	return r.types[i]
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// queryFake returns rows holding cols and values.
+func queryFake(t *testing.T, cols []string, values ...[]driver.Value) *sql.Rows {
+	t.Helper()
+	db := sql.OpenDB(&fakeConnector{cols: cols, rows: values})
+	t.Cleanup(func() { db.Close() })
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
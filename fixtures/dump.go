@@ -0,0 +1,127 @@
+package fixtures
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/dav-m85/mapper"
+	"gopkg.in/yaml.v3"
+)
+
+// Dump writes the rows of table matching where, read through m, to w as a
+// YAML fixture file which [Load] reads back, columns in mapper order:
+//
+//	err := fixtures.Dump(ctx, db, users, "users", "org_id = $1", f, orgID)
+//
+// Values are written as m sends them to the database, so JSON, uuid or
+// encrypted fields stay in their stored form, and anonymized, see
+// [mapper.WithAnonymizer]. Where is a condition without
+// the WHERE keyword, left out when empty, taking args.
+//
+// With [mapper.WithTenantColumn], only the rows of the tenant of ctx are
+// written. The tenant is then the first argument of the query, so where
+// placeholders start at $2 with Postgres.
+func Dump(ctx context.Context, db mapper.Queryer, m Mapper, table, where string, w io.Writer, args ...any) error {
+	var rows []*yaml.Node
+	err := dump(ctx, db, m, table, where, args, func(cols []string, vals []any) error {
+		row := &yaml.Node{Kind: yaml.MappingNode}
+		for i, col := range cols {
+			var v yaml.Node
+			if err := v.Encode(vals[i]); err != nil {
+				return fmt.Errorf("%s: %w", col, err)
+			}
+			row.Content = append(row.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: col}, &v)
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.SequenceNode, Content: rows}); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// DumpJSON is like [Dump] but writes JSON, one row a line. Binary values
+// cannot be written.
+func DumpJSON(ctx context.Context, db mapper.Queryer, m Mapper, table, where string, w io.Writer, args ...any) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	sep := "\n"
+	err := dump(ctx, db, m, table, where, args, func(cols []string, vals []any) error {
+		line := []byte(sep + "  {")
+		sep = ",\n"
+		for i, col := range cols {
+			if b, ok := vals[i].([]byte); ok {
+				if !utf8.Valid(b) {
+					return fmt.Errorf("%s: binary values cannot be written as JSON, dump as YAML", col)
+				}
+				vals[i] = string(b)
+			}
+			k, _ := json.Marshal(col)
+			v, err := json.Marshal(vals[i])
+			if err != nil {
+				return fmt.Errorf("%s: %w", col, err)
+			}
+			if i > 0 {
+				line = append(line, ", "...)
+			}
+			line = append(append(append(line, k...), ": "...), v...)
+		}
+		_, err := w.Write(append(line, '}'))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n]\n")
+	return err
+}
+
+// dump calls row with the columns and values of each row of table
+// matching where.
+func dump(ctx context.Context, db mapper.Queryer, m Mapper, table, where string, args []any, row func(cols []string, vals []any) error) error {
+	query := m.SelectString(table)
+	switch {
+	case where != "" && m.TenantColumn() != "":
+		query += " AND (" + where + ")" // SelectString has the tenant WHERE clause
+	case where != "":
+		query += " WHERE " + where
+	}
+	args, err := m.TenantArgs(ctx, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	cols := m.Columns()
+	for rows.Next() {
+		rec := reflect.New(m.Type()).Interface()
+		if err := rows.Scan(m.Addrs(rec)...); err != nil {
+			return err
+		}
+		vals := m.ExportValues(rec)
+		for i, v := range vals {
+			if vals[i], err = driver.DefaultParameterConverter.ConvertValue(v); err != nil {
+				return fmt.Errorf("%s: %w", cols[i], err)
+			}
+		}
+		if err := row(cols, vals); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
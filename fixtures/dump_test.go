@@ -0,0 +1,122 @@
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/dav-m85/mapper"
+	"github.com/dav-m85/mapper/mappertest"
+	"github.com/matryer/is"
+)
+
+// rowsConnector is a database answering every query with rows.
+type rowsConnector struct {
+	cols []string
+	rows [][]driver.Value
+
+	query string // last one
+	args  []driver.Value
+}
+
+func (c *rowsConnector) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *rowsConnector) Driver() driver.Driver                        { return nil }
+func (c *rowsConnector) Prepare(q string) (driver.Stmt, error)        { c.query = q; return c, nil }
+func (c *rowsConnector) Close() error                                 { return nil }
+func (c *rowsConnector) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+func (c *rowsConnector) NumInput() int                                { return -1 }
+func (c *rowsConnector) Exec([]driver.Value) (driver.Result, error)   { return nil, driver.ErrSkip }
+func (c *rowsConnector) Query(args []driver.Value) (driver.Rows, error) {
+	c.args = args
+	return &fakeRows{c.cols, c.rows}, nil
+}
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestDump(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	db := sql.OpenDB(&rowsConnector{
+		cols: []string{"id", "org_id", "name", "born"},
+		rows: [][]driver.Value{
+			{int64(1), int64(10), "alice", time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)},
+			{int64(2), int64(10), []byte("bob"), time.Time{}},
+		},
+	})
+	defer db.Close()
+	users := mapper.Mapper(user{}, "*")
+
+	var b strings.Builder
+	is.NoErr(Dump(ctx, db, users, "users", "org_id = ?", &b, 10))
+	is.Equal(b.String(), `- id: 1
+  org_id: 10
+  name: alice
+  born: 2000-01-02T00:00:00Z
+- id: 2
+  org_id: 10
+  name: bob
+  born: 0001-01-01T00:00:00Z
+`)
+
+	// dumps load back
+	rec := mappertest.NewRecorder()
+	defer rec.Close()
+	fsys := fstest.MapFS{"users.yml": {Data: []byte(b.String())}}
+	is.NoErr(Load(ctx, rec, fsys, WithMapper("users", users)))
+	is.Equal(rec.Statements()[0].Args, []any{int64(1), int64(10), "alice", time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC), int64(2), int64(10), "bob", time.Time{}})
+
+	b.Reset()
+	is.NoErr(DumpJSON(ctx, db, users, "users", "", &b))
+	is.Equal(b.String(), `[
+  {"id": 1, "org_id": 10, "name": "alice", "born": "2000-01-02T00:00:00Z"},
+  {"id": 2, "org_id": 10, "name": "bob", "born": "0001-01-01T00:00:00Z"}
+]
+`)
+	rec.Reset()
+	is.NoErr(Load(ctx, rec, fstest.MapFS{"users.json": {Data: []byte(b.String())}}, WithMapper("users", users)))
+	is.Equal(len(rec.Statements()), 1)
+}
+
+type orgKey struct{}
+
+func TestDumpTenant(t *testing.T) {
+	is := is.New(t)
+	conn := &rowsConnector{cols: []string{"id", "name"}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	type member struct {
+		ID   int64  `mapper:"id"`
+		Name string `mapper:"name"`
+	}
+	members := mapper.Mapper(member{}, "*").With(mapper.WithDialect(mapper.Postgres), mapper.WithTenantColumn("org_id", func(ctx context.Context) any {
+		return ctx.Value(orgKey{})
+	}))
+	ctx := context.WithValue(context.Background(), orgKey{}, int64(10))
+
+	var b strings.Builder
+	is.NoErr(Dump(ctx, db, members, "members", "name = $2", &b, "bob"))
+	is.Equal(conn.query, "SELECT id,name FROM members WHERE org_id=$1 AND (name = $2)")
+	is.Equal(conn.args, []driver.Value{int64(10), "bob"})
+	is.NoErr(Dump(ctx, db, members, "members", "", &b))
+	is.Equal(conn.query, "SELECT id,name FROM members WHERE org_id=$1")
+	is.Equal(Dump(context.Background(), db, members, "members", "", &b), mapper.ErrNoTenant)
+}
@@ -0,0 +1,189 @@
+// Package fixtures loads YAML and JSON fixture files into a database
+// through mappers, in place of hand written seed SQL in integration tests.
+// It lives in its own module to keep mapper free of dependencies.
+//
+// Each file holds the rows of the table it is named after, users.yml
+// those of users, as a list of column values:
+//
+//	# users.yml
+//	- id: 1
+//	  name: alice
+//	  born: 2000-01-02T00:00:00Z
+//	- id: 2
+//	  name: bob
+//
+// Rows are inserted with the mapper registered under the table name, see
+// [mapper.Register], whose conversions apply:
+//
+//	err := fixtures.Load(ctx, db, os.DirFS("testdata/fixtures"), fixtures.Order("orgs", "users"))
+//
+// [Dump] writes such files from the rows of a database, to snapshot
+// production like data for tests.
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/dav-m85/mapper"
+	"gopkg.in/yaml.v3"
+)
+
+// Mapper is the part of a mapper loading and dumping fixtures, see
+// [mapper.Mapper].
+type Mapper interface {
+	Type() reflect.Type
+	ScanMap(vals map[string]any, dest any) error
+	InsertBatch(ctx context.Context, db mapper.Execer, table string, recs any, opts mapper.BatchOptions) (int64, error)
+
+	Columns() []string
+	SelectString(table string) string
+	TenantColumn() string
+	TenantArgs(ctx context.Context, args ...any) ([]any, error)
+	Addrs(dest any) []any
+	ExportValues(dest any) []any
+}
+
+// Option configures [Load].
+type Option func(*loader)
+
+// Order loads tables first, in order, so that rows referenced by foreign
+// keys exist before those referencing them. Other tables follow in name
+// order.
+func Order(tables ...string) Option {
+	return func(l *loader) {
+		l.order = append(l.order, tables...)
+	}
+}
+
+// WithMapper loads the rows of table with m rather than the mapper
+// registered under the table name.
+func WithMapper(table string, m Mapper) Option {
+	return func(l *loader) {
+		l.mappers[table] = m
+	}
+}
+
+// WithBatchOptions sets how rows are batched, see [mapper.BatchOptions].
+func WithBatchOptions(opts mapper.BatchOptions) Option {
+	return func(l *loader) {
+		l.batch = opts
+	}
+}
+
+type loader struct {
+	order   []string
+	mappers map[string]Mapper
+	batch   mapper.BatchOptions
+}
+
+// Load inserts the rows of the .yml, .yaml and .json files at the root of
+// fsys into db, as os.DirFS(dir) or an embed.FS, one table a file. It
+// stops at the first error, prefixed with the file name.
+func Load(ctx context.Context, db mapper.Execer, fsys fs.FS, opts ...Option) error {
+	l := &loader{mappers: make(map[string]Mapper)}
+	for _, opt := range opts {
+		opt(l)
+	}
+	files := make(map[string]string) // by table
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for _, e := range entries {
+		ext := path.Ext(e.Name())
+		if e.IsDir() || ext != ".yml" && ext != ".yaml" && ext != ".json" {
+			continue
+		}
+		table := strings.TrimSuffix(e.Name(), ext)
+		if other, ok := files[table]; ok {
+			return fmt.Errorf("fixtures: %s and %s hold the same table", other, e.Name())
+		}
+		files[table] = e.Name()
+		tables = append(tables, table)
+	}
+	slices.SortStableFunc(tables, func(a, b string) int {
+		return rank(l.order, a) - rank(l.order, b)
+	})
+	for _, table := range tables {
+		if err := l.load(ctx, db, fsys, table, files[table]); err != nil {
+			return fmt.Errorf("%s: %w", files[table], err)
+		}
+	}
+	return nil
+}
+
+// rank returns the position of table in order, or len(order) when absent.
+func rank(order []string, table string) int {
+	if i := slices.Index(order, table); i >= 0 {
+		return i
+	}
+	return len(order)
+}
+
+// load inserts the rows of file into table.
+func (l *loader) load(ctx context.Context, db mapper.Execer, fsys fs.FS, table, file string) error {
+	m, ok := l.mappers[table]
+	if !ok {
+		if m, ok = mapper.Lookup(table); !ok {
+			return fmt.Errorf("no mapper registered for table %s", table)
+		}
+	}
+	b, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return err
+	}
+	rows, err := decode(b, path.Ext(file) == ".json")
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	recs := reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(m.Type())), len(rows), len(rows))
+	for i, row := range rows {
+		rec := reflect.New(m.Type())
+		if err := m.ScanMap(row, rec.Interface()); err != nil {
+			return fmt.Errorf("row %d: %w", i+1, err)
+		}
+		recs.Index(i).Set(rec)
+	}
+	_, err = m.InsertBatch(ctx, db, table, recs.Interface(), l.batch)
+	return err
+}
+
+// decode returns the rows of a fixture file, JSON when isJSON, YAML
+// otherwise. JSON numbers are kept exact.
+func decode(b []byte, isJSON bool) ([]map[string]any, error) {
+	var rows []map[string]any
+	if !isJSON {
+		return rows, yaml.Unmarshal(b, &rows)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&rows); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for col, v := range row {
+			n, ok := v.(json.Number)
+			if !ok {
+				continue
+			}
+			if i, err := n.Int64(); err == nil {
+				row[col] = i
+			} else {
+				row[col], _ = n.Float64()
+			}
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,46 @@
+package fixtures
+
+import (
+	"context"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/dav-m85/mapper"
+	"github.com/dav-m85/mapper/mappertest"
+	"github.com/matryer/is"
+)
+
+type org struct {
+	ID   int64  `mapper:"id,pk"`
+	Name string `mapper:"name"`
+}
+
+type user struct {
+	ID    int64     `mapper:"id,pk"`
+	OrgID int64     `mapper:"org_id"`
+	Name  string    `mapper:"name"`
+	Born  time.Time `mapper:"born"`
+}
+
+func init() {
+	mapper.Register("orgs", mapper.Mapper(org{}, "*"))
+}
+
+func TestLoad(t *testing.T) {
+	is := is.New(t)
+	rec := mappertest.NewRecorder()
+	defer rec.Close()
+	err := Load(context.Background(), rec, os.DirFS("testdata"), Order("orgs"), WithMapper("users", mapper.Mapper(user{}, "*")))
+	is.NoErr(err)
+	is.Equal(rec.String(), `INSERT INTO orgs (id,name) VALUES (?,?) -- 10, "acme"
+INSERT INTO users (id,org_id,name,born) VALUES (?,?,?,?),(?,?,?,?) -- 1, 10, "alice", 2000-01-02T00:00:00Z, 2, 10, "bob", 0001-01-01T00:00:00Z
+`)
+
+	err = Load(context.Background(), rec, os.DirFS("testdata"))
+	is.True(err != nil) // no users mapper
+
+	err = Load(context.Background(), rec, fstest.MapFS{"orgs.yml": {Data: []byte("- id: x\n")}})
+	is.Equal(err.Error(), `orgs.yml: row 1: id: strconv.ParseInt: parsing "x": invalid syntax`)
+}
@@ -0,0 +1,11 @@
+module github.com/dav-m85/mapper/fixtures
+
+go 1.24.6
+
+require (
+	github.com/dav-m85/mapper v0.0.0
+	github.com/matryer/is v1.4.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/dav-m85/mapper => ../
@@ -0,0 +1,183 @@
+package mapper
+
+import (
+	"fmt"
+	"math/bits"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// flagSet holds the masks of the flags of a type, see [RegisterFlags].
+type flagSet struct {
+	masks map[any]int64
+	flags []flagBit // by increasing mask
+}
+
+type flagBit struct {
+	mask int64
+	flag reflect.Value
+}
+
+var (
+	flagsMu  sync.Mutex
+	flagSets atomic.Pointer[map[reflect.Type]*flagSet] // replaced on registration
+)
+
+// RegisterFlags declares the bit of each flag of type T, so fields of type
+// []T, or map[T]bool sets, tagged bitmask are stored as an integer with the
+// bits of their flags:
+//
+//	type Perm string
+//
+//	func init() {
+//	  mapper.RegisterFlags(map[Perm]int64{"read": 1, "write": 2, "admin": 4})
+//	}
+//
+//	type Role struct {
+//	  Name  string `mapper:"name,pk"`
+//	  Perms []Perm `mapper:"perms,bitmask"`
+//	}
+//
+// [Values] then gives 3 for []Perm{"read", "write"}, and scans turn 5 into
+// []Perm{"read", "admin"}, flags by increasing bit. Flags missing from the
+// registration, and unknown bits, fail the statement or the scan. Nil
+// fields are NULL, and NULL scans as nil.
+//
+// It panics unless each mask is a distinct single bit. Registering T again
+// replaces its flags for mappers built afterwards.
+func RegisterFlags[T comparable](masks map[T]int64) {
+	fs := &flagSet{masks: make(map[any]int64, len(masks))}
+	for flag, mask := range masks {
+		if bits.OnesCount64(uint64(mask)) != 1 {
+			panic(fmt.Errorf("RegisterFlags: mask %#x of %v is not a single bit", mask, flag))
+		}
+		fs.masks[flag] = mask
+		fs.flags = append(fs.flags, flagBit{mask, reflect.ValueOf(flag)})
+	}
+	slices.SortFunc(fs.flags, func(a, b flagBit) int {
+		return bits.TrailingZeros64(uint64(a.mask)) - bits.TrailingZeros64(uint64(b.mask))
+	})
+	for i := 1; i < len(fs.flags); i++ {
+		if fs.flags[i].mask == fs.flags[i-1].mask {
+			panic(fmt.Errorf("RegisterFlags: %v and %v share mask %#x", fs.flags[i-1].flag, fs.flags[i].flag, fs.flags[i].mask))
+		}
+	}
+	flagsMu.Lock()
+	defer flagsMu.Unlock()
+	reg := make(map[reflect.Type]*flagSet)
+	if p := flagSets.Load(); p != nil {
+		for t, fs := range *p {
+			reg[t] = fs
+		}
+	}
+	reg[reflect.TypeFor[T]()] = fs
+	flagSets.Store(&reg)
+}
+
+// checkBitmask returns the flags of a field of type t tagged bitmask, or an
+// error when t is neither a slice nor a set of registered flags.
+func checkBitmask(t reflect.Type, opts TagOptions) (*flagSet, error) {
+	if !opts.Has("bitmask") {
+		return nil, nil
+	}
+	var flag reflect.Type
+	switch {
+	case t.Kind() == reflect.Slice:
+		flag = t.Elem()
+	case t.Kind() == reflect.Map && t.Elem().Kind() == reflect.Bool:
+		flag = t.Key()
+	default:
+		return nil, fmt.Errorf("field of type %s cannot be a bitmask", t)
+	}
+	var fs *flagSet
+	if p := flagSets.Load(); p != nil {
+		fs = (*p)[flag]
+	}
+	if fs == nil {
+		return nil, fmt.Errorf("no flags registered for %s, see RegisterFlags", flag)
+	}
+	return fs, nil
+}
+
+// value returns the bitmask of v, a slice or set of flags.
+func (fs *flagSet) value(v any) any {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return nil // NULL
+	}
+	var n int64
+	add := func(flag reflect.Value) error {
+		mask, ok := fs.masks[flag.Interface()]
+		if !ok {
+			return fmt.Errorf("%v is not a registered %s flag", flag, flag.Type())
+		}
+		n |= mask
+		return nil
+	}
+	if rv.Kind() == reflect.Slice {
+		for i := range rv.Len() {
+			if err := add(rv.Index(i)); err != nil {
+				return failingValue{err}
+			}
+		}
+		return n
+	}
+	for it := rv.MapRange(); it.Next(); {
+		if !it.Value().Bool() {
+			continue
+		}
+		if err := add(it.Key()); err != nil {
+			return failingValue{err}
+		}
+	}
+	return n
+}
+
+// flagsScanner scans bitmasks into the slice or set of flags pointed to by
+// dest.
+type flagsScanner struct {
+	fs   *flagSet
+	dest reflect.Value
+}
+
+func (s flagsScanner) Scan(src any) error {
+	f := s.dest.Elem()
+	var n int64
+	switch v := src.(type) {
+	case nil:
+		f.SetZero()
+		return nil
+	case int64:
+		n = v
+	case []byte, string:
+		var err error
+		if n, err = strconv.ParseInt(fmt.Sprintf("%s", v), 10, 64); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot scan a %T into a bitmask", src)
+	}
+	if f.Kind() == reflect.Slice {
+		f.Set(reflect.MakeSlice(f.Type(), 0, bits.OnesCount64(uint64(n))))
+	} else {
+		f.Set(reflect.MakeMap(f.Type()))
+	}
+	for _, b := range s.fs.flags {
+		if n&b.mask == 0 {
+			continue
+		}
+		n &^= b.mask
+		if f.Kind() == reflect.Slice {
+			f.Set(reflect.Append(f, b.flag))
+		} else {
+			f.SetMapIndex(b.flag, reflect.ValueOf(true))
+		}
+	}
+	if n != 0 {
+		return fmt.Errorf("unknown flag bits %#x", n)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type testPerm string
+
+func TestFlags(t *testing.T) {
+	is := is.New(t)
+	RegisterFlags(map[testPerm]int64{"read": 1, "write": 2, "admin": 4})
+	type Role struct {
+		Name  string            `mapper:"name,pk"`
+		Perms []testPerm        `mapper:"perms,bitmask"`
+		Extra map[testPerm]bool `mapper:"extra,bitmask"`
+	}
+	dut := Mapper(Role{}, "*")
+	vals := dut.Values(Role{"ops", []testPerm{"admin", "read"}, map[testPerm]bool{"write": true, "admin": false}})
+	is.Equal(vals[1], int64(5))
+	is.Equal(vals[2], int64(2))
+	is.Equal(dut.Values(Role{})[1], nil) // NULL
+
+	_, err := dut.Values(Role{Perms: []testPerm{"root"}})[1].(driver.Valuer).Value()
+	is.True(err != nil)
+
+	rows := queryFake(t, []string{"name", "perms", "extra"},
+		[]driver.Value{"ops", int64(6), []byte("1")},
+		[]driver.Value{"bad", int64(8), nil},
+	)
+	is.True(rows.Next())
+	r, err := ScanRow[Role](dut, rows)
+	is.NoErr(err)
+	is.Equal(r.Perms, []testPerm{"write", "admin"})
+	is.Equal(r.Extra, map[testPerm]bool{"read": true})
+	is.True(rows.Next())
+	_, err = ScanRow[Role](dut, rows)
+	is.True(err != nil) // unknown bit
+
+	type Unregistered struct {
+		Flags []int `mapper:"flags,bitmask"`
+	}
+	_, err = MapperE(Unregistered{}, "*")
+	is.True(err != nil)
+}
@@ -0,0 +1,12 @@
+package mapper
+
+// isGenerated tells whether column j is generated by the database.
+func (m *mapper) isGenerated(j int) bool {
+	return m.fields[j].opts.Has("generated")
+}
+
+// readOnlyColumn tells whether column j is left out of writes, being an
+// expression or generated.
+func (m *mapper) readOnlyColumn(j int) bool {
+	return m.isExpr(j) || m.isGenerated(j)
+}
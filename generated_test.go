@@ -0,0 +1,86 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGenerated(t *testing.T) {
+	is := is.New(t)
+	type Item struct {
+		ID    int64   `mapper:"id,pk"`
+		Price float64 `mapper:"price"`
+		Qty   int64   `mapper:"qty"`
+		Total float64 `mapper:"total,generated=price*qty"`
+	}
+	dut := Mapper(Item{}, "*").With(WithDialect(Postgres))
+	it := Item{ID: 1, Price: 2.5, Qty: 4, Total: 10}
+
+	is.Equal(dut.SelectString("items"), "SELECT id,price,qty,total FROM items")
+	is.Equal(dut.InsertString("items"), "INSERT INTO items (id,price,qty) VALUES ($1,$2,$3)")
+	is.Equal(dut.InsertArgs(&it), []any{int64(1), 2.5, int64(4)})
+	is.Equal(dut.UpdateString("items"), "UPDATE items SET price=$1,qty=$2 WHERE id=$3")
+	is.Equal(dut.UpdateArgs(&it), []any{2.5, int64(4), int64(1)})
+	is.Equal(dut.NamedMarks(), ":id,:price,:qty")
+	is.Equal(dut.NamedSetString(), "id=:id,price=:price,qty=:qty")
+	is.Equal(dut.ValuesMap(it), map[string]any{"id": int64(1), "price": 2.5, "qty": int64(4)})
+	is.Equal(dut.SquirrelSetMap(&it), map[string]any{"id": int64(1), "price": 2.5, "qty": int64(4)})
+	is.Equal(dut.UpsertString("items"), "INSERT INTO items (id,price,qty) VALUES ($1,$2,$3) ON CONFLICT (id) DO UPDATE SET price=excluded.price,qty=excluded.qty")
+	is.Equal(dut.CreateTableString("items"), "CREATE TABLE items (\n  id bigint,\n  price double precision,\n  qty bigint,\n  total double precision GENERATED ALWAYS AS (price*qty) STORED,\n  PRIMARY KEY (id)\n)")
+}
+
+func TestValidateSchemaGenerated(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		ID    int64   `mapper:"id,pk"`
+		Total float64 `mapper:"total"`
+		Slug  string  `mapper:"slug,generated"`
+	}
+	db := sql.OpenDB(&fakeConnector{
+		cols: []string{"column_name", "data_type", "nullable", "generated"},
+		rows: [][]driver.Value{
+			{"id", "bigint", false, "NEVER"},
+			{"total", "double precision", false, "ALWAYS"},
+			{"slug", "text", false, "NEVER"},
+		},
+	})
+	defer db.Close()
+
+	r, err := Mapper(M{}, "*").With(WithDialect(Postgres)).ValidateSchema(context.Background(), db, "m")
+	is.NoErr(err)
+	is.Equal(r.Generated, []string{"total"})
+	is.Equal(r.NotGenerated, []string{"slug"})
+	is.Equal(r.Err().Error(), "table m does not match mapper: generated columns total not tagged generated; columns slug tagged generated are not")
+}
+
+func TestTableColumnsGenerated(t *testing.T) {
+	is := is.New(t)
+	db := sql.OpenDB(&fakeConnector{
+		cols: []string{"column_name", "column_type", "nullable", "extra"},
+		rows: [][]driver.Value{
+			{"total", "double", false, "STORED GENERATED"},
+			{"slug", "varchar(20)", true, "VIRTUAL GENERATED"},
+			{"created_at", "timestamp", false, "DEFAULT_GENERATED on update CURRENT_TIMESTAMP"},
+			{"id", "bigint", false, "auto_increment"},
+		},
+	})
+	defer db.Close()
+
+	cols, err := TableColumns(context.Background(), db, MySQL, "m")
+	is.NoErr(err)
+	var generated []string
+	for _, c := range cols {
+		if c.Generated {
+			generated = append(generated, c.Name)
+		}
+	}
+	is.Equal(generated, []string{"total", "slug"})
+
+	is.True(generatedColumn(SQLite, "3"))
+	is.True(!generatedColumn(SQLite, "0"))
+	is.True(!generatedColumn(Postgres, "NEVER"))
+}
@@ -0,0 +1,11 @@
+module github.com/dav-m85/mapper/goqumapper
+
+go 1.24.6
+
+require (
+	github.com/dav-m85/mapper v0.0.0
+	github.com/doug-martin/goqu/v9 v9.19.0
+	github.com/matryer/is v1.4.1
+)
+
+replace github.com/dav-m85/mapper => ../
@@ -0,0 +1,47 @@
+// Package goqumapper builds github.com/doug-martin/goqu/v9 expressions out
+// of mappers, so goqu users get mapper verified column names and values.
+// It lives in its own module to keep mapper free of dependencies.
+package goqumapper
+
+import (
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+// Mapper is the part of a mapper these helpers need.
+type Mapper interface {
+	Columns() []string
+	ValuesMap(dest any) map[string]any
+}
+
+// Cols returns the columns of m as goqu identifiers:
+//
+//	goqu.From("users").Select(goqumapper.Cols(users)...)
+//
+// Select takes them as ...any, see [Selectable].
+func Cols(m Mapper) []exp.IdentifierExpression {
+	cols := m.Columns()
+	res := make([]exp.IdentifierExpression, len(cols))
+	for i, col := range cols {
+		res[i] = goqu.C(col)
+	}
+	return res
+}
+
+// Selectable is like [Cols] but returns a slice Select accepts directly.
+func Selectable(m Mapper) []any {
+	cols := Cols(m)
+	res := make([]any, len(cols))
+	for i, c := range cols {
+		res[i] = c
+	}
+	return res
+}
+
+// Record returns the values of rec by column, for goqu inserts and updates:
+//
+//	goqu.Insert("users").Rows(goqumapper.Record(users, u))
+//	goqu.Update("users").Set(goqumapper.Record(users, u))
+func Record(m Mapper, rec any) goqu.Record {
+	return goqu.Record(m.ValuesMap(rec))
+}
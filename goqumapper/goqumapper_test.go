@@ -0,0 +1,27 @@
+package goqumapper
+
+import (
+	"testing"
+
+	"github.com/dav-m85/mapper"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/matryer/is"
+)
+
+type user struct {
+	ID   int
+	Name string
+}
+
+func TestGoqu(t *testing.T) {
+	is := is.New(t)
+	users := mapper.Mapper(user{}, "*")
+
+	sql, _, err := goqu.From("users").Select(Selectable(users)...).ToSQL()
+	is.NoErr(err)
+	is.Equal(sql, `SELECT "id", "name" FROM "users"`)
+
+	sql, _, err = goqu.Insert("users").Rows(Record(users, user{1, "a"})).ToSQL()
+	is.NoErr(err)
+	is.Equal(sql, `INSERT INTO "users" ("id", "name") VALUES (1, 'a')`)
+}
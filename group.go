@@ -0,0 +1,93 @@
+package mapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GroupBy returns a GROUP BY clause of cols, with a leading space, to
+// complete reporting queries:
+//
+//	q := "SELECT country, count(*) FROM users" + users.GroupBy("country")
+//
+// It panics when some of cols are not mapped by m, so typos surface early
+// rather than as broken SQL.
+func (m *mapper) GroupBy(cols ...string) string {
+	s, err := m.GroupByE(cols...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// GroupByE is like [GroupBy] but returns [ErrNoColumns] or an
+// [*ErrMissingColumns] instead of panicking.
+func (m *mapper) GroupByE(cols ...string) (string, error) {
+	if len(cols) == 0 {
+		return "", ErrNoColumns
+	}
+	if missing := m.unknown(cols); len(missing) > 0 {
+		return "", &ErrMissingColumns{Cols: missing}
+	}
+	return " GROUP BY " + strings.Join(cols, m.sep()), nil
+}
+
+// Aggregate is a predicate of a HAVING clause comparing an aggregate of a
+// column to an argument, as count(id) > ?, see [Having].
+type Aggregate struct {
+	// Func is count, sum, avg, min or max.
+	Func string
+
+	// Col is a mapped column, or * for count.
+	Col string
+
+	// Op is =, <>, !=, <, <=, > or >=.
+	Op string
+}
+
+// Having returns a HAVING clause of preds, with a leading space, joined by
+// AND, each taking an argument:
+//
+//	q := "SELECT country FROM users" + users.GroupBy("country") + users.Having(1, Aggregate{"count", "*", ">="})
+//
+// Placeholders of the [Postgres] dialect are numbered from n. It panics
+// when a predicate is not made of known functions, mapped columns and
+// comparison operators.
+func (m *mapper) Having(n int, preds ...Aggregate) string {
+	s, err := m.HavingE(n, preds...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// HavingE is like [Having] but returns an error instead of panicking.
+func (m *mapper) HavingE(n int, preds ...Aggregate) (string, error) {
+	if len(preds) == 0 {
+		return "", ErrNoColumns
+	}
+	var b strings.Builder
+	for i, p := range preds {
+		fn := strings.ToLower(p.Func)
+		switch fn {
+		case "count", "sum", "avg", "min", "max":
+		default:
+			return "", fmt.Errorf("unknown aggregate function %q", p.Func)
+		}
+		switch p.Op {
+		case "=", "<>", "!=", "<", "<=", ">", ">=":
+		default:
+			return "", fmt.Errorf("unknown comparison operator %q", p.Op)
+		}
+		if !(p.Col == "*" && fn == "count") && !m.Has(p.Col) {
+			return "", &ErrMissingColumns{Cols: []string{p.Col}}
+		}
+		if i == 0 {
+			b.WriteString(" HAVING ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		b.WriteString(fn + "(" + p.Col + ")" + p.Op + m.placeholder(n+i))
+	}
+	return b.String(), nil
+}
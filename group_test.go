@@ -0,0 +1,32 @@
+package mapper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGroupBy(t *testing.T) {
+	is := is.New(t)
+	type Sale struct {
+		Country string `mapper:"country"`
+		Day     string `mapper:"day"`
+		Amount  int64  `mapper:"amount"`
+	}
+	dut := Mapper(Sale{}, "*")
+	is.Equal(dut.GroupBy("country", "day"), " GROUP BY country,day")
+	is.Equal(dut.Having(1, Aggregate{"count", "*", ">="}, Aggregate{"SUM", "amount", ">"}), " HAVING count(*)>=? AND sum(amount)>?")
+	pg := dut.With(WithDialect(Postgres))
+	is.Equal(pg.Having(3, Aggregate{"avg", "amount", "<"}), " HAVING avg(amount)<$3")
+
+	_, err := dut.GroupByE("contry")
+	var missing *ErrMissingColumns
+	is.True(errors.As(err, &missing))
+	_, err = dut.HavingE(1, Aggregate{"sum", "*", ">"})
+	is.True(errors.As(err, &missing))
+	_, err = dut.HavingE(1, Aggregate{"count", "*", "; DROP"})
+	is.True(err != nil)
+	_, err = dut.HavingE(1, Aggregate{"pg_sleep", "amount", ">"})
+	is.True(err != nil)
+}
@@ -0,0 +1,62 @@
+package mapper
+
+import "context"
+
+// BeforeInserter is implemented by records needing some preparation, like
+// normalization or validation, before being inserted. [Insert] calls
+// BeforeInsert on pointers to records, and aborts if it fails:
+//
+//	func (u *User) BeforeInsert(ctx context.Context) error {
+//	  u.Email = strings.ToLower(u.Email)
+//	  return nil
+//	}
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterScanner is implemented by records needing some processing once
+// scanned, like computing derived fields. The scan helpers call AfterScan
+// on every scanned record, and stop if it fails. Helpers taking no context,
+// as [ScanRow], pass context.Background().
+type AfterScanner interface {
+	AfterScan(ctx context.Context) error
+}
+
+// WithValidator has validate check records before [Insert], [Update],
+// [Upsert], [InsertBatch] and [BulkUpdate] build their values, once
+// BeforeInsert hooks ran, so invalid records are rejected with the error
+// it returns before a round trip to the database. Validate gets records
+// as given, pointers to them in batches. The Struct method of a
+// go-playground/validator, which reports field errors from validate tags,
+// is one:
+//
+//	var users = Mapper(User{}, "*").SetOptions(WithValidator(validator.New().Struct))
+func WithValidator(validate func(rec any) error) MapperOption {
+	return func(m *mapper) {
+		m.validator = validate
+	}
+}
+
+// validate runs the validator of m on rec, if any.
+func (m *mapper) validate(rec any) error {
+	if m.validator == nil {
+		return nil
+	}
+	return m.validator(rec)
+}
+
+// beforeInsert runs the BeforeInsert hook of rec, if any.
+func beforeInsert(ctx context.Context, rec any) error {
+	if h, ok := rec.(BeforeInserter); ok {
+		return h.BeforeInsert(ctx)
+	}
+	return nil
+}
+
+// afterScan runs the AfterScan hook of rec, if any.
+func afterScan(ctx context.Context, rec any) error {
+	if h, ok := rec.(AfterScanner); ok {
+		return h.AfterScan(ctx)
+	}
+	return nil
+}
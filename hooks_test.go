@@ -0,0 +1,43 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithValidator(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	conn := &fakeConnector{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	errName := errors.New("Name: required")
+	users := Mapper(buildUser{}, "*").With(WithDialect(Postgres), WithValidator(func(rec any) error {
+		if u := rec.(*buildUser); u.Name == "" {
+			return errName
+		}
+		return nil
+	}))
+
+	ok := buildUser{ID: 1, Name: "a"}
+	_, err := users.Insert(ctx, db, "users", &ok)
+	is.NoErr(err)
+	is.Equal(len(conn.calls), 1)
+
+	bad := buildUser{ID: 2}
+	_, err = users.Insert(ctx, db, "users", &bad)
+	is.Equal(err, errName)
+	_, err = users.Update(ctx, db, "users", &bad)
+	is.Equal(err, errName)
+	_, err = users.Upsert(ctx, db, "users", &bad)
+	is.Equal(err, errName)
+	_, err = users.InsertBatch(ctx, db, "users", []buildUser{ok, bad}, BatchOptions{})
+	is.Equal(err, errName)
+	_, err = users.BulkUpdate(ctx, db, "users", []buildUser{ok, bad})
+	is.Equal(err, errName)
+	is.Equal(len(conn.calls), 1) // no round trip
+}
@@ -0,0 +1,88 @@
+package mapper
+
+import (
+	"strings"
+	"unicode"
+)
+
+// checkColumn returns an [*ErrUnsafeColumn] unless col can be pasted in SQL
+// as is, or opts has raw.
+func checkColumn(col string, opts TagOptions) error {
+	if opts.Has("raw") {
+		return nil
+	}
+	for rest := col; ; {
+		part, after, dotted := cutQualifier(rest)
+		if !identifier(part) && !quotedIdentifier(part) {
+			return &ErrUnsafeColumn{Col: col}
+		}
+		if !dotted {
+			return nil
+		}
+		rest = after
+	}
+}
+
+// cutQualifier slices s around its first dot outside quotes, as in
+// "a.b".c, like [strings.Cut].
+func cutQualifier(s string) (before, after string, found bool) {
+	var end byte
+	if s != "" {
+		switch s[0] {
+		case '"', '`':
+			end = s[0]
+		case '[':
+			end = ']'
+		}
+	}
+	if end == 0 {
+		return strings.Cut(s, ".")
+	}
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] != end:
+		case i+1 < len(s) && s[i+1] == end:
+			i++ // escaped by doubling
+		default:
+			if rest, ok := strings.CutPrefix(s[i+1:], "."); ok {
+				return s[:i+1], rest, true
+			}
+			return s, "", false
+		}
+	}
+	return s, "", false
+}
+
+func identifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// quotedIdentifier tells whether s is quoted, with the closing quote only
+// appearing escaped by doubling in between.
+func quotedIdentifier(s string) bool {
+	if len(s) < 3 {
+		return false
+	}
+	var end byte
+	switch s[0] {
+	case '"', '`':
+		end = s[0]
+	case '[':
+		end = ']'
+	default:
+		return false
+	}
+	if s[len(s)-1] != end {
+		return false
+	}
+	inner := strings.ReplaceAll(s[1:len(s)-1], string([]byte{end, end}), "")
+	return !strings.ContainsRune(inner, rune(end)) && !strings.ContainsRune(inner, 0)
+}
@@ -0,0 +1,108 @@
+package mapper
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// identityMap holds the records scanned within a unit of work, by type
+// and primary key.
+type identityMap struct {
+	mu   sync.Mutex
+	recs map[identityKey]reflect.Value
+}
+
+type identityKey struct {
+	t   reflect.Type
+	key any
+}
+
+type identityCtxKey struct{}
+
+// IdentityContext returns a context of ctx carrying an identity map, so
+// that within it a row scanned twice by [QueryRefs], or loaded twice by
+// [Eager] or [EagerJoin] into pointer fields, is the same struct instance:
+//
+//	ctx = mapper.IdentityContext(ctx) // per request
+//	us, err := mapper.QueryRefs[User](ctx, users.Eager("groups"), db, q)
+//
+// Records are told apart by their primary key, those without one are not
+// cached. Later scans of a cached row return the first instance as is,
+// fresher values being dropped.
+//
+// Only those helpers consult it: helpers returning records as values, as
+// [Query], [ScanRow], [ForEach], [Stream] and the All functions, make a
+// copy of every row.
+func IdentityContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, identityCtxKey{}, &identityMap{recs: make(map[identityKey]reflect.Value)})
+}
+
+// identity returns the cached instance of rec, a pointer to an m record,
+// caching rec when it is the first.
+func (m *mapper) identity(ctx context.Context, rec reflect.Value) reflect.Value {
+	im, ok := ctx.Value(identityCtxKey{}).(*identityMap)
+	if !ok {
+		return rec
+	}
+	pks := m.pkIndexesE()
+	if len(pks) == 0 {
+		return rec
+	}
+	k := identityKey{m.elem, relKey(m, rec.Elem(), pks)}
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if cached, ok := im.recs[k]; ok {
+		return cached
+	}
+	im.recs[k] = rec
+	return rec
+}
+
+// QueryRefs is like [Query] but returns pointers, which are shared within
+// an identity map, see [IdentityContext].
+func QueryRefs[T any](ctx context.Context, m *mapper, db Queryer, query string, args ...any) (res []*T, err error) {
+	ctx, end := m.startStatement(ctx, "", query, args)
+	defer func() {
+		end(int64(len(res)), err)
+	}()
+	rows, err := m.queryContext(ctx, db, query, args)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	defer rows.Close()
+	if m.eagerJoin {
+		recs, err := m.scanJoined(ctx, rows, true)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+		for _, rec := range recs {
+			res = append(res, rec.Interface().(*T))
+		}
+		return res, nil
+	}
+	for rows.Next() {
+		rec := new(T)
+		if err := m.scan(rows, rec); err != nil {
+			return nil, err
+		}
+		cached := m.identity(ctx, reflect.ValueOf(rec)).Interface().(*T)
+		if cached != rec {
+			res = append(res, cached)
+			continue
+		}
+		if err := afterScan(ctx, rec); err != nil {
+			return nil, err
+		}
+		res = append(res, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err)
+	}
+	if len(m.eager) > 0 {
+		if err := m.loadEager(ctx, db, reflect.ValueOf(res), m.eager); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestIdentityContext(t *testing.T) {
+	is := is.New(t)
+	conn := &fakeConnector{answer: func(query string, args []driver.Value) ([]string, [][]driver.Value) {
+		if query == "SELECT id,name FROM buyers WHERE id IN (?)" {
+			return []string{"id", "name"}, [][]driver.Value{{int64(1), "ann"}}
+		}
+		return []string{"id", "user_id"}, [][]driver.Value{{int64(10), int64(1)}, {int64(11), int64(1)}, {int64(10), int64(1)}}
+	}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	buyers := Mapper(eagerBuyer{}, "*").SetOptions(WithTable("buyers"))
+	orders := Mapper(eagerOrder{}, "*").SetOptions(WithTable("orders")).Rel(buyers, "user_id", BelongsTo)
+
+	os, err := QueryRefs[eagerOrder](context.Background(), orders, db, orders.SelectString(""))
+	is.NoErr(err)
+	is.True(os[0] != os[2]) // no identity map
+
+	ctx := IdentityContext(context.Background())
+	os, err = QueryRefs[eagerOrder](ctx, orders.Eager("buyers"), db, orders.SelectString(""))
+	is.NoErr(err)
+	is.Equal(len(os), 3)
+	is.True(os[0] == os[2])
+	is.True(os[0].Buyer == os[1].Buyer)
+	is.Equal(os[1].Buyer.Name, "ann")
+}
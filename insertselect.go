@@ -0,0 +1,109 @@
+package mapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// InsertFromSelect returns a statement copying the rows of srcTable
+// matching where, mapped by src, into destTable, mapped by m, for copy and
+// archive jobs:
+//
+//	INSERT INTO users_archive (id,name,archived_at) SELECT id,name,now() FROM users WHERE deleted
+//
+// Columns of m are filled from the columns of src of the same names, or
+// else mapped from fields of the same names, or from their autocreate
+// expression, and field types must be alike. Where is a condition without
+// the WHERE keyword, left out when empty, and MUST NOT come from user
+// input.
+//
+// With [WithTenantColumn], both mappers must have the same tenant column,
+// which is copied along, and only the rows of the tenant are copied: the
+// statement then takes the tenant as first argument, see [TenantArgs]:
+//
+//	INSERT INTO users_archive (id,name,org_id) SELECT id,name,org_id FROM users WHERE org_id=$1 AND (deleted)
+//
+// It panics when columns do not line up, see [InsertFromSelectE].
+func (m *mapper) InsertFromSelect(destTable string, src *mapper, srcTable, where string) string {
+	s, err := m.InsertFromSelectE(destTable, src, srcTable, where)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// InsertFromSelectE is like [InsertFromSelect] but returns an error
+// instead of panicking: an [*ErrMissingColumns] listing the columns of m
+// missing from src, or an error naming a column whose types differ.
+func (m *mapper) InsertFromSelectE(destTable string, src *mapper, srcTable, where string) (string, error) {
+	if err := m.writable(); err != nil {
+		return "", err
+	}
+	if m.tenantCol != src.tenantCol {
+		return "", errors.New("mapper: cannot copy rows between tenant columns " + m.tenantCol + " and " + src.tenantCol)
+	}
+	var cols, exprs, missing []string
+	for j, col := range m.cols {
+		if m.readOnlyColumn(j) {
+			continue
+		}
+		cols = append(cols, col)
+		k := slices.Index(src.cols, col)
+		if k < 0 {
+			k = slices.IndexFunc(src.fields, func(f field) bool { return f.Name == m.fields[j].Name })
+		}
+		switch {
+		case k >= 0:
+			if !alikeTypes(m.fields[j].Type, src.fields[k].Type) {
+				return "", fmt.Errorf("%s: type %s does not match %s", col, src.fields[k].Type, m.fields[j].Type)
+			}
+			if src.isExpr(k) {
+				exprs = append(exprs, src.fields[k].opts.Get("expr"))
+			} else {
+				exprs = append(exprs, src.cols[k])
+			}
+		case m.autoExpr(j, false) != "":
+			exprs = append(exprs, m.autoExpr(j, false))
+		default:
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) > 0 {
+		return "", &ErrMissingColumns{Cols: missing}
+	}
+	if m.tenantCol != "" {
+		cols = append(cols, m.tenantCol)
+		exprs = append(exprs, m.tenantCol)
+	}
+	m.warnDeprecated(nil)
+	src.warnDeprecated(nil)
+	s := "INSERT INTO " + m.tableName(destTable) + " (" + strings.Join(cols, m.sep()) + ") SELECT " +
+		strings.Join(exprs, src.sep()) + " FROM " + src.tableName(srcTable)
+	switch {
+	case m.tenantCol != "" && where != "":
+		s += " WHERE " + m.tenantCol + "=" + src.placeholder(1) + " AND (" + where + ")"
+	case m.tenantCol != "":
+		s += " WHERE " + m.tenantCol + "=" + src.placeholder(1)
+	case where != "":
+		s += " WHERE " + where
+	}
+	return s, nil
+}
+
+// alikeTypes tells whether fields of types a and b hold the same kind of
+// values, pointers aside.
+func alikeTypes(a, b reflect.Type) bool {
+	for a.Kind() == reflect.Pointer {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Pointer {
+		b = b.Elem()
+	}
+	if a == b {
+		return true
+	}
+	return a.Kind() == b.Kind() && a.Kind() != reflect.Struct && a.Kind() != reflect.Slice && a.Kind() != reflect.Map
+}
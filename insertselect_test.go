@@ -0,0 +1,51 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestInsertFromSelect(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID      int64  `mapper:"id,pk"`
+		Name    string `mapper:"name"`
+		Email   string `mapper:"email"`
+		Deleted bool   `mapper:"deleted"`
+	}
+	type Archived struct {
+		Email      *string   `mapper:"contact"`
+		ID         int64     `mapper:"id,pk"`
+		Name       string    `mapper:"name"`
+		ArchivedAt time.Time `mapper:"archived_at,autocreate=now()"`
+	}
+	users := Mapper(User{}, "*")
+	archive := Mapper(Archived{}, "*")
+	is.Equal(archive.InsertFromSelect("users_archive", users, "users", "deleted"),
+		"INSERT INTO users_archive (contact,id,name,archived_at) SELECT email,id,name,now() FROM users WHERE deleted")
+
+	_, err := archive.InsertFromSelectE("users_archive", users.Subset("id", "email"), "users", "")
+	var missing *ErrMissingColumns
+	is.True(errors.As(err, &missing))
+	is.Equal(missing.Cols, []string{"name"})
+
+	tenant := WithTenantColumn("org_id", func(ctx context.Context) any { return ctx.Value(orgKey{}) })
+	tusers := Mapper(User{}, "id", "name").With(WithDialect(Postgres), tenant)
+	tarchive := Mapper(Archived{}, "id", "name").With(WithDialect(Postgres), tenant)
+	is.Equal(tarchive.InsertFromSelect("users_archive", tusers, "users", "deleted OR name=''"),
+		"INSERT INTO users_archive (id,name,org_id) SELECT id,name,org_id FROM users WHERE org_id=$1 AND (deleted OR name='')")
+	is.Equal(tarchive.InsertFromSelect("users_archive", tusers, "users", ""),
+		"INSERT INTO users_archive (id,name,org_id) SELECT id,name,org_id FROM users WHERE org_id=$1")
+	_, err = archive.InsertFromSelectE("users_archive", tusers, "users", "")
+	is.True(err != nil) // tenant columns differ
+
+	type BadID struct {
+		ID string `mapper:"id"`
+	}
+	_, err = Mapper(BadID{}, "*").InsertFromSelectE("t", users, "users", "")
+	is.True(err != nil)
+}
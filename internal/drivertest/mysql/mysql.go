@@ -0,0 +1,14 @@
+// Package mysql mimics the errors of github.com/go-sql-driver/mysql, so
+// tests exercise their recognition without depending on the driver.
+package mysql
+
+// MySQLError has the shape of mysql.MySQLError.
+type MySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (e *MySQLError) Error() string {
+	return e.Message
+}
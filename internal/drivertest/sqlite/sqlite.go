@@ -0,0 +1,23 @@
+// Package sqlite mimics the errors of modernc.org/sqlite, so tests
+// exercise their recognition without depending on the driver.
+package sqlite
+
+// Error has the shape of sqlite.Error.
+type Error struct {
+	msg  string
+	code int
+}
+
+// NewError returns an error of code with message msg.
+func NewError(code int, msg string) *Error {
+	return &Error{msg, code}
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Code returns the extended result code of e.
+func (e *Error) Code() int {
+	return e.code
+}
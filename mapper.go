@@ -3,14 +3,183 @@
 //
 // When manipulating raw queries, it's easy to introduce bug by misordering fields,
 // forgetting about names.
+//
+// # Statements
+//
+// Statement builders, as [SelectString] or [InsertString], write the usual
+// single table statements, with the primary key made of the fields tagged
+// pk:
+//
+//	type User struct {
+//	  ID   int64 `mapper:"id,pk"`
+//	  Name string
+//	}
+//
+// Placeholders are Mark, or $1, $2... for the [Postgres] dialect. Columns
+// are separated by Separator, or Comma. Fields tagged autocreate or
+// autoupdate are stamped, see [WithClock]. An empty table stands for the
+// mapper table, see [Table].
+//
+// # Tag options
+//
+// Options follow the column name in struct tags, comma separated, as in
+// `mapper:"id,pk"`, some taking a value, as in `mapper:"status,cast=text"`.
+//
+// Fields tagged with a prefix= option, as in `mapper:",prefix=billing_"`,
+// are flattened: the fields of their struct map to columns named with the
+// prefix, so Billing.City maps to billing_city.
+//
+// Fields tagged autocreate or autoupdate are timestamps maintained by the
+// write helpers:
+//
+//	CreatedAt time.Time    `mapper:"created_at,autocreate"`
+//	UpdatedAt sql.NullTime `mapper:"updated_at,autoupdate"`
+//
+// On insert, see [InsertArgs], autocreate fields get the current time when
+// zero, and autoupdate fields get it anyway. On update, see [UpdateArgs],
+// autoupdate fields get the current time while autocreate ones are left
+// out. Records given by pointer are stamped too.
+//
+// Given a value, as in autocreate=now(), the timestamp is left to the
+// database: the value is written in statements in place of a placeholder.
+//
+// Such fields MUST be a time.Time, a *time.Time or a sql.NullTime.
+//
+// Primary keys tagged uuid get a generated UUID on insert when zero, see
+// [InsertArgs]:
+//
+//	ID string `mapper:"id,pk,uuid"`
+//
+// [Insert], [InsertBatch], [Upsert] and other insert helpers generate it,
+// but [Values] does not: it also gives the arguments of updates and CQL
+// statements, which MUST NOT invent keys, so pass [InsertArgs] to
+// hand-written INSERT statements.
+//
+// Other uuid fields are left as is, empty strings being NULL, so nullable
+// foreign keys stay NULL. Tag them default=uuid to generate them too.
+//
+// They MUST be a string, holding the canonical form
+// 01234567-89ab-cdef-0123-456789abcdef, or a [16]byte array, as uuid.UUID
+// types usually are. UUIDs are version 4 unless set otherwise with
+// [WithUUID].
+//
+// The tag value sets how the column stores them, whatever the field type:
+//
+//	uuid or uuid=text  the canonical form, for uuid and char(36) columns
+//	uuid=binary        16 bytes, for BINARY(16) columns
+//	uuid=swapped       16 bytes with the time fields first, as MySQL
+//	                   UUID_TO_BIN(u, 1) stores them for index locality
+//
+// Strings stored as text are left as is. Otherwise scans accept either
+// form, the text one with braces, a urn:uuid: prefix or no dashes, empty
+// strings are NULL, and NULL scans as the zero value.
+//
+// Fields tagged expr map to a SQL expression rather than a column, as
+// computed projections and aggregates:
+//
+//	type CountryStats struct {
+//	  Country string  `mapper:"country"`
+//	  Users   int64   `mapper:"users,expr=count(*)"`
+//	  Revenue float64 `mapper:"revenue,expr=sum(price*qty)"`
+//	}
+//
+// [SelectString] then selects count(*) AS users, and so on, while writing
+// statements leave them out. Joins, as [JoinSelectString], cannot qualify
+// their columns, so they panic on them. Expressions cannot hold commas, which
+// separate tag options, and MUST NOT come from user input.
+//
+// Fields tagged cast= are cast to the given SQL type, which settles the
+// type of values drivers send as text, as enums and intervals with pgx:
+//
+//	Status string `mapper:"status,cast=order_status"`
+//
+// The [Postgres] dialect selects status::order_status and writes
+// $1::order_status placeholders, others use CAST(status AS order_status)
+// and CAST(? AS order_status).
+//
+// Fields tagged gob hold any Go value, stored gob encoded in a bytes
+// column, for occasional complex values with no need for JSON:
+//
+//	Prefs map[string][]int `mapper:"prefs,gob"`
+//
+// Nil values are NULL, and NULL scans as the zero value. Concrete types
+// held by interface fields MUST be registered with [gob.Register]. gob
+// encoded fields can also be compressed and encrypted.
+//
+// Fields tagged default= get the DEFAULT clause of [CreateTableString],
+// and their default on insert when zero, so defaults are declared next to
+// the mapping rather than in constructors:
+//
+//	type User struct {
+//	  ID      string    `mapper:"id,pk,default=uuid"`
+//	  Role    string    `mapper:"role,default='member'"`
+//	  Karma   int       `mapper:"karma,default=10"`
+//	  Created time.Time `mapper:"created,default=now()"`
+//	}
+//
+// Defaults are SQL literals, quoted strings, numbers and booleans, now,
+// now() or current_timestamp for time fields, stamped as with autocreate,
+// and uuid, uuid() or gen_random_uuid() for string and [16]byte fields,
+// generated as with the uuid option. Other expressions, as nextval(...),
+// are only for the DDL. [InsertArgs] applies them, and sets them in
+// records given as pointers.
+//
+// Fields tagged generated map to columns the database computes, as STORED
+// or VIRTUAL generated columns, which reject written values:
+//
+//	type Item struct {
+//	  ID    int64   `mapper:"id,pk"`
+//	  Price float64 `mapper:"price"`
+//	  Qty   int64   `mapper:"qty"`
+//	  Total float64 `mapper:"total,generated=price*qty"`
+//	}
+//
+// They are selected and scanned as any column, while INSERT, UPDATE and
+// upsert statements leave them out. generated=expr has [CreateTableString]
+// declare the column GENERATED ALWAYS AS (expr) STORED. [ValidateSchema]
+// reports generated columns not tagged so, and tagged ones which are not.
+//
+// # Converters
+//
+// Fields of type big.Rat and big.Int, or pointers to them, round trip
+// NUMERIC columns exactly, where float64 loses precision:
+//
+//	Price *big.Rat `mapper:"price"`
+//
+// [Values] gives their decimal text, as "19.99", and scans accept text and
+// numbers. Rationals with no finite decimal form, as 1/3, fail statements.
+// They are converters, which [RegisterConverter] can replace, and the
+// decimalmapper package converts shopspring/decimal values.
+//
+// Fields of type net.IP, netip.Addr, netip.Prefix and net.HardwareAddr, or
+// pointers to them, map to the inet, cidr and macaddr columns of Postgres,
+// and to text columns elsewhere:
+//
+//	Addr   netip.Addr       `mapper:"addr"`
+//	Subnet netip.Prefix     `mapper:"subnet"`
+//	MAC    net.HardwareAddr `mapper:"mac"`
+//
+// [Values] gives their text form, as "10.0.0.1" and "10.0.0.0/8". Scans
+// accept text, and 4 or 16 raw bytes for addresses, as MySQL stores them
+// with INET6_ATON. A prefix length scanned into an address is dropped.
+// Zero values are NULL, and NULL scans as the zero value. They are
+// converters, which [RegisterConverter] can replace.
 package mapper
 
 // License MIT
 // Author github.com/dav-m85
 
 import (
+	"context"
+	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+	"unsafe"
 )
 
 type FieldMapper func(field string) string
@@ -19,9 +188,85 @@ var Direct FieldMapper = func(field string) string { return field }
 
 // mapper carries mapping between database columns' name and go types.
 type mapper struct {
-	fields []int
+	fields []field
 	cols   []string
 	target reflect.Type
+	elem   reflect.Type // struct type of target
+	key    string       // struct tag key
+
+	// tagParser reads tag values, see [WithTagParser].
+	tagParser TagParser
+
+	// now gives the time of autocreate and autoupdate fields.
+	now func() time.Time
+
+	// uuid generates the UUIDs of uuid fields.
+	uuid func() [16]byte
+
+	// tenantCol scopes statements to the tenant given by tenantOf, see
+	// [WithTenantColumn].
+	tenantCol string
+	tenantOf  func(ctx context.Context) any
+
+	// table is the mapper table, see [WithTable], named after the target
+	// by tableNaming when empty.
+	table       string
+	tableNaming TableNaming
+
+	// tracers observe statements run by helpers, see [WithTracer].
+	tracers []Tracer
+
+	// stmts prepares the statements run by helpers, see [WithStmtCache].
+	stmts *StmtCache
+
+	// distinct and distinctOn make SELECT DISTINCT statements, see
+	// [WithDistinct] and [WithDistinctOn].
+	distinct   bool
+	distinctOn []string
+
+	// cipher encrypts fields tagged encrypted, see [WithCipher].
+	cipher Cipher
+
+	// location normalizes time fields, see [WithLocation].
+	location *time.Location
+
+	// warn reports deprecated columns in use, once each as recorded in
+	// warned, see [WithWarnings].
+	warn   func(msg string)
+	warned *sync.Map
+
+	// anonymizers rewrite exported values by column, see
+	// [WithAnonymizer].
+	anonymizers map[string]func(v any) any
+
+	// readOnly forbids writes, see [ReadOnly].
+	readOnly bool
+
+	// validator checks records before writes, see [WithValidator].
+	validator func(rec any) error
+
+	// rels are the relations declared with [Rel], and eager the paths of
+	// those loaded by [Query], see [Eager], joined with eagerJoin, see
+	// [EagerJoin].
+	rels      []relation
+	eager     []string
+	eagerJoin bool
+
+	// compatible struct types accepted as destinations besides elem.
+	compatible []reflect.Type
+
+	// unsafe mode, see [WithUnsafe].
+	unsafe bool
+
+	// joker tells the mapper was built with "*", noJoker forbids it, see
+	// [WithNoJoker].
+	joker, noJoker bool
+
+	// memo caches generated strings, see memoize.
+	memo *memoCache
+
+	// frozen mappers reject option changes, see [Freeze].
+	frozen bool
 
 	// Comma is the field delimiter.
 	// It is set to comma (',') by Mapper
@@ -29,6 +274,10 @@ type mapper struct {
 	// or the Unicode replacement character (0xFFFD).
 	Comma rune
 
+	// Separator is the field delimiter as a string, for lists like "a, b".
+	// When not empty, it takes precedence over Comma.
+	Separator string
+
 	// Mark is the field placeholder.
 	// It is set to question mark ('?') by Mapper
 	// Mark must be a valid rune and must not be \r, \n,
@@ -36,8 +285,15 @@ type mapper struct {
 	Mark rune
 
 	// FieldMapper processes struct's field names when no struct tag is given.
-	// It defaults to [Direct]. Common option are [strings.ToLower], [strings.ToUpper]...
+	// It defaults to [strings.ToLower], which existing mappers rely on:
+	// turning APIKey into api_key rather than apikey would change their
+	// columns, so [SnakeCase] is opt in. Other common options are [Direct],
+	// [strings.ToUpper]...
 	FieldMapper FieldMapper
+
+	// Dialect is the database flavor spoken by dialect-aware helpers.
+	// It is empty by default.
+	Dialect Dialect
 }
 
 // Mapper maps columns from target fields, and provides helper functions around them.
@@ -54,82 +310,257 @@ type mapper struct {
 //	  Field string `mapper:"column_name"`
 //	}
 //
-// You can change Comma, Mark, FieldMapper after instanciation with direct access or
-// [SetOptions].
+// Tag options may follow the column name, see the package doc.
+//
+// You can change Comma, Mark after instanciation with direct access or
+// [SetOptions]. FieldMapper only matters during field resolution, use
+// [MapperWithOptions] to change it.
 func Mapper(target any, columns ...string) *mapper {
 	return MapperWithKey(target, "mapper", columns...)
 }
 
 // MapperWithKey changes the default tag.
 func MapperWithKey(target any, key string, columns ...string) *mapper {
+	m, err := MapperWithKeyE(target, key, columns...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// MapperE is like [Mapper] but returns an error instead of panicking.
+// Errors wrap [ErrNotStruct], [ErrNoColumns], [*ErrDuplicateColumn] or
+// [*ErrMissingColumns] so the cause can be told with [errors.Is] and
+// [errors.As].
+func MapperE(target any, columns ...string) (*mapper, error) {
+	return MapperWithKeyE(target, "mapper", columns...)
+}
+
+// MapperWithKeyE is like [MapperWithKey] but returns an error instead of
+// panicking, see [MapperE].
+func MapperWithKeyE(target any, key string, columns ...string) (*mapper, error) {
+	return newMapper(target, key, nil, columns)
+}
+
+// MapperWithOptions is like [Mapper] but applies opts before fields are
+// resolved, which is required for [WithFieldMapper] to have an effect:
+//
+//	var users = MapperWithOptions(User{}, []MapperOption{WithFieldMapper(SnakeCase)}, "*")
+func MapperWithOptions(target any, opts []MapperOption, columns ...string) *mapper {
+	m, err := MapperWithOptionsE(target, opts, columns...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// MapperWithOptionsE is like [MapperWithOptions] but returns an error
+// instead of panicking, see [MapperE].
+func MapperWithOptionsE(target any, opts []MapperOption, columns ...string) (*mapper, error) {
+	return newMapper(target, "mapper", opts, columns)
+}
+
+func newMapper(target any, key string, opts []MapperOption, columns []string) (*mapper, error) {
 	if key == "" {
-		panic("Mapper MUST have a non empty struct tag key.")
+		return nil, ErrEmptyKey
 	}
 	t := reflect.TypeOf(target)
-	k := t.Kind()
-	if k == reflect.Pointer {
-		k = t.Elem().Kind()
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
 	}
-	if k != reflect.Struct {
-		panic("Mapper first argument MUST be a struct or a struct pointer")
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrNotStruct
 	}
 	m := &mapper{
 		Comma:       ',',
 		Mark:        '?',
 		FieldMapper: strings.ToLower,
 		cols:        make([]string, 0, len(columns)),
-		fields:      make([]int, 0, len(columns)),
+		fields:      make([]field, 0, len(columns)),
 		target:      reflect.TypeOf(target),
+		elem:        t,
+		key:         key,
+		tagParser:   parseTag,
+		now:         time.Now,
+		uuid:        UUIDv4,
+		memo:        new(memoCache),
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
 	if len(columns) == 0 {
-		panic("Mapper MUST select at least one field")
+		return nil, fmt.Errorf("mapping %s: %w", t, ErrNoColumns)
 	}
+	columns = slices.Clone(columns) // we remove found columns from it
 	joker := fieldSlice(columns).joker()
+	if joker && m.noJoker {
+		return nil, fmt.Errorf("mapping %s: %w", t, ErrJoker)
+	}
+	m.joker = joker
+	if err := m.mapStruct(t, nil, 0, "", "", &columns); err != nil {
+		return nil, fmt.Errorf("mapping %s: %w", t, err)
+	}
+
+	if !joker && len(columns) != 0 {
+		return nil, fmt.Errorf("mapping %s: %w", t, &ErrMissingColumns{Cols: columns})
+	}
+	if missing := m.unknown(m.distinctOn); len(missing) > 0 {
+		return nil, fmt.Errorf("mapping %s: %w", t, &ErrMissingColumns{Cols: missing})
+	}
+	if missing := m.unknown(slices.Sorted(maps.Keys(m.anonymizers))); len(missing) > 0 {
+		return nil, fmt.Errorf("mapping %s: %w", t, &ErrMissingColumns{Cols: missing})
+	}
+	if m.tenantCol != "" && slices.Contains(m.cols, m.tenantCol) {
+		return nil, fmt.Errorf("mapping %s: %w", t, &ErrDuplicateColumn{Col: m.tenantCol})
+	}
+	for _, c := range m.compatible {
+		if err := m.checkCompatible(c); err != nil {
+			return nil, fmt.Errorf("mapping %s: %w", t, err)
+		}
+	}
+
+	return m, nil
+}
+
+// mapStruct maps the fields of struct t, found at index and offset in the
+// target, to prefixed columns. path is the dotted field name of t, if
+// nested. Found columns are removed from columns unless mapping all. Fields
+// tagged prefix= are flattened, see the package doc.
+func (m *mapper) mapStruct(t reflect.Type, index []int, offset uintptr, prefix, path string, columns *[]string) error {
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
-		if f.IsExported() {
-			// Transform field Name to a column name
-			// Check first if we have a tag for this field
-			var col string
-			if t := f.Tag.Get(key); t != "" {
-				if strings.HasSuffix(t, ",ignore") {
-					// TODO maybe add panic if this column is in columns
-					continue
-				}
-				col = t
-			} else if m.FieldMapper != nil {
-				col = m.FieldMapper(f.Name)
-			} else {
-				col = f.Name
+		if !f.IsExported() {
+			continue
+		}
+		f.Index = append(slices.Clip(index), i)
+		f.Offset += offset
+		name := path + f.Name
+		// Transform field Name to a column name
+		// Check first if we have a tag for this field
+		col, opts := m.tagParser(f.Tag.Get(m.key))
+		if opts.Has("ignore") {
+			// TODO maybe add panic if this column is in columns
+			continue
+		}
+		if opts.Has("prefix") {
+			if f.Type.Kind() != reflect.Struct {
+				return fmt.Errorf("field %s has a prefix but is not a struct", name)
+			}
+			if err := m.mapStruct(f.Type, f.Index, f.Offset, prefix+opts.Get("prefix"), name+".", columns); err != nil {
+				return err
 			}
+			continue
+		}
+		source := sourceTag
+		if col == "" && m.FieldMapper != nil {
+			col = m.FieldMapper(f.Name)
+			source = sourceFieldMapper
+		} else if col == "" {
+			col = f.Name
+			source = sourceName
+		}
+		col = prefix + col
 
-			// Check if col is listed in wanted fields
-			if !joker {
-				i := fieldSlice(columns).index(col)
-				if i == -1 {
-					continue
-				}
-				// https://github.com/golang/go/wiki/SliceTricks#delete-without-preserving-order
-				// Note we modify fields to exclude fields already mapped
-				// I ain't empty at the end, we tried selecting things that does not exist
-				columns[i] = columns[len(columns)-1]
-				columns = columns[:len(columns)-1]
+		// Check if col is listed in wanted fields
+		if !m.joker {
+			cols := *columns
+			i := fieldSlice(cols).index(col)
+			if i == -1 {
+				continue
 			}
+			// https://github.com/golang/go/wiki/SliceTricks#delete-without-preserving-order
+			// Note we modify fields to exclude fields already mapped
+			// I ain't empty at the end, we tried selecting things that does not exist
+			cols[i] = cols[len(cols)-1]
+			*columns = cols[:len(cols)-1]
+		}
 
-			if fieldSlice(m.cols).index(col) != -1 {
-				panic("Field " + col + " is mapped more than once")
+		// Colliding with a selected field, possibly from another source,
+		// as with ID and Id folded by strings.ToLower.
+		if j := fieldSlice(m.cols).index(col); j != -1 {
+			return &ErrDuplicateColumn{
+				Col:     col,
+				Fields:  []string{m.fields[j].Name, name},
+				Sources: []string{m.fields[j].source, source},
 			}
+		}
 
-			m.cols = append(m.cols, col)
-			m.fields = append(m.fields, i)
+		if err := checkColumn(col, opts); err != nil {
+			return err
+		}
+		if err := checkAuto(f.Type, opts); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		uuid, err := checkUUID(f.Type, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := checkCast(opts); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := checkVersion(opts); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		compressor, err := checkBlob(f.Type, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
 		}
+		loc, err := checkTZ(f.Type, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		flags, err := checkBitmask(f.Type, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := m.checkPartition(col, opts); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		f.Name = name
+		m.cols = append(m.cols, col)
+		nf := newField(f, opts, source)
+		nf.compressor, nf.loc, nf.flags, nf.uuid = compressor, loc, flags, uuid
+		nf.def = defaultOf(f.Type, opts)
+		nf.codec = codecOf(f.Type)
+		m.fields = append(m.fields, nf)
 	}
+	return nil
+}
 
-	if !joker && len(columns) != 0 {
-		panic("Some fields are missing from target: " + strings.Join(columns, ","))
-	}
+// field is a mapped struct field. Index and Offset are relative to the
+// target, and Name is dotted for flattened fields, as in Billing.City.
+type field struct {
+	reflect.StructField
+	opts       TagOptions
+	source     string         // where the column name comes from
+	ptrType    unsafe.Pointer // interface type word of a pointer to the field
+	encrypted  bool           // tagged encrypted, see [WithCipher]
+	compressor Compressor     // of compress= tagged fields, see [RegisterCompressor]
+	gob        bool           // tagged gob, stored gob encoded
+	loc        *time.Location // of tz= tagged fields, see [WithLocation]
+	flags      *flagSet       // of bitmask tagged fields, see [RegisterFlags]
+	uuid       uuidForm       // of uuid tagged fields
+	def        defaulter      // of default= tagged fields, applied on insert
+	codec      *codec         // of fields of converted types, see [RegisterConverter]
+}
 
-	return m
+// Column name sources.
+const (
+	sourceTag         = "tag"
+	sourceFieldMapper = "FieldMapper"
+	sourceName        = "field name"
+	sourceRename      = "Rename"
+)
+
+func newField(f reflect.StructField, opts TagOptions, source string) field {
+	return field{
+		StructField: f,
+		opts:        opts,
+		source:      source,
+		ptrType:     typeWord(reflect.Zero(reflect.PointerTo(f.Type)).Interface()),
+		encrypted:   opts.Has("encrypted"),
+		gob:         opts.Has("gob"),
+	}
 }
 
 func (m *mapper) Columns() []string {
@@ -140,101 +571,375 @@ func (m *mapper) Columns() []string {
 // column1,column2,column3
 // If you need to prefix those columns, use [ColumnsStringPrefix] instead.
 func (m *mapper) ColumnsString() string {
+	return m.ColumnsStringPrefix("")
+}
+
+// ColumnsStringPrefix is like [ColumnsString] but puts prefix in front of every
+// column, as in t.column1,t.column2
+func (m *mapper) ColumnsStringPrefix(prefix string) string {
+	return m.memoize(memoKey{prefix: prefix, sep: m.Separator, comma: m.Comma}, (*mapper).columnsString)
+}
+
+func (m *mapper) columnsString(k memoKey) string {
 	if len(m.cols) == 1 {
-		return m.cols[0]
+		return k.prefix + m.cols[0]
 	}
+	k.sep = k.delimiter()
 
-	n := len(m.cols) - 1 // one rune per Comma
+	n := (len(m.cols) - 1) * len(k.sep)
 	for i := 0; i < len(m.cols); i++ {
-		n += len(m.cols[i])
+		n += len(m.cols[i]) + len(k.prefix)
 	}
 
 	var b strings.Builder
 	b.Grow(n)
-	b.WriteString(m.cols[0])
+	b.WriteString(k.prefix + m.cols[0])
 	for _, s := range m.cols[1:] {
-		b.WriteRune(m.Comma)
-		b.WriteString(s)
+		b.WriteString(k.sep)
+		b.WriteString(k.prefix + s)
 	}
 	return b.String()
 }
 
-func (m *mapper) ColumnsStringPrefix(prefix string) string {
-	if len(m.cols) == 1 {
-		return prefix + m.cols[0]
-	}
+// Addrs returns all mapped fields of dest as slice of addressable interfaces.
+// dest must be a pointer to the mapper target type, or to a type declared
+// with [WithCompatible].
+// So if dest := &struct{a int}, Addrs will return [{*int}].
+// TODO(dmo) dest == nil reuses Mapper first argument
+func (m *mapper) Addrs(dest any) []any {
+	return m.AddrsInto(dest, nil)
+}
 
-	n := len(m.cols) - 1 // one rune per Comma
-	for i := 0; i < len(m.cols); i++ {
-		n += len(m.cols[i]) + len(prefix)
+// AddrsInto is like [Addrs] but stores the addresses in buf, which is grown
+// when too small. Reusing buf between rows keeps read loops allocation-free:
+//
+//	var buf []any
+//	for rows.Next() {
+//	  var r Record
+//	  buf = ma.AddrsInto(&r, buf)
+//	  rows.Scan(buf...)
+//	}
+func (m *mapper) AddrsInto(dest any, buf []any) []any {
+	v, p, err := m.pointedStruct(dest)
+	if err != nil {
+		panic(err)
+	}
+	if cap(buf) < len(m.fields) {
+		buf = make([]any, 0, len(m.fields))
+	}
+	buf = buf[:0]
+	for j := range m.fields {
+		buf = append(buf, m.fieldAddr(v, p, j))
 	}
+	return buf
+}
 
-	var b strings.Builder
-	b.Grow(n)
-	b.WriteString(prefix + m.cols[0])
-	for _, s := range m.cols[1:] {
-		b.WriteRune(m.Comma)
-		b.WriteString(prefix + s)
+// AddrsE is like [Addrs] but returns an error when dest is not a struct
+// pointer, is nil or is not of the mapper target type, instead of panicking.
+func (m *mapper) AddrsE(dest any) ([]any, error) {
+	v, p, err := m.pointedStruct(dest)
+	if err != nil {
+		return nil, err
 	}
-	return b.String()
+	res := make([]any, 0, len(m.fields))
+	for j := range m.fields {
+		res = append(res, m.fieldAddr(v, p, j))
+	}
+	return res, nil
 }
 
-// Addrs returns all mapped fields of dest as slice of addressable interfaces.
+// VisitAddrs calls fn with each mapped column and the address of the
+// matching field of dest, in column order. It is the allocation-free
+// counterpart of [Addrs] for callers feeding their own decoders, but for
+// fields scanned through a wrapper, as compressed or uuid ones.
 // dest must be a struct pointer.
-// So if dest := &struct{a int}, Addrs will return [{*int}].
-// TODO(dmo) dest == nil reuses Mapper first argument
-func (m *mapper) Addrs(dest any) (res []any) {
+func (m *mapper) VisitAddrs(dest any, fn func(col string, addr any)) {
+	v, p, err := m.pointedStruct(dest)
+	if err != nil {
+		panic(err)
+	}
+	for j, col := range m.cols {
+		fn(col, m.fieldAddr(v, p, j))
+	}
+}
+
+// Values of dest as a slice of interfaces. dest MUST be of the mapper target
+// type, or a pointer to it. See [WithCompatible] for other types.
+func (m *mapper) Values(dest any) []any {
+	m.mustWritable()
+	v, p, err := m.structOf(dest)
+	if err != nil {
+		panic(err)
+	}
+	return m.values(v, p)
+}
+
+// ValuesE is like [Values] but returns an error when dest is not a struct,
+// is a nil pointer or is not of the mapper target type, instead of
+// panicking.
+func (m *mapper) ValuesE(dest any) ([]any, error) {
+	if err := m.writable(); err != nil {
+		return nil, err
+	}
+	v, p, err := m.structOf(dest)
+	if err != nil {
+		return nil, err
+	}
+	return m.values(v, p), nil
+}
+
+func (m *mapper) values(v reflect.Value, p unsafe.Pointer) []any {
+	res := make([]any, 0, len(m.fields))
+	for j := range m.fields {
+		res = append(res, m.fieldValue(v, p, j))
+	}
+	return res
+}
+
+// VisitValues calls fn with each mapped column and the matching value of
+// dest, in column order, sparing the intermediate slice built by [Values].
+// Values are still boxed in interfaces, which allocates for most fields
+// not holding pointers. dest MUST be a struct or a pointer to a struct.
+func (m *mapper) VisitValues(dest any, fn func(col string, v any)) {
+	m.mustWritable()
+	v, p, err := m.structOf(dest)
+	if err != nil {
+		panic(err)
+	}
+	for j, col := range m.cols {
+		fn(col, m.fieldValue(v, p, j))
+	}
+}
+
+// pointedStruct returns the struct dest points to and, in unsafe mode, its
+// address.
+func (m *mapper) pointedStruct(dest any) (reflect.Value, unsafe.Pointer, error) {
 	v := reflect.ValueOf(dest)
-	if v.Type().Kind() != reflect.Pointer {
-		panic("destination not a pointer")
+	if v.Kind() != reflect.Pointer {
+		return v, nil, ErrNotPointer
+	}
+	if v.IsNil() {
+		return v, nil, ErrNilPointer
 	}
 	v = v.Elem()
-	if v.Type().Kind() != reflect.Struct {
-		panic("destination not a struct pointer")
+	if v.Kind() != reflect.Struct {
+		return v, nil, ErrDestNotStruct
+	}
+	if err := m.checkType(v.Type()); err != nil {
+		return v, nil, err
 	}
-	// TODO(dmo) check that dest same type as Mapper first argument
-	for _, i := range m.fields {
-		res = append(res, v.Field(i).Addr().Interface())
+	if m.unsafe {
+		return v, v.Addr().UnsafePointer(), nil
 	}
-	return
+	return v, nil, nil
 }
 
-// Values of dest as a slice of interfaces. dest MUST be a struct or a pointer
-// to a struct.
-func (m *mapper) Values(dest any) (res []any) {
-	v := reflect.ValueOf(dest) // dest ?
-	if reflect.TypeOf(dest).Kind() == reflect.Pointer {
+// structOf returns the struct dest is or points to and, in unsafe mode, its
+// address when it has one.
+func (m *mapper) structOf(dest any) (reflect.Value, unsafe.Pointer, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return v, nil, ErrNilPointer
+		}
 		v = v.Elem()
 	}
-	if reflect.TypeOf(v).Kind() != reflect.Struct {
-		panic("destination not a struct")
+	if v.Kind() != reflect.Struct {
+		return v, nil, ErrDestNotStruct
+	}
+	if err := m.checkType(v.Type()); err != nil {
+		return v, nil, err
+	}
+	if m.unsafe && v.CanAddr() {
+		return v, v.Addr().UnsafePointer(), nil
+	}
+	return v, nil, nil
+}
+
+// checkType returns an error unless t is the struct m was built from, or one
+// declared with [WithCompatible]. Field indexes, let alone offsets, mean
+// nothing on another struct.
+func (m *mapper) checkType(t reflect.Type) error {
+	if t == m.elem || (!m.unsafe && slices.Contains(m.compatible, t)) {
+		return nil
+	}
+	return &ErrTypeMismatch{Got: t, Want: m.elem}
+}
+
+// fieldAddr returns the address of the j-th mapped field of v. p is the
+// address of v in unsafe mode, nil otherwise.
+func (m *mapper) fieldAddr(v reflect.Value, p unsafe.Pointer, j int) any {
+	var a any
+	if p != nil {
+		*(*eface)(unsafe.Pointer(&a)) = eface{m.fields[j].ptrType, unsafe.Add(p, m.fields[j].Offset)}
+	} else {
+		a = v.FieldByIndex(m.fields[j].Index).Addr().Interface()
+	}
+	if m.fields[j].isBlob() {
+		return blobScanner{&m.fields[j], m.cipher, reflect.ValueOf(a)}
+	}
+	if fs := m.fields[j].flags; fs != nil {
+		return flagsScanner{fs, reflect.ValueOf(a)}
 	}
-	for _, i := range m.fields {
-		res = append(res, v.Field(i).Interface())
+	if form := m.fields[j].uuid; form != uuidNone {
+		return uuidScanner{form, reflect.ValueOf(a)}
 	}
-	return
+	if c := m.fields[j].codec; c != nil {
+		return codecScanner{c, reflect.ValueOf(a)}
+	}
+	if loc := m.locationOf(j); loc != nil {
+		return tzScanner{loc, a}
+	}
+	return a
+}
+
+// fieldValue returns the value of the j-th mapped field of v. p is the
+// address of v in unsafe mode, nil otherwise.
+func (m *mapper) fieldValue(v reflect.Value, p unsafe.Pointer, j int) any {
+	var a any
+	if p != nil {
+		a = reflect.NewAt(m.fields[j].Type, unsafe.Add(p, m.fields[j].Offset)).Elem().Interface()
+	} else {
+		a = v.FieldByIndex(m.fields[j].Index).Interface()
+	}
+	if m.fields[j].isBlob() {
+		return m.encodeBlob(j, a)
+	}
+	if fs := m.fields[j].flags; fs != nil {
+		return fs.value(a)
+	}
+	if form := m.fields[j].uuid; form != uuidNone {
+		return uuidValue(form, a)
+	}
+	if c := m.fields[j].codec; c != nil {
+		return c.value(a)
+	}
+	return m.utc(j, a)
+}
+
+// eface is the runtime layout of an empty interface.
+type eface struct {
+	typ  unsafe.Pointer
+	data unsafe.Pointer
+}
+
+// typeWord returns the type word of a.
+func typeWord(a any) unsafe.Pointer {
+	return (*eface)(unsafe.Pointer(&a)).typ
 }
 
-// Marks returns a string of n Mark separated by Comma, where n is number of
+// Marks returns a string of n Mark separated by Separator, where n is number of
 // mapped fields.
 // So then Mapper(T, "a", "b").Marks() = "?,?"
 func (m *mapper) Marks() string {
+	m.mustWritable()
+	return m.allMarks()
+}
+
+// allMarks is [Marks] without the write guard, for placeholders of any
+// list of as many values as columns, as key lists of eager loads.
+func (m *mapper) allMarks() string {
+	return m.memoize(memoKey{marks: true, sep: m.Separator, comma: m.Comma, mark: m.Mark}, (*mapper).marks)
+}
+
+func (m *mapper) marks(k memoKey) string {
 	if len(m.cols) == 1 {
-		return string(m.Mark)
+		return string(k.mark)
 	}
+	k.sep = k.delimiter()
 
-	n := 2*len(m.cols) - 1 // one rune per Comma, one per Mark
+	n := len(m.cols)*utf8.RuneLen(k.mark) + (len(m.cols)-1)*len(k.sep)
 
 	var b strings.Builder
 	b.Grow(n)
-	b.WriteRune(m.Mark)
+	b.WriteRune(k.mark)
 	for i := 0; i < len(m.cols)-1; i++ {
-		b.WriteRune(m.Comma)
-		b.WriteRune(m.Mark)
+		b.WriteString(k.sep)
+		b.WriteRune(k.mark)
 	}
 	return b.String()
 }
 
+// memoKey identifies a generated string along with the settings it was
+// built with, so changing separators or Mark never serves a stale value.
+type memoKey struct {
+	marks  bool
+	prefix string
+	sep    string
+	comma  rune
+	mark   rune
+}
+
+// sep returns the field delimiter, Separator or else Comma.
+func (m *mapper) sep() string {
+	return memoKey{sep: m.Separator, comma: m.Comma}.delimiter()
+}
+
+// delimiter returns sep, or else comma.
+func (k memoKey) delimiter() string {
+	if k.sep != "" {
+		return k.sep
+	}
+	return string(k.comma)
+}
+
+// memoMax bounds the strings memoized by a mapper, as prefixes may come
+// from callers, as table aliases.
+const memoMax = 64
+
+// memoCache holds memoized strings of a mapper.
+type memoCache struct {
+	mu sync.RWMutex
+	m  map[memoKey]string
+}
+
+// reset drops every memoized string.
+func (c *memoCache) reset() {
+	c.mu.Lock()
+	c.m = nil
+	c.mu.Unlock()
+}
+
+// memoize returns the string for k, building it once with build, or each
+// time once memoMax strings are memoized.
+func (m *mapper) memoize(k memoKey, build func(m *mapper, k memoKey) string) string {
+	c := m.memo
+	c.mu.RLock()
+	s, ok := c.m[k]
+	c.mu.RUnlock()
+	if ok {
+		return s
+	}
+	s = build(m, k)
+	c.mu.Lock()
+	if c.m == nil {
+		c.m = make(map[memoKey]string)
+	}
+	if len(c.m) < memoMax {
+		c.m[k] = s
+	}
+	c.mu.Unlock()
+	return s
+}
+
+// Clone returns an unfrozen copy of m, that can be configured independently.
+func (m *mapper) Clone() *mapper {
+	c := *m
+	c.memo = new(memoCache)
+	c.frozen = false
+	return &c
+}
+
+// Freeze makes m safe to share between goroutines: from now on, [SetOptions]
+// panics. Use [With] to derive a differently configured mapper. Exported
+// fields such as Comma MUST NOT be assigned on a frozen mapper either.
+//
+//	var users = Mapper(User{}, "*").Freeze()
+func (m *mapper) Freeze() *mapper {
+	m.frozen = true
+	return m
+}
+
 // fieldSlice helper
 type fieldSlice []string
 
@@ -1,6 +1,8 @@
 package mapper
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/matryer/is"
@@ -60,7 +62,7 @@ func TestMapperDuplicate(t *testing.T) {
 		if r := recover(); r == nil {
 			t.Errorf("The code did not panic")
 		} else {
-			is.Equal(r.(string), "Field b is mapped more than once")
+			is.Equal(r.(error).Error(), "mapping mapper.M: Field b is mapped more than once: A (tag) and B (FieldMapper)")
 		}
 	}()
 	type M struct {
@@ -105,7 +107,7 @@ func TestMapperUnknownField(t *testing.T) {
 		if r := recover(); r == nil {
 			t.Errorf("The code did not panic")
 		} else {
-			is.Equal(r.(string), "Some fields are missing from target: c")
+			is.Equal(r.(error).Error(), "mapping mapper.M: Some fields are missing from target: c")
 		}
 	}()
 	type M struct {
@@ -115,3 +117,399 @@ func TestMapperUnknownField(t *testing.T) {
 
 	Mapper(M{}, "a", "c")
 }
+
+func TestAddrsInto(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string
+		B int
+	}
+
+	dut := Mapper(M{}, "*")
+	m := new(M)
+	buf := make([]any, 0, 2)
+	res := dut.AddrsInto(m, buf)
+	is.Equal(res, []any{&m.A, &m.B})
+	is.Equal(&res[0], &buf[:1][0]) // buf storage is reused
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = dut.AddrsInto(m, buf)
+	})
+	is.Equal(allocs, 0.0)
+}
+
+func TestColumnsStringMemo(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string
+		B string
+	}
+
+	dut := Mapper(M{}, "*")
+	is.Equal(dut.ColumnsString(), "a,b")
+	is.Equal(dut.ColumnsStringPrefix("t."), "t.a,t.b")
+	is.Equal(dut.Marks(), "?,?")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		dut.ColumnsString()
+		dut.Marks()
+	})
+	is.Equal(allocs, 0.0)
+
+	dut.Comma = ';'
+	is.Equal(dut.ColumnsString(), "a;b")
+	dut.SetOptions(WithMark('$'))
+	is.Equal(dut.Marks(), "$;$")
+}
+
+func TestUnsafe(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string
+		b int32
+		C int64
+	}
+
+	dut := Mapper(M{}, "*").SetOptions(WithUnsafe())
+	m := &M{A: "a", C: 3}
+	is.Equal(dut.Addrs(m), []any{&m.A, &m.C})
+	is.Equal(dut.Values(m), []any{"a", int64(3)})
+	is.Equal(dut.Values(*m), []any{"a", int64(3)})
+
+	defer func() {
+		is.True(recover() != nil) // other types are rejected
+	}()
+	dut.Addrs(&struct{ A, C string }{})
+}
+
+// wide is a 16 columns record used in benchmarks.
+type wide struct {
+	A, B, C, D, E, F, G, H string
+	I, J, K, L, M, N, O, P int64
+}
+
+func benchmarkAddrs(b *testing.B, opts ...MapperOption) {
+	dut := Mapper(wide{}, "*").SetOptions(opts...)
+	var w wide
+	var buf []any
+	for i := 0; i < b.N; i++ {
+		buf = dut.AddrsInto(&w, buf)
+	}
+}
+
+func BenchmarkAddrs(b *testing.B)       { benchmarkAddrs(b) }
+func BenchmarkAddrsUnsafe(b *testing.B) { benchmarkAddrs(b, WithUnsafe()) }
+
+func benchmarkValues(b *testing.B, opts ...MapperOption) {
+	dut := Mapper(wide{}, "*").SetOptions(opts...)
+	w := new(wide)
+	for i := 0; i < b.N; i++ {
+		dut.Values(w)
+	}
+}
+
+func BenchmarkValues(b *testing.B)       { benchmarkValues(b) }
+func BenchmarkValuesUnsafe(b *testing.B) { benchmarkValues(b, WithUnsafe()) }
+
+func TestVisit(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string
+		B int
+	}
+
+	dut := Mapper(M{}, "*")
+	m := &M{A: "x", B: 2}
+	var cols []string
+	var vals []any
+	dut.VisitValues(m, func(col string, v any) {
+		cols = append(cols, col)
+		vals = append(vals, v)
+	})
+	is.Equal(cols, []string{"a", "b"})
+	is.Equal(vals, []any{"x", 2})
+
+	dut.VisitAddrs(m, func(col string, addr any) {
+		if col == "b" {
+			*addr.(*int) = 3
+		}
+	})
+	is.Equal(m.B, 3)
+
+	m.B = 1000 // boxed on the heap, unlike small ints
+	is.Equal(testing.AllocsPerRun(10, func() {
+		dut.VisitAddrs(m, func(string, any) {})
+	}), 0.0)
+	is.Equal(testing.AllocsPerRun(10, func() {
+		dut.VisitValues(m, func(string, any) {})
+	}), testing.AllocsPerRun(10, func() {
+		dut.Values(m)
+	})-1) // the slice
+}
+
+func TestMemoBounded(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string
+		B string
+	}
+	dut := Mapper(M{}, "*")
+	for i := range 2 * memoMax {
+		prefix := fmt.Sprintf("t%d.", i)
+		is.Equal(dut.ColumnsStringPrefix(prefix), prefix+"a,"+prefix+"b")
+	}
+	is.Equal(len(dut.memo.m), memoMax)
+}
+
+func TestFreeze(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string
+		B string
+	}
+
+	dut := Mapper(M{}, "*").Freeze()
+	semi := dut.With(WithComma(';'))
+	is.Equal(dut.ColumnsString(), "a,b")
+	is.Equal(semi.ColumnsString(), "a;b")
+
+	clone := dut.Clone()
+	clone.SetOptions(WithMark('$'))
+	is.Equal(clone.Marks(), "$,$")
+	is.Equal(dut.Marks(), "?,?")
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+	dut.SetOptions(WithComma(';'))
+}
+
+func TestMapperE(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string `mapper:"b"`
+		B string
+	}
+
+	_, err := MapperE(M{}, "*")
+	var dup *ErrDuplicateColumn
+	is.True(errors.As(err, &dup))
+	is.Equal(dup.Col, "b")
+
+	// Only selected columns collide.
+	m, err := MapperE(M{}, "b")
+	is.NoErr(err)
+	is.Equal(m.Columns(), []string{"b"})
+
+	_, err = MapperE(M{}, "c", "d")
+	var missing *ErrMissingColumns
+	is.True(errors.As(err, &missing))
+	is.Equal(missing.Cols, []string{"c", "d"})
+
+	_, err = MapperE(1, "*")
+	is.True(errors.Is(err, ErrNotStruct))
+
+	_, err = MapperE(nil, "*")
+	is.True(errors.Is(err, ErrNotStruct))
+
+	_, err = MapperE(M{})
+	is.True(errors.Is(err, ErrNoColumns))
+}
+
+func TestMapperUnsafeColumn(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string `mapper:"u.id"`
+		B string `mapper:"\"first name\""`
+		C string `mapper:"count(*),raw"`
+		D string `mapper:"d; DROP TABLE users"`
+	}
+
+	dut := Mapper(M{}, "u.id", "\"first name\"", "count(*)")
+	is.Equal(dut.ColumnsString(), "u.id,\"first name\",count(*)")
+
+	_, err := MapperE(M{}, "*")
+	var unsafe *ErrUnsafeColumn
+	is.True(errors.As(err, &unsafe))
+	is.Equal(unsafe.Col, "d; DROP TABLE users")
+
+	for _, col := range []string{"a b", "a;", "a--", "\"a\"b\"", "[a", "a..b", "`a`; x", `"a".`, `"a"x.b`, `"a.b`} {
+		is.True(checkColumn(col, nil) != nil) // col
+	}
+	for _, col := range []string{"a", "café", "a$1", "42", `"a""b"`, "`order`", "[order]", "s.t.c", `"a.b"`, `"a.b".c`, `t."first.name"`, "[a.b].[c]"} {
+		is.NoErr(checkColumn(col, nil)) // col
+	}
+}
+
+func TestAddrsValuesE(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string
+	}
+	type N struct {
+		A string
+	}
+
+	dut := Mapper(M{}, "*")
+	m := &M{A: "x"}
+	addrs, err := dut.AddrsE(m)
+	is.NoErr(err)
+	is.Equal(addrs, []any{&m.A})
+	values, err := dut.ValuesE(*m)
+	is.NoErr(err)
+	is.Equal(values, []any{"x"})
+
+	_, err = dut.AddrsE(*m)
+	is.Equal(err, ErrNotPointer)
+	_, err = dut.AddrsE((*M)(nil))
+	is.Equal(err, ErrNilPointer)
+	_, err = dut.ValuesE((*M)(nil))
+	is.Equal(err, ErrNilPointer)
+	_, err = dut.ValuesE(1)
+	is.Equal(err, ErrDestNotStruct)
+	_, err = dut.AddrsE(new(int))
+	is.Equal(err, ErrDestNotStruct)
+	var mismatch *ErrTypeMismatch
+	_, err = dut.AddrsE(&N{})
+	is.True(errors.As(err, &mismatch))
+	_, err = dut.ValuesE(N{})
+	is.True(errors.As(err, &mismatch))
+}
+
+func TestTypeMismatch(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string
+		B int
+	}
+	type N struct {
+		B int
+		A string
+	}
+	type O M
+
+	dut := Mapper(M{}, "*")
+	func() {
+		defer func() {
+			var mismatch *ErrTypeMismatch
+			err, _ := recover().(error)
+			is.True(errors.As(err, &mismatch))
+		}()
+		dut.Addrs(&N{})
+	}()
+
+	dut.SetOptions(WithCompatible(O{}))
+	o := &O{A: "x", B: 1}
+	is.Equal(dut.Addrs(o), []any{&o.A, &o.B})
+	is.Equal(dut.Values(o), []any{"x", 1})
+
+	// Same field count, other layout.
+	var mismatch *ErrTypeMismatch
+	_, err := MapperWithOptionsE(M{}, []MapperOption{WithCompatible(N{})}, "*")
+	is.True(errors.As(err, &mismatch))
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			is.True(errors.As(err, &mismatch))
+		}()
+		Mapper(M{}, "*").SetOptions(WithCompatible(N{}))
+	}()
+}
+
+func TestNoJoker(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string
+		B string
+	}
+
+	dut := Mapper(M{}, "a", "b").SetOptions(WithNoJoker())
+	_, err := dut.SubsetE("*")
+	is.Equal(err, ErrJoker)
+
+	defer func() {
+		is.Equal(recover(), ErrJoker)
+	}()
+	Mapper(M{}, "*").SetOptions(WithNoJoker())
+}
+
+func TestMapperCollision(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		ID string
+		Id string
+	}
+
+	_, err := MapperE(M{}, "*")
+	var dup *ErrDuplicateColumn
+	is.True(errors.As(err, &dup))
+	is.Equal(dup.Fields, []string{"ID", "Id"})
+	is.Equal(dup.Sources, []string{"FieldMapper", "FieldMapper"})
+
+	// An explicit selection maps the first field only.
+	m, err := MapperE(M{}, "id")
+	is.NoErr(err)
+	is.Equal(m.Values(&M{ID: "a", Id: "b"}), []any{"a"})
+}
+
+func TestSeparator(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		A string
+		B string
+	}
+
+	dut := Mapper(M{}, "*").SetOptions(WithSeparator(", "))
+	is.Equal(dut.ColumnsString(), "a, b")
+	is.Equal(dut.ColumnsStringPrefix("t."), "t.a, t.b")
+	is.Equal(dut.Marks(), "?, ?")
+	is.Equal(dut.NamedSetString(), "a=:a, b=:b")
+
+	dut.SetOptions(WithComma(';'))
+	is.Equal(dut.ColumnsString(), "a;b")
+}
+
+func TestMapperPrefix(t *testing.T) {
+	is := is.New(t)
+	type Address struct {
+		City string
+		Zip  string `mapper:"zip_code"`
+	}
+	type Contact struct {
+		Email   string
+		Address `mapper:",prefix="`
+	}
+	type Order struct {
+		ID       int
+		Billing  Address `mapper:",prefix=billing_"`
+		Shipping Contact `mapper:",prefix=ship_"`
+		Home     Address `mapper:",prefix="`
+	}
+	dut := Mapper(Order{}, "*")
+	is.Equal(dut.Columns(), []string{"id", "billing_city", "billing_zip_code", "ship_email", "ship_city", "ship_zip_code", "city", "zip_code"})
+
+	o := Order{ID: 1, Billing: Address{"Paris", "75001"}}
+	is.Equal(dut.Values(&o)[:3], []any{1, "Paris", "75001"})
+	addrs := dut.Addrs(&o)
+	is.Equal(addrs[1], &o.Billing.City)
+	is.Equal(addrs[4], &o.Shipping.Zip)
+	is.Equal(addrs[5], &o.Home.City)
+
+	f, ok := dut.FieldFor("billing_zip_code")
+	is.True(ok)
+	is.Equal(f.Name, "Billing.Zip")
+	col, ok := dut.ColumnFor("Home.City")
+	is.True(ok)
+	is.Equal(col, "city")
+
+	sub := Mapper(Order{}, "billing_city", "id").SetOptions(WithUnsafe())
+	is.Equal(sub.Values(o), []any{1, "Paris"})
+	is.Equal(sub.Addrs(&o), []any{&o.ID, &o.Billing.City})
+
+	type Dup struct {
+		City string
+		Home Address `mapper:",prefix="`
+	}
+	_, err := MapperE(Dup{}, "*")
+	is.Equal(err.Error(), "mapping mapper.Dup: Field city is mapped more than once: City (FieldMapper) and Home.City (FieldMapper)")
+}
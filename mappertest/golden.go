@@ -0,0 +1,138 @@
+package mappertest
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// AssertOption sets how [AssertSQL] compares SQL.
+type AssertOption func(*assertConfig)
+
+type assertConfig struct {
+	update bool
+}
+
+// Update has [AssertSQL] write got to the golden file, rather than compare
+// them, when update is set. Tests usually take it from a flag of their own:
+//
+//	var update = flag.Bool("update", false, "rewrite golden files")
+//
+//	mappertest.AssertSQL(t, users.UpdateString(""), "testdata/update_users.sql", mappertest.Update(*update))
+func Update(update bool) AssertOption {
+	return func(c *assertConfig) {
+		c.update = update
+	}
+}
+
+// AssertSQL fails t unless got is the SQL held in goldenFile, as generated
+// by mapper builders, once both are normalized, see [NormalizeSQL].
+// Placeholders ?, $1, $2... are alike when only one of got and the golden
+// file numbers them, so a golden file is shared between dialects, and
+// otherwise compared as is, catching reordered arguments.
+//
+//	mappertest.AssertSQL(t, users.UpdateString(""), "testdata/update_users.sql")
+//
+// With [Update], got is written to goldenFile instead, so changes are
+// reviewed in the diff of the golden files.
+func AssertSQL(t testing.TB, got, goldenFile string, opts ...AssertOption) {
+	t.Helper()
+	var c assertConfig
+	for _, o := range opts {
+		o(&c)
+	}
+	if c.update {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goldenFile, []byte(strings.TrimSpace(got)+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(goldenFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden file %s missing, run tests with mappertest.Update to write it", goldenFile)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, gn := normalize(got, false)
+	w, wn := normalize(string(want), false)
+	if gn != wn {
+		g, _ = normalize(got, true)
+		w, _ = normalize(string(want), true)
+	}
+	if g != w {
+		t.Errorf("SQL differs from %s, run tests with mappertest.Update to accept it:\n got: %s\nwant: %s", goldenFile, g, w)
+	}
+}
+
+// NormalizeSQL returns query normalized as [AssertSQL] compares it: runs
+// of white space count as one, and white space around commas, parentheses
+// and comparisons does not count. Quoted text is kept as is.
+func NormalizeSQL(query string) string {
+	s, _ := normalize(query, false)
+	return s
+}
+
+// normalize returns query normalized, with $1, $2... placeholders turned
+// into ? when fold is set, and whether it has such placeholders.
+func normalize(query string, fold bool) (string, bool) {
+	numbered := false
+	var b strings.Builder
+	space := false // white space pending
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(query[i+1:], c)
+			if end == -1 {
+				end = len(query) - i // unterminated
+			} else {
+				end += 2
+			}
+			flush(&b, &space, c)
+			b.WriteString(query[i : i+end])
+			i += end - 1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = b.Len() > 0
+		case c == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			numbered = true
+			start := i
+			for i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9' {
+				i++
+			}
+			flush(&b, &space, '$')
+			if fold {
+				b.WriteByte('?')
+			} else {
+				b.WriteString(query[start : i+1])
+			}
+		default:
+			flush(&b, &space, c)
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), numbered
+}
+
+// tight is the punctuation white space does not count around.
+const tight = ",()=<>"
+
+// flush writes the pending white space before c, unless c or the last
+// byte written is tight.
+func flush(b *strings.Builder, space *bool, c byte) {
+	if !*space {
+		return
+	}
+	*space = false
+	s := b.String()
+	if strings.IndexByte(tight, c) != -1 || strings.IndexByte(tight, s[len(s)-1]) != -1 {
+		return
+	}
+	b.WriteByte(' ')
+}
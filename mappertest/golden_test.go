@@ -0,0 +1,51 @@
+package mappertest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dav-m85/mapper"
+	"github.com/dav-m85/mapper/mappertest"
+	"github.com/matryer/is"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	is := is.New(t)
+	is.Equal(mappertest.NormalizeSQL("UPDATE  users\n\tSET name = $1 , email=$12\nWHERE ( id=$2 ) AND x='a  $1  b'"),
+		"UPDATE users SET name=$1,email=$12 WHERE(id=$2)AND x='a  $1  b'")
+	is.Equal(mappertest.NormalizeSQL("SELECT 'open"), "SELECT 'open")
+}
+
+func TestAssertSQL(t *testing.T) {
+	is := is.New(t)
+	type item struct {
+		ID   int64  `mapper:"id,pk"`
+		Name string `mapper:"name"`
+	}
+	m := mapper.Mapper(item{}, "*").SetOptions(mapper.WithTable("items"))
+	golden := filepath.Join(t.TempDir(), "testdata", "update.sql")
+
+	mappertest.AssertSQL(t, m.UpdateString(""), golden, mappertest.Update(true))
+	b, err := os.ReadFile(golden)
+	is.NoErr(err)
+	is.Equal(string(b), "UPDATE items SET name=? WHERE id=?\n")
+
+	is.NoErr(os.WriteFile(golden, []byte("UPDATE items\n  SET name = $1\n  WHERE id = $2\n"), 0o644))
+	mappertest.AssertSQL(t, m.UpdateString(""), golden, mappertest.Update(false))
+	mappertest.AssertSQL(t, m.With(mapper.WithDialect(mapper.Postgres)).UpdateString(""), golden)
+
+	is.NoErr(os.WriteFile(golden, []byte("UPDATE items SET name=$2 WHERE id=$1\n"), 0o644))
+	ft := &fakeT{TB: t}
+	mappertest.AssertSQL(ft, m.With(mapper.WithDialect(mapper.Postgres)).UpdateString(""), golden)
+	is.True(ft.failed) // arguments reordered
+}
+
+// fakeT records failures rather than failing the test.
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (t *fakeT) Errorf(format string, args ...any) { t.failed = true }
+func (t *fakeT) Helper()                           {}
@@ -0,0 +1,158 @@
+// Package mappertest provides canned result sets built from mapped records,
+// so code scanning through mapper can be unit tested without a database:
+//
+//	rows := mappertest.Rows(users, User{ID: 1}, User{ID: 2})
+//	err := mapper.ForEach(users, rows, func(u *User) error { ... })
+//
+// and a [Recorder] database capturing the statements code would run.
+package mappertest
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Mapper is the part of a mapper this package needs.
+type Mapper interface {
+	Columns() []string
+	Values(dest any) []any
+}
+
+// FakeRows is a result set holding the mapped values of some records. It
+// implements mapper.Rows and mapper.ColumnRows.
+type FakeRows struct {
+	cols   []string
+	rows   [][]any
+	cur    int
+	err    error
+	closed bool
+}
+
+// Rows returns a result set with the columns of m, and a row for each of
+// records, mapper target structs or pointers to them.
+func Rows(m Mapper, records ...any) *FakeRows {
+	r := &FakeRows{cols: m.Columns(), rows: make([][]any, len(records))}
+	for i, rec := range records {
+		r.rows[i] = m.Values(rec)
+	}
+	return r
+}
+
+// WithErr makes the result set fail with err once its rows are exhausted,
+// as a connection dropped midway would.
+func (r *FakeRows) WithErr(err error) *FakeRows {
+	r.err = err
+	return r
+}
+
+// Next advances to the next row.
+func (r *FakeRows) Next() bool {
+	if r.closed || r.cur >= len(r.rows) {
+		return false
+	}
+	r.cur++
+	return true
+}
+
+// Scan copies the current row into dest. Values are assigned when their
+// type matches, and otherwise go through sql.Scanner or a conversion.
+func (r *FakeRows) Scan(dest ...any) error {
+	if r.closed {
+		return errors.New("mappertest: Rows are closed")
+	}
+	if r.cur == 0 {
+		return errors.New("mappertest: Scan called without calling Next")
+	}
+	row := r.rows[r.cur-1]
+	if len(dest) != len(row) {
+		return fmt.Errorf("mappertest: expected %d destination arguments in Scan, not %d", len(row), len(dest))
+	}
+	for j, d := range dest {
+		if err := assign(d, row[j]); err != nil {
+			return fmt.Errorf("mappertest: column %s: %w", r.cols[j], err)
+		}
+	}
+	return nil
+}
+
+// Err returns the error given to [FakeRows.WithErr] once rows are exhausted.
+func (r *FakeRows) Err() error {
+	if r.cur < len(r.rows) {
+		return nil
+	}
+	return r.err
+}
+
+// Close closes the result set.
+func (r *FakeRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+// Closed tells whether Close was called, to check that rows do not leak.
+func (r *FakeRows) Closed() bool {
+	return r.closed
+}
+
+// Columns returns the mapped column names.
+func (r *FakeRows) Columns() ([]string, error) {
+	return r.cols, nil
+}
+
+// DriverValues returns the rows as driver values, to feed mocks of
+// database/sql drivers, such as sqlmock:
+//
+//	fake := mappertest.Rows(users, u1, u2)
+//	rows := sqlmock.NewRows(users.Columns())
+//	for _, vs := range fake.DriverValues() {
+//	  rows.AddRow(vs...)
+//	}
+func (r *FakeRows) DriverValues() ([][]driver.Value, error) {
+	res := make([][]driver.Value, len(r.rows))
+	for i, row := range r.rows {
+		res[i] = make([]driver.Value, len(row))
+		for j, v := range row {
+			dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("mappertest: row %d column %s: %w", i, r.cols[j], err)
+			}
+			res[i][j] = dv
+		}
+	}
+	return res, nil
+}
+
+var scannerType = reflect.TypeFor[interface{ Scan(any) error }]()
+
+// assign v to the variable pointed by dest.
+func assign(dest, v any) error {
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() {
+		return fmt.Errorf("destination not a pointer: %T", dest)
+	}
+	if v == nil {
+		dv.Elem().SetZero()
+		return nil
+	}
+	sv := reflect.ValueOf(v)
+	et := dv.Elem().Type()
+	switch {
+	case sv.Type().AssignableTo(et):
+		dv.Elem().Set(sv)
+	case dv.Type().Implements(scannerType):
+		if vr, ok := v.(driver.Valuer); ok {
+			var err error
+			if v, err = vr.Value(); err != nil {
+				return err
+			}
+		}
+		return dest.(interface{ Scan(any) error }).Scan(v)
+	case sv.Type().ConvertibleTo(et):
+		dv.Elem().Set(sv.Convert(et))
+	default:
+		return fmt.Errorf("cannot assign %T to %s", v, et)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package mappertest_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dav-m85/mapper"
+	"github.com/dav-m85/mapper/mappertest"
+	"github.com/matryer/is"
+)
+
+type user struct {
+	ID    int
+	Name  string
+	Email sql.NullString
+}
+
+func TestRows(t *testing.T) {
+	is := is.New(t)
+	m := mapper.Mapper(user{}, "*")
+	want := []user{
+		{1, "ann", sql.NullString{String: "a@b.c", Valid: true}},
+		{2, "bob", sql.NullString{}},
+	}
+	rows := mappertest.Rows(m, want[0], &want[1])
+
+	var got []user
+	err := mapper.ForEach(m, rows, func(u *user) error {
+		got = append(got, *u)
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(got, want)
+	is.True(rows.Closed())
+}
+
+func TestRowsErr(t *testing.T) {
+	is := is.New(t)
+	m := mapper.Mapper(user{}, "*")
+	boom := errors.New("boom")
+	rows := mappertest.Rows(m, user{ID: 1}).WithErr(boom)
+	is.NoErr(rows.Err())
+	err := mapper.ForEach(m, rows, func(u *user) error { return nil })
+	is.Equal(err, boom)
+}
+
+func TestRowsScan(t *testing.T) {
+	is := is.New(t)
+	m := mapper.Mapper(user{}, "id", "name")
+	rows := mappertest.Rows(m, user{ID: 7, Name: "ann"})
+	is.True(rows.Next())
+
+	var id int64 // converted
+	var name sql.NullString
+	is.NoErr(rows.Scan(&id, &name))
+	is.Equal(id, int64(7))
+	is.Equal(name, sql.NullString{String: "ann", Valid: true})
+	is.True(rows.Scan(&id) != nil)
+
+	vs, err := rows.DriverValues()
+	is.NoErr(err)
+	is.Equal(len(vs), 1)
+	is.Equal(vs[0][0], int64(7))
+}
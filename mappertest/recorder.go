@@ -0,0 +1,195 @@
+package mappertest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder is a database running nothing, which records the statements
+// and arguments it is given instead, for dry runs and golden tests:
+//
+//	rec := mappertest.NewRecorder()
+//	defer rec.Close()
+//	_, err := users.BulkUpdate(ctx, rec, "", us)
+//	for _, s := range rec.Statements() { fmt.Println(s) }
+//
+// It is a mapper.Execer and mapper.Queryer, and begins transactions, which
+// are recorded as BEGIN, COMMIT and ROLLBACK. Statements affect no row,
+// and queries return no row. Arguments are recorded as database/sql sends
+// them to drivers, once driver.Valuer ran.
+type Recorder struct {
+	*sql.DB
+	c *recorderConnector
+}
+
+// Statement is a statement given to a [Recorder].
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// String returns the query, followed by its arguments if any.
+func (s Statement) String() string {
+	if len(s.Args) == 0 {
+		return s.Query
+	}
+	var b strings.Builder
+	b.WriteString(s.Query)
+	b.WriteString(" -- ")
+	for i, a := range s.Args {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(formatArg(a))
+	}
+	return b.String()
+}
+
+// formatArg returns a readable form of a, a driver value.
+func formatArg(a any) string {
+	switch v := a.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return strconv.Quote(v)
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(a)
+}
+
+// NewRecorder returns a recorder with no statements.
+func NewRecorder() *Recorder {
+	c := &recorderConnector{}
+	return &Recorder{DB: sql.OpenDB(c), c: c}
+}
+
+// Statements returns the statements recorded so far.
+func (r *Recorder) Statements() []Statement {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+	return append([]Statement(nil), r.c.stmts...)
+}
+
+// Reset forgets the statements recorded so far.
+func (r *Recorder) Reset() {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+	r.c.stmts = nil
+}
+
+// String returns the statements recorded so far, one a line.
+func (r *Recorder) String() string {
+	var b strings.Builder
+	for _, s := range r.Statements() {
+		b.WriteString(s.String() + "\n")
+	}
+	return b.String()
+}
+
+type recorderConnector struct {
+	mu    sync.Mutex
+	stmts []Statement
+}
+
+func (c *recorderConnector) record(query string, args []driver.NamedValue) {
+	s := Statement{Query: query}
+	for _, a := range args {
+		s.Args = append(s.Args, a.Value)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stmts = append(c.stmts, s)
+}
+
+func (c *recorderConnector) Connect(context.Context) (driver.Conn, error) {
+	return recorderConn{c}, nil
+}
+
+func (c *recorderConnector) Driver() driver.Driver { return recorderDriver{} }
+
+type recorderDriver struct{}
+
+func (recorderDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("mappertest: use NewRecorder")
+}
+
+type recorderConn struct{ c *recorderConnector }
+
+func (c recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return recorderStmt{c.c, query}, nil
+}
+
+func (c recorderConn) Close() error { return nil }
+
+func (c recorderConn) Begin() (driver.Tx, error) {
+	c.c.record("BEGIN", nil)
+	return recorderTx{c.c}, nil
+}
+
+func (c recorderConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.c.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (c recorderConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.c.record(query, args)
+	return recorderRows{}, nil
+}
+
+type recorderTx struct{ c *recorderConnector }
+
+func (tx recorderTx) Commit() error {
+	tx.c.record("COMMIT", nil)
+	return nil
+}
+
+func (tx recorderTx) Rollback() error {
+	tx.c.record("ROLLBACK", nil)
+	return nil
+}
+
+// recorderStmt serves prepared statements, recorded when run.
+type recorderStmt struct {
+	c     *recorderConnector
+	query string
+}
+
+func (s recorderStmt) Close() error  { return nil }
+func (s recorderStmt) NumInput() int { return -1 }
+
+func (s recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.record(s.query, named(args))
+	return driver.RowsAffected(0), nil
+}
+
+func (s recorderStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.record(s.query, named(args))
+	return recorderRows{}, nil
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	nv := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return nv
+}
+
+// recorderRows is an empty result set.
+type recorderRows struct{}
+
+func (recorderRows) Columns() []string         { return nil }
+func (recorderRows) Close() error              { return nil }
+func (recorderRows) Next([]driver.Value) error { return io.EOF }
@@ -0,0 +1,44 @@
+package mappertest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dav-m85/mapper"
+	"github.com/dav-m85/mapper/mappertest"
+	"github.com/matryer/is"
+)
+
+func TestRecorder(t *testing.T) {
+	is := is.New(t)
+	type item struct {
+		ID   int64  `mapper:"id,pk"`
+		Name string `mapper:"name"`
+	}
+	m := mapper.Mapper(item{}, "*").SetOptions(mapper.WithTable("items"))
+	ctx := context.Background()
+	rec := mappertest.NewRecorder()
+	defer rec.Close()
+
+	err := mapper.Transact(ctx, rec, nil, func(tx mapper.QueryExecer) error {
+		if _, err := m.Insert(ctx, tx, "", &item{1, "a"}); err != nil {
+			return err
+		}
+		_, err := m.BulkDelete(ctx, tx, "", []int64{2, 3})
+		return err
+	})
+	is.NoErr(err)
+	items, err := mapper.Query[item](ctx, m, rec, m.SelectString(""))
+	is.NoErr(err)
+	is.Equal(len(items), 0)
+
+	is.Equal(rec.String(), `BEGIN
+INSERT INTO items (id,name) VALUES (?,?) -- 1, "a"
+DELETE FROM items WHERE id IN (?,?) -- 2, 3
+COMMIT
+SELECT id,name FROM items
+`)
+	is.Equal(rec.Statements()[1].Args, []any{int64(1), "a"})
+	rec.Reset()
+	is.Equal(len(rec.Statements()), 0)
+}
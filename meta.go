@@ -0,0 +1,82 @@
+package mapper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Has tells whether col is mapped.
+func (m *mapper) Has(col string) bool {
+	return fieldSlice(m.cols).index(col) != -1
+}
+
+// ColumnFor returns the column mapped to the struct field named field.
+func (m *mapper) ColumnFor(field string) (string, bool) {
+	for j, f := range m.fields {
+		if f.Name == field {
+			return m.cols[j], true
+		}
+	}
+	return "", false
+}
+
+// FieldFor returns the struct field mapped to col.
+func (m *mapper) FieldFor(col string) (reflect.StructField, bool) {
+	j := fieldSlice(m.cols).index(col)
+	if j == -1 {
+		return reflect.StructField{}, false
+	}
+	return m.fields[j].StructField, true
+}
+
+// Type returns the struct type m was built from, so tooling makes records
+// of it, as reflect.New(m.Type()).
+func (m *mapper) Type() reflect.Type {
+	return m.elem
+}
+
+// Len returns the number of mapped columns.
+func (m *mapper) Len() int {
+	return len(m.cols)
+}
+
+// FieldInfo describes a mapped column.
+type FieldInfo struct {
+	Column  string       // column name
+	Name    string       // struct field name
+	Type    reflect.Type // struct field type
+	Options TagOptions   // options given in the struct tag, as type=numeric(12,2)
+}
+
+// Fields describes every mapped column, in order, so tooling layered on
+// top of mappers can enumerate them.
+func (m *mapper) Fields() []FieldInfo {
+	res := make([]FieldInfo, len(m.cols))
+	for j, f := range m.fields {
+		res[j] = FieldInfo{
+			Column:  m.cols[j],
+			Name:    f.Name,
+			Type:    f.Type,
+			Options: f.opts,
+		}
+	}
+	return res
+}
+
+// String describes m, listing which field each column maps to, in order:
+//
+//	mapper main.User tag "mapper"
+//	  1  id    ID    int
+//	  2  name  Name  string
+func (m *mapper) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "mapper %s tag %q", m.elem, m.key)
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for j, f := range m.fields {
+		fmt.Fprintf(w, "\n  %d\t%s\t%s\t%s", j+1, m.cols[j], f.Name, f.Type)
+	}
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package mapper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestIntrospection(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		ID   int
+		Name string `mapper:"full_name"`
+	}
+	dut := Mapper(M{}, "*")
+
+	is.True(dut.Has("full_name"))
+	is.True(!dut.Has("name"))
+
+	col, ok := dut.ColumnFor("Name")
+	is.True(ok)
+	is.Equal(col, "full_name")
+	_, ok = dut.ColumnFor("Nope")
+	is.True(!ok)
+
+	f, ok := dut.FieldFor("id")
+	is.True(ok)
+	is.Equal(f.Name, "ID")
+	_, ok = dut.FieldFor("nope")
+	is.True(!ok)
+}
+
+func TestFields(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		ID    int    `mapper:",pk"`
+		Price string `mapper:"price,notnull,type=numeric(12,2)"`
+		Skip  string `mapper:"skip,ignore"`
+	}
+	dut := Mapper(M{}, "*")
+
+	is.Equal(dut.Len(), 2)
+	fields := dut.Fields()
+	is.Equal(fields[0].Column, "id")
+	is.Equal(fields[0].Name, "ID")
+	is.True(fields[0].Options.Has("pk"))
+	is.Equal(fields[1].Column, "price")
+	is.Equal(fields[1].Options, TagOptions{"notnull": "", "type": "numeric(12,2)"})
+	is.Equal(fields[1].Type.Kind(), reflect.String)
+}
+
+func TestString(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		ID       int
+		FullName string `mapper:"name"`
+	}
+
+	is.Equal(Mapper(M{}, "*").String(), `mapper mapper.M tag "mapper"
+  1  id    ID        int
+  2  name  FullName  string`)
+}
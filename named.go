@@ -0,0 +1,104 @@
+package mapper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ColumnRows are [Rows] telling their column names, as *sql.Rows and
+// *sqlx.Rows.
+type ColumnRows interface {
+	Columns() ([]string, error)
+	Scan(dest ...any) error
+}
+
+// StructScan scans the current row of rows into dest, matching columns by
+// name rather than by position, as sqlx StructScan does. Every column of
+// rows must be mapped by m, mapped columns absent from rows are left
+// untouched. It eases moving from sqlx while keeping queries as they are:
+//
+//	for rows.Next() {
+//	  var u User
+//	  err := users.StructScan(rows, &u)
+//	}
+func (m *mapper) StructScan(rows ColumnRows, dest any) error {
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	v, p, err := m.pointedStruct(dest)
+	if err != nil {
+		return err
+	}
+	addrs := make([]any, len(cols))
+	for i, col := range cols {
+		j := fieldSlice(m.cols).index(col)
+		if j == -1 {
+			return fmt.Errorf("missing destination name %s in %s", col, m.elem)
+		}
+		addrs[i] = m.fieldAddr(v, p, j)
+	}
+	if err := rows.Scan(addrs...); err != nil {
+		return err
+	}
+	return afterScan(context.Background(), dest)
+}
+
+// NamedMarks returns named placeholders for mapped columns separated by
+// Separator, as in ":id,:name", suitable for sqlx named queries:
+//
+//	db.NamedExec(`INSERT INTO users (`+users.ColumnsString()+`) VALUES (`+users.NamedMarks()+`)`, users.ValuesMap(u))
+//
+// Expression and generated columns are left out, as from every write, see
+// [InsertString] for the matching column list.
+func (m *mapper) NamedMarks() string {
+	m.mustWritable()
+	var b strings.Builder
+	for j, col := range m.cols {
+		if m.readOnlyColumn(j) {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(m.sep())
+		}
+		b.WriteString(":" + col)
+	}
+	return b.String()
+}
+
+// ValuesMap returns the values of dest by column name, which sqlx named
+// queries take as bindings regardless of struct tags. dest MUST be of the
+// mapper target type, or a pointer to it. Expression and generated columns
+// are left out.
+func (m *mapper) ValuesMap(dest any) map[string]any {
+	m.mustWritable()
+	v, p, err := m.structOf(dest)
+	if err != nil {
+		panic(err)
+	}
+	res := make(map[string]any, len(m.cols))
+	for j, col := range m.cols {
+		if !m.readOnlyColumn(j) {
+			res[col] = m.fieldValue(v, p, j)
+		}
+	}
+	return res
+}
+
+// NamedSetString returns col=:col pairs separated by Separator, for the SET
+// clause of sqlx named updates, expression and generated columns left out.
+func (m *mapper) NamedSetString() string {
+	m.mustWritable()
+	var b strings.Builder
+	for j, col := range m.cols {
+		if m.readOnlyColumn(j) {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(m.sep())
+		}
+		b.WriteString(col + "=:" + col)
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package mapper
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestStructScan(t *testing.T) {
+	is := is.New(t)
+	rows := queryFake(t, []string{"name", "id"}, []driver.Value{"a", int64(1)})
+	defer rows.Close()
+
+	dut := Mapper(scanRecord{}, "*")
+	is.True(rows.Next())
+	var r scanRecord
+	is.NoErr(dut.StructScan(rows, &r))
+	is.Equal(r, scanRecord{1, "a"})
+
+	err := dut.Subset("id").StructScan(rows, &r)
+	is.Equal(err.Error(), "missing destination name name in mapper.scanRecord")
+}
+
+func TestNamed(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(scanRecord{}, "*")
+
+	is.Equal(dut.NamedMarks(), ":id,:name")
+	is.Equal(dut.NamedSetString(), "id=:id,name=:name")
+	is.Equal(dut.ValuesMap(scanRecord{1, "a"}), map[string]any{"id": int64(1), "name": "a"})
+}
+
+func TestScanMap(t *testing.T) {
+	is := is.New(t)
+	type Prefs struct {
+		Theme string `json:"theme"`
+	}
+	type User struct {
+		ID      int64          `mapper:"id"`
+		Name    *string        `mapper:"name"`
+		Born    time.Time      `mapper:"born"`
+		Score   float32        `mapper:"score"`
+		Tags    []string       `mapper:"tags"`
+		Prefs   Prefs          `mapper:"prefs"`
+		Email   sql.NullString `mapper:"email"`
+		Active  bool           `mapper:"active"`
+		Deleted *time.Time     `mapper:"deleted"`
+	}
+	m := Mapper(User{}, "*")
+	is.Equal(m.Type(), reflect.TypeFor[User]())
+	var u User
+	err := m.ScanMap(map[string]any{
+		"id":      1.0,
+		"name":    "alice",
+		"born":    "2000-01-02T00:00:00Z",
+		"score":   2,
+		"tags":    []any{"a", "b"},
+		"prefs":   map[string]any{"theme": "dark"},
+		"email":   "a@b.c",
+		"active":  true,
+		"deleted": nil,
+	}, &u)
+	is.NoErr(err)
+	is.Equal(u.ID, int64(1))
+	is.Equal(*u.Name, "alice")
+	is.Equal(u.Born, time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC))
+	is.Equal(u.Score, float32(2))
+	is.Equal(u.Tags, []string{"a", "b"})
+	is.Equal(u.Prefs.Theme, "dark")
+	is.Equal(u.Email, sql.NullString{String: "a@b.c", Valid: true})
+	is.True(u.Active)
+
+	is.True(m.ScanMap(map[string]any{"id": 1.5}, &u) != nil)
+	is.True(m.ScanMap(map[string]any{"nope": 1}, &u) != nil)
+	is.True(m.ScanMap(map[string]any{"active": 1}, &u) != nil)
+}
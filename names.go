@@ -0,0 +1,97 @@
+package mapper
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultInitialisms are the mixed case initialisms known to [SnakeCase].
+// All caps ones like HTTP or ID need not be listed.
+var DefaultInitialisms = []string{"OAuth", "IPv4", "IPv6", "GraphQL"}
+
+// SnakeCase converts field names to snake_case, keeping acronyms together:
+// APIKey becomes api_key and HTTPServerURL http_server_url. It handles any
+// Unicode letter, and [DefaultInitialisms]. Use [NewSnakeCase] for other
+// initialisms.
+//
+// It is not the default field mapper, which stays [strings.ToLower] for
+// compatibility, so set it with [WithFieldMapper].
+var SnakeCase FieldMapper = NewSnakeCase(DefaultInitialisms...)
+
+// NewSnakeCase returns a snake_case [FieldMapper] treating initialisms as
+// single words, so OAuthToken becomes oauth_token given "OAuth".
+func NewSnakeCase(initialisms ...string) FieldMapper {
+	inits := make([][]rune, len(initialisms))
+	for i, s := range initialisms {
+		inits[i] = []rune(s)
+	}
+	return func(field string) string {
+		return snakeCase([]rune(field), inits)
+	}
+}
+
+func snakeCase(rs []rune, initialisms [][]rune) string {
+	var b strings.Builder
+	b.Grow(len(rs) + 4)
+	underscore := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+			b.WriteByte('_')
+		}
+	}
+	wordEnd := false // an initialism was just written
+	for i := 0; i < len(rs); {
+		if n := matchInitialism(rs[i:], initialisms); n > 0 {
+			underscore()
+			for _, r := range rs[i : i+n] {
+				b.WriteRune(unicode.ToLower(r))
+			}
+			i += n
+			wordEnd = true
+			continue
+		}
+		r := rs[i]
+		if unicode.IsUpper(r) && i > 0 {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if wordEnd || unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				underscore()
+			}
+		} else if wordEnd && unicode.IsLetter(r) {
+			underscore()
+		}
+		b.WriteRune(unicode.ToLower(r))
+		wordEnd = false
+		i++
+	}
+	return b.String()
+}
+
+// matchInitialism returns the length of the initialism rs starts with, if it
+// ends at a word boundary, or 0.
+func matchInitialism(rs []rune, initialisms [][]rune) int {
+	for _, in := range initialisms {
+		if len(in) > len(rs) || string(rs[:len(in)]) != string(in) {
+			continue
+		}
+		if len(in) == len(rs) || !unicode.IsLower(rs[len(in)]) {
+			return len(in)
+		}
+	}
+	return 0
+}
+
+// Pluralize returns the English plural of s, by suffix rules good enough
+// for table names: user becomes users, category categories and address
+// addresses. Irregular plurals are not handled.
+func Pluralize(s string) string {
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
@@ -0,0 +1,44 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSnakeCase(t *testing.T) {
+	is := is.New(t)
+	for in, want := range map[string]string{
+		"ID":            "id",
+		"Name":          "name",
+		"HomeAway":      "home_away",
+		"APIKey":        "api_key",
+		"HTTPServerURL": "http_server_url",
+		"UserID":        "user_id",
+		"Address2":      "address2",
+		"OAuthToken":    "oauth_token",
+		"RemoteIPv4":    "remote_ipv4",
+		"Already_Snake": "already_snake",
+		"ÉtéÀParis":     "été_à_paris",
+		"ΣύνολοΤιμή":    "σύνολο_τιμή",
+	} {
+		is.Equal(SnakeCase(in), want) // in
+	}
+
+	is.Equal(NewSnakeCase("IOs")("IOsApp"), "ios_app")
+}
+
+func TestMapperWithOptions(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		UserID  int
+		APIKey  string
+		Comment string `mapper:"note"`
+	}
+
+	dut := MapperWithOptions(M{}, []MapperOption{WithFieldMapper(SnakeCase)}, "*")
+	is.Equal(dut.Columns(), []string{"user_id", "api_key", "note"})
+
+	_, err := MapperWithOptionsE(M{}, []MapperOption{WithNoJoker()}, "*")
+	is.True(err != nil)
+}
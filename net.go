@@ -0,0 +1,106 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+)
+
+var (
+	ipType     = reflect.TypeFor[net.IP]()
+	addrType   = reflect.TypeFor[netip.Addr]()
+	prefixType = reflect.TypeFor[netip.Prefix]()
+	macType    = reflect.TypeFor[net.HardwareAddr]()
+)
+
+func init() {
+	registerZeroNull(func(ip net.IP) (driver.Value, error) {
+		if ip == nil {
+			return nil, nil // NULL
+		}
+		return ip.String(), nil
+	}, func(src any) (net.IP, error) {
+		a, err := scanAddr(src)
+		if err != nil {
+			return nil, err
+		}
+		return net.IP(a.AsSlice()), nil
+	})
+	registerZeroNull(func(a netip.Addr) (driver.Value, error) {
+		if !a.IsValid() {
+			return nil, nil // NULL
+		}
+		return a.String(), nil
+	}, scanAddr)
+	registerZeroNull(func(p netip.Prefix) (driver.Value, error) {
+		if !p.IsValid() {
+			return nil, nil // NULL
+		}
+		return p.String(), nil
+	}, scanPrefix)
+	registerZeroNull(func(mac net.HardwareAddr) (driver.Value, error) {
+		if mac == nil {
+			return nil, nil // NULL
+		}
+		return mac.String(), nil
+	}, func(src any) (net.HardwareAddr, error) {
+		s, ok := textOf(src)
+		if !ok {
+			return nil, fmt.Errorf("cannot scan %T into a net.HardwareAddr", src)
+		}
+		return net.ParseMAC(s)
+	})
+}
+
+// registerZeroNull registers a converter of T, whose zero value is NULL.
+func registerZeroNull[T any](toDB func(T) (driver.Value, error), fromDB func(src any) (T, error)) {
+	c := newCodec(toDB, fromDB)
+	c.zeroNull = true
+	register(c)
+}
+
+func scanAddr(src any) (netip.Addr, error) {
+	if s, ok := textOf(src); ok {
+		if a, err := netip.ParseAddr(s); err == nil {
+			return a, nil
+		}
+		if p, err := netip.ParsePrefix(s); err == nil {
+			return p.Addr(), nil
+		}
+	}
+	if b, ok := src.([]byte); ok {
+		if a, ok := netip.AddrFromSlice(b); ok {
+			return a, nil
+		}
+	}
+	return netip.Addr{}, fmt.Errorf("cannot scan %T %v into an IP address", src, src)
+}
+
+func scanPrefix(src any) (netip.Prefix, error) {
+	s, ok := textOf(src)
+	if !ok {
+		return netip.Prefix{}, fmt.Errorf("cannot scan %T into a netip.Prefix", src)
+	}
+	if !strings.Contains(s, "/") {
+		a, err := netip.ParseAddr(s) // a host, as Postgres prints them
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(a, a.BitLen()), nil
+	}
+	return netip.ParsePrefix(s)
+}
+
+// textOf returns the text of src, a string or bytes.
+func textOf(src any) (string, bool) {
+	switch v := src.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	}
+	return "", false
+}
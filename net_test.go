@@ -0,0 +1,50 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestNet(t *testing.T) {
+	is := is.New(t)
+	type Host struct {
+		Name   string           `mapper:"name,pk"`
+		IP     net.IP           `mapper:"ip"`
+		Addr   netip.Addr       `mapper:"addr"`
+		Subnet netip.Prefix     `mapper:"subnet"`
+		MAC    net.HardwareAddr `mapper:"mac"`
+		Gw     *netip.Addr      `mapper:"gw"`
+	}
+	dut := Mapper(Host{}, "*")
+	mac, _ := net.ParseMAC("00:1a:2b:3c:4d:5e")
+	vals := dut.Values(Host{"h", net.ParseIP("10.0.0.1"), netip.MustParseAddr("2001:db8::1"), netip.MustParsePrefix("10.0.0.0/8"), mac, nil})
+	is.Equal(vals[1:], []any{"10.0.0.1", "2001:db8::1", "10.0.0.0/8", "00:1a:2b:3c:4d:5e", nil})
+	is.Equal(dut.Values(Host{})[1:5], []any{nil, nil, nil, nil}) // zero values are NULL
+
+	rows := queryFake(t, []string{"name", "ip", "addr", "subnet", "mac", "gw"},
+		[]driver.Value{"h", "10.0.0.1", []byte{192, 168, 0, 1}, "192.168.0.7", []byte("00:1a:2b:3c:4d:5e"), "10.0.0.254/24"},
+		[]driver.Value{"null", nil, nil, nil, nil, nil},
+	)
+	is.True(rows.Next())
+	h, err := ScanRow[Host](dut, rows)
+	is.NoErr(err)
+	is.True(h.IP.Equal(net.ParseIP("10.0.0.1")))
+	is.Equal(h.Addr, netip.MustParseAddr("192.168.0.1"))
+	is.Equal(h.Subnet, netip.MustParsePrefix("192.168.0.7/32"))
+	is.Equal(h.MAC, mac)
+	is.Equal(*h.Gw, netip.MustParseAddr("10.0.0.254"))
+	is.True(rows.Next())
+	h, err = ScanRow[Host](dut, rows)
+	is.NoErr(err)
+	is.Equal(h, Host{Name: "null"})
+
+	ddl, err := dut.With(WithTable("hosts"), WithDialect(Postgres)).CreateTableStringE("")
+	is.NoErr(err)
+	is.True(strings.Contains(ddl, "subnet cidr"))
+	is.True(strings.Contains(ddl, "mac macaddr"))
+}
@@ -1,5 +1,11 @@
 package mapper
 
+import (
+	"reflect"
+	"slices"
+	"time"
+)
+
 // SetOptions allows to set mapper options with a fluent pattern, so you could
 // write:
 //
@@ -9,15 +15,32 @@ package mapper
 //
 //	var mymapper = Mapper()
 //	mymapper.Comma = ','
+//
+// SetOptions panics on a frozen mapper, see [Freeze].
 func (m *mapper) SetOptions(opts ...MapperOption) *mapper {
+	if m.frozen {
+		panic("SetOptions called on a frozen mapper, use With instead")
+	}
 	for _, opt := range opts {
 		opt(m)
 	}
+	m.memo.reset()
 	return m
 }
 
+// With returns a copy of m with opts applied, leaving m untouched. It is the
+// way to derive a mapper from a frozen one:
+//
+//	var users = Mapper(User{}, "*").Freeze()
+//	var pgUsers = users.With(WithMark('$'))
+func (m *mapper) With(opts ...MapperOption) *mapper {
+	return m.Clone().SetOptions(opts...)
+}
+
 type MapperOption func(m *mapper)
 
+// WithFieldMapper sets the FieldMapper. It MUST be given to
+// [MapperWithOptions], as columns are resolved by then.
 func WithFieldMapper(fm FieldMapper) MapperOption {
 	if fm == nil {
 		fm = Direct
@@ -27,9 +50,32 @@ func WithFieldMapper(fm FieldMapper) MapperOption {
 	}
 }
 
+// WithTagParser reads field tags under key with p, so structs tagged for
+// another library map as is. It MUST be given to [MapperWithOptions], as
+// columns are resolved by then:
+//
+//	var users = MapperWithOptions(User{}, []MapperOption{WithTagParser("gorm", GormTags)}, "*")
+func WithTagParser(key string, p TagParser) MapperOption {
+	return func(m *mapper) {
+		m.key = key
+		m.tagParser = p
+	}
+}
+
+// WithComma sets a single rune field delimiter, clearing Separator.
 func WithComma(comma rune) MapperOption {
 	return func(m *mapper) {
 		m.Comma = comma
+		m.Separator = ""
+	}
+}
+
+// WithSeparator sets the field delimiter, which can be longer than a rune:
+//
+//	Mapper(A{}, "*").SetOptions(WithSeparator(", ")).ColumnsString() // a, b
+func WithSeparator(sep string) MapperOption {
+	return func(m *mapper) {
+		m.Separator = sep
 	}
 }
 
@@ -38,3 +84,129 @@ func WithMark(mark rune) MapperOption {
 		m.Mark = mark
 	}
 }
+
+// WithUnsafe makes Addrs and Values locate fields with pointer arithmetic on
+// offsets computed at construction, instead of going through
+// reflect.Value.Field for every column. It pays off on wide structs scanned
+// in hot loops. Destinations must then be of the exact target type.
+func WithUnsafe() MapperOption {
+	return func(m *mapper) {
+		m.unsafe = true
+	}
+}
+
+// WithDialect sets the database flavor used by dialect-aware helpers.
+func WithDialect(d Dialect) MapperOption {
+	return func(m *mapper) {
+		m.Dialect = d
+	}
+}
+
+// WithCompatible lets Addrs, Values and friends accept destinations of the
+// given struct types besides the mapper target. Those MUST have the mapped
+// fields at the same positions, as with types derived from the target:
+//
+//	type Admin User
+//	var users = Mapper(User{}, "*").SetOptions(WithCompatible(Admin{}))
+//
+// It panics with [*ErrTypeMismatch] when a mapped field differs in index,
+// type or offset. It has no effect in unsafe mode, see [WithUnsafe].
+func WithCompatible(targets ...any) MapperOption {
+	types := make([]reflect.Type, 0, len(targets))
+	for _, target := range targets {
+		t := reflect.TypeOf(target)
+		if t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			panic(ErrNotStruct)
+		}
+		types = append(types, t)
+	}
+	return func(m *mapper) {
+		for _, t := range types {
+			if err := m.checkCompatible(t); err != nil {
+				panic(err)
+			}
+		}
+		m.compatible = append(slices.Clip(m.compatible), types...)
+	}
+}
+
+// checkCompatible returns an [*ErrTypeMismatch] unless t has the mapped
+// fields of m at the same index, of the same type and at the same offset.
+// Fields are checked once mapped, so newMapper checks again.
+func (m *mapper) checkCompatible(t reflect.Type) error {
+	for _, f := range m.fields {
+		if !fieldAt(t, f.Index, f.Type, f.Offset) {
+			return &ErrTypeMismatch{Got: t, Want: m.elem}
+		}
+	}
+	return nil
+}
+
+// fieldAt tells whether struct t has a field of type typ at index, offset
+// bytes from its start.
+func fieldAt(t reflect.Type, index []int, typ reflect.Type, offset uintptr) bool {
+	var sf reflect.StructField
+	var off uintptr
+	for _, i := range index {
+		if t.Kind() != reflect.Struct || i >= t.NumField() {
+			return false
+		}
+		sf = t.Field(i)
+		off += sf.Offset
+		t = sf.Type
+	}
+	return sf.Type == typ && off == offset
+}
+
+// WithNoJoker forbids the "*" joker, whose meaning silently changes each
+// time the struct gains a field. It panics with [ErrJoker] if the mapper
+// was built with it, and so does a later [Subset]("*"). Teams can enforce
+// explicit column lists by setting it on every mapper:
+//
+//	var users = Mapper(User{}, "id", "name").SetOptions(WithNoJoker())
+func WithNoJoker() MapperOption {
+	return func(m *mapper) {
+		if m.joker {
+			panic(ErrJoker)
+		}
+		m.noJoker = true
+	}
+}
+
+// WithDistinct makes [SelectString] write SELECT DISTINCT.
+func WithDistinct() MapperOption {
+	return func(m *mapper) {
+		m.distinct = true
+		m.distinctOn = nil
+	}
+}
+
+// WithDistinctOn makes [SelectString] write the SELECT DISTINCT ON (cols)
+// of PostgreSQL, keeping the first row of each cols value:
+//
+//	latest := logins.With(WithDistinctOn("user_id"))
+//	q := latest.SelectString("logins") + " ORDER BY user_id, at DESC"
+//
+// It panics with an [*ErrMissingColumns] when some of cols are not mapped.
+func WithDistinctOn(cols ...string) MapperOption {
+	return func(m *mapper) {
+		if len(m.cols) > 0 { // else checked once mapped
+			if missing := m.unknown(cols); len(missing) > 0 {
+				panic(&ErrMissingColumns{Cols: missing})
+			}
+		}
+		m.distinct = true
+		m.distinctOn = cols
+	}
+}
+
+// WithClock sets the function giving the current time to autocreate and
+// autoupdate fields, time.Now by default. It helps tests.
+func WithClock(now func() time.Time) MapperOption {
+	return func(m *mapper) {
+		m.now = now
+	}
+}
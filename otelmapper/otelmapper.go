@@ -0,0 +1,112 @@
+// Package otelmapper traces and measures the statements run by mapper
+// helpers with OpenTelemetry. It lives in its own module to keep mapper
+// free of dependencies:
+//
+//	var users = mapper.Mapper(User{}, "*").SetOptions(mapper.WithTracer(otelmapper.NewTracer()))
+package otelmapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/dav-m85/mapper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const scope = "github.com/dav-m85/mapper/otelmapper"
+
+// Option configures a tracer.
+type Option func(*tracer)
+
+// WithTracerProvider sets the provider of spans, the global one by
+// default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(t *tracer) {
+		t.tp = tp
+	}
+}
+
+// WithMeterProvider sets the provider of metrics, the global one by
+// default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(t *tracer) {
+		t.mp = mp
+	}
+}
+
+// WithSystem sets the db.system.name attribute, as postgresql.
+func WithSystem(name string) Option {
+	return func(t *tracer) {
+		t.attrs = append(t.attrs, attribute.String("db.system.name", name))
+	}
+}
+
+type tracer struct {
+	tp    trace.TracerProvider
+	mp    metric.MeterProvider
+	attrs []attribute.KeyValue
+
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	rows     metric.Int64Histogram
+}
+
+// NewTracer returns a mapper.Tracer starting a client span per statement,
+// named after its operation and table, as "INSERT users". Spans have the
+// db.operation.name, db.collection.name, db.query.text and
+// mapper.target attributes, and mapper.rows once done.
+//
+// Durations and row counts are recorded by the
+// db.client.operation.duration and mapper.rows histograms.
+func NewTracer(opts ...Option) mapper.Tracer {
+	t := &tracer{
+		tp: otel.GetTracerProvider(),
+		mp: otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.tracer = t.tp.Tracer(scope)
+	meter := t.mp.Meter(scope)
+	t.duration, _ = meter.Float64Histogram("db.client.operation.duration",
+		metric.WithDescription("Duration of database client operations."), metric.WithUnit("s"))
+	t.rows, _ = meter.Int64Histogram("mapper.rows",
+		metric.WithDescription("Rows affected or scanned by statements."), metric.WithUnit("{row}"))
+	return t
+}
+
+func (t *tracer) StartStatement(ctx context.Context, s mapper.TracedStatement) (context.Context, func(int64, error)) {
+	attrs := append([]attribute.KeyValue{
+		attribute.String("db.operation.name", s.Operation),
+		attribute.String("mapper.target", s.Target.String()),
+	}, t.attrs...)
+	name := s.Operation
+	if s.Table != "" {
+		attrs = append(attrs, attribute.String("db.collection.name", s.Table))
+		name += " " + s.Table
+	}
+	// Scans have no query, their statement ran beforehand.
+	kind, spanAttrs := trace.SpanKindInternal, attrs
+	if s.Query != "" {
+		kind, spanAttrs = trace.SpanKindClient, append(attrs[:len(attrs):len(attrs)], attribute.String("db.query.text", s.Query))
+	}
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(spanAttrs...))
+	start := time.Now()
+	return ctx, func(rows int64, err error) {
+		set := metric.WithAttributes(attrs...)
+		t.duration.Record(ctx, time.Since(start).Seconds(), set)
+		if rows >= 0 {
+			span.SetAttributes(attribute.Int64("mapper.rows", rows))
+			t.rows.Record(ctx, rows, set)
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,89 @@
+package otelmapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/dav-m85/mapper"
+	"github.com/matryer/is"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type user struct {
+	ID   int64 `mapper:"id,pk"`
+	Name string
+}
+
+// db runs nothing, failing when err is set.
+type db struct {
+	err error
+}
+
+func (d db) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+// noRows has no rows.
+type noRows struct{}
+
+func (noRows) Next() bool             { return false }
+func (noRows) Scan(dest ...any) error { return nil }
+func (noRows) Err() error             { return nil }
+
+func TestTracer(t *testing.T) {
+	is := is.New(t)
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	m := mapper.Mapper(user{}, "*").SetOptions(mapper.WithTracer(NewTracer(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithSystem("postgresql"),
+	)))
+	ctx := context.Background()
+
+	_, err := m.Insert(ctx, db{}, "users", &user{1, "a"})
+	is.NoErr(err)
+	_, err = m.Delete(ctx, db{errors.New("boom")}, "users", &user{1, "a"})
+	is.True(err != nil)
+	is.NoErr(mapper.ForEach(m, noRows{}, func(*user) error { return nil }))
+
+	ended := spans.Ended()
+	is.Equal(len(ended), 3)
+	is.Equal(ended[0].Name(), "INSERT users")
+	attrs := attribute.NewSet(ended[0].Attributes()...)
+	v, _ := attrs.Value("mapper.rows")
+	is.Equal(v.AsInt64(), int64(1))
+	v, _ = attrs.Value("mapper.target")
+	is.Equal(v.AsString(), "otelmapper.user")
+	v, _ = attrs.Value("db.query.text")
+	is.Equal(v.AsString(), "INSERT INTO users (id,name) VALUES (?,?)")
+	is.Equal(ended[1].Name(), "DELETE users")
+	is.Equal(ended[1].Status().Code, codes.Error)
+	is.Equal(ended[2].Name(), "SCAN users")
+	is.Equal(ended[2].SpanKind(), trace.SpanKindInternal)
+	attrs = attribute.NewSet(ended[2].Attributes()...)
+	is.True(!attrs.HasValue("db.query.text"))
+
+	var rm metricdata.ResourceMetrics
+	is.NoErr(reader.Collect(ctx, &rm))
+	names := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names[m.Name] = true
+		}
+	}
+	is.True(names["db.client.operation.duration"])
+	is.True(names["mapper.rows"])
+}
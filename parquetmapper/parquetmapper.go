@@ -0,0 +1,179 @@
+// Package parquetmapper writes mapped records to Parquet files, with a
+// schema derived from the mapped fields, so query results can be offloaded
+// with the very same column mapping. It lives in its own module to keep
+// mapper free of dependencies.
+package parquetmapper
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/dav-m85/mapper"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Mapper is the part of a mapper this package needs.
+type Mapper interface {
+	Fields() []mapper.FieldInfo
+	ExportValues(dest any) []any
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// nullTypes are the database/sql nullable types, by the type they hold.
+var nullTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeFor[sql.NullString]():  reflect.TypeFor[string](),
+	reflect.TypeFor[sql.NullInt64]():   reflect.TypeFor[int64](),
+	reflect.TypeFor[sql.NullInt32]():   reflect.TypeFor[int32](),
+	reflect.TypeFor[sql.NullInt16]():   reflect.TypeFor[int16](),
+	reflect.TypeFor[sql.NullByte]():    reflect.TypeFor[uint8](),
+	reflect.TypeFor[sql.NullFloat64](): reflect.TypeFor[float64](),
+	reflect.TypeFor[sql.NullBool]():    reflect.TypeFor[bool](),
+	reflect.TypeFor[sql.NullTime]():    timeType,
+}
+
+// Schema derives a Parquet schema named name from the mapped fields.
+// Pointers and sql.Null types are optional columns, times are nanosecond
+// timestamps.
+func Schema(name string, m Mapper) (*parquet.Schema, error) {
+	g := parquet.Group{}
+	for _, f := range m.Fields() {
+		n, err := node(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", f.Column, err)
+		}
+		g[f.Column] = n
+	}
+	return parquet.NewSchema(name, g), nil
+}
+
+func node(t reflect.Type) (parquet.Node, error) {
+	if t.Kind() == reflect.Pointer {
+		n, err := node(t.Elem())
+		return parquet.Optional(n), err
+	}
+	if v, ok := nullTypes[t]; ok {
+		n, err := node(v)
+		return parquet.Optional(n), err
+	}
+	if t == timeType {
+		return parquet.Timestamp(parquet.Nanosecond), nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return parquet.Leaf(parquet.BooleanType), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return parquet.Int(32), nil
+	case reflect.Int, reflect.Int64:
+		return parquet.Int(64), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return parquet.Uint(32), nil
+	case reflect.Uint, reflect.Uint64:
+		return parquet.Uint(64), nil
+	case reflect.Float32:
+		return parquet.Leaf(parquet.FloatType), nil
+	case reflect.Float64:
+		return parquet.Leaf(parquet.DoubleType), nil
+	case reflect.String:
+		return parquet.String(), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return parquet.Leaf(parquet.ByteArrayType), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// Writer writes records one at a time, for exports too large to hold in
+// memory.
+type Writer struct {
+	m      Mapper
+	w      *parquet.Writer
+	leaves []parquet.LeafColumn // by mapped column
+}
+
+// NewWriter returns a Writer of records to out. Close it to complete the
+// file.
+func NewWriter(out io.Writer, m Mapper, opts ...parquet.WriterOption) (*Writer, error) {
+	schema, err := Schema("record", m)
+	if err != nil {
+		return nil, err
+	}
+	fields := m.Fields()
+	leaves := make([]parquet.LeafColumn, len(fields))
+	for j, f := range fields {
+		leaves[j], _ = schema.Lookup(f.Column)
+	}
+	return &Writer{
+		m:      m,
+		w:      parquet.NewWriter(out, append(opts, schema)...),
+		leaves: leaves,
+	}, nil
+}
+
+// Write appends rec, a mapper target struct or a pointer to one.
+func (w *Writer) Write(rec any) error {
+	vs := w.m.ExportValues(rec)
+	row := make(parquet.Row, len(vs))
+	for j, v := range vs {
+		v := plain(v)
+		leaf := w.leaves[j]
+		def := leaf.MaxDefinitionLevel
+		if v == nil {
+			def = 0
+		}
+		row[leaf.ColumnIndex] = parquet.ValueOf(v).Level(0, def, leaf.ColumnIndex)
+	}
+	_, err := w.w.WriteRows([]parquet.Row{row})
+	return err
+}
+
+// Close flushes buffered records and writes the file footer.
+func (w *Writer) Close() error {
+	return w.w.Close()
+}
+
+// plain dereferences pointers and unwraps sql.Null types, returning nil for
+// NULL, and turns times into nanoseconds.
+func plain(v any) any {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if _, ok := nullTypes[rv.Type()]; ok {
+		if !rv.FieldByName("Valid").Bool() {
+			return nil
+		}
+		rv = rv.Field(0)
+	}
+	v = rv.Interface()
+	if t, ok := v.(time.Time); ok {
+		return t.UnixNano()
+	}
+	return v
+}
+
+// Write writes records, a slice of structs or struct pointers, as a Parquet
+// file to out.
+func Write(out io.Writer, m Mapper, records any) error {
+	recs := reflect.ValueOf(records)
+	if recs.Kind() != reflect.Slice && recs.Kind() != reflect.Array {
+		return fmt.Errorf("records not a slice: %s", recs.Kind())
+	}
+	w, err := NewWriter(out, m)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < recs.Len(); i++ {
+		if err := w.Write(recs.Index(i).Interface()); err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+	}
+	return w.Close()
+}
@@ -0,0 +1,65 @@
+package parquetmapper
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dav-m85/mapper"
+	"github.com/matryer/is"
+	"github.com/parquet-go/parquet-go"
+)
+
+type event struct {
+	ID     int64
+	Name   string
+	Note   *string
+	Score  sql.NullFloat64
+	At     time.Time
+	Hidden bool `mapper:",ignore"`
+}
+
+func TestWrite(t *testing.T) {
+	is := is.New(t)
+	events := mapper.Mapper(event{}, "*")
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	note := "n"
+
+	var buf bytes.Buffer
+	is.NoErr(Write(&buf, events, []event{
+		{1, "a", &note, sql.NullFloat64{Float64: 1.5, Valid: true}, at, false},
+		{2, "b", nil, sql.NullFloat64{}, at, false},
+	}))
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	is.NoErr(err)
+	is.Equal(f.NumRows(), int64(2))
+
+	type row struct {
+		ID    int64    `parquet:"id"`
+		Name  string   `parquet:"name"`
+		Note  *string  `parquet:"note,optional"`
+		Score *float64 `parquet:"score,optional"`
+		At    int64    `parquet:"at"`
+	}
+	rows, err := parquet.Read[row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	is.NoErr(err)
+	is.Equal(rows[0].ID, int64(1))
+	is.Equal(*rows[0].Note, "n")
+	is.Equal(*rows[0].Score, 1.5)
+	is.Equal(rows[0].At, at.UnixNano())
+	is.Equal(rows[1].Name, "b")
+	is.Equal(rows[1].Note, nil)
+	is.Equal(rows[1].Score, nil)
+}
+
+func TestSchemaUnsupported(t *testing.T) {
+	is := is.New(t)
+	type bad struct {
+		Tags []string
+	}
+
+	_, err := Schema("bad", mapper.Mapper(bad{}, "*"))
+	is.Equal(err.Error(), "column tags: unsupported type []string")
+}
@@ -0,0 +1,130 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PartitionColumn returns the column tagged partition, if any, see
+// [RouteTable].
+func (m *mapper) PartitionColumn() (string, bool) {
+	j, err := m.partitionIndex()
+	if err != nil {
+		return "", false
+	}
+	return m.cols[j], true
+}
+
+// partitionIndex returns the index of the column tagged partition.
+func (m *mapper) partitionIndex() (int, error) {
+	for j, f := range m.fields {
+		if f.opts.Has("partition") {
+			return j, nil
+		}
+	}
+	return 0, ErrNoPartition
+}
+
+// checkPartition returns an error when col is tagged partition in opts,
+// while another column of m already is.
+func (m *mapper) checkPartition(col string, opts TagOptions) error {
+	if !opts.Has("partition") {
+		return nil
+	}
+	if j, err := m.partitionIndex(); err == nil {
+		return fmt.Errorf("columns %s and %s are both tagged partition", m.cols[j], col)
+	}
+	return nil
+}
+
+// RouteTable returns the table of rec, pattern with its {{}} patterns
+// expanded with the partition key of rec, the field tagged partition, for
+// records spread over tables by hand, as monthly tables of events or
+// hashed shards of users:
+//
+//	type Event struct {
+//	  ID      int64     `mapper:"id,pk"`
+//	  Created time.Time `mapper:"created,partition"`
+//	}
+//
+//	table := events.RouteTable(&e, "events_{{yyyymm}}") // events_202406
+//	_, err := events.Insert(ctx, db, table, &e)
+//
+// Patterns are those of [WithTable], expanded at time keys in the location
+// of the field, see [WithLocation], or else UTC. String and integer keys
+// take {{key}}, put as is, and integer ones {{key%n}} too, put modulo n,
+// as users_{{key%16}}. A single field of a mapper may be tagged partition.
+// The result is checked to be a plain table name, so string keys cannot
+// inject SQL.
+//
+// It panics when the table cannot be named, see [RouteTableE].
+func (m *mapper) RouteTable(rec any, pattern string) string {
+	table, err := m.RouteTableE(rec, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return table
+}
+
+// RouteTableE is like [RouteTable] but returns an error instead of
+// panicking: [ErrNoPartition] when no column is tagged partition, an
+// [*ErrUnsafeColumn] when the table name is not plain, or an error telling
+// why the key does not fit pattern.
+func (m *mapper) RouteTableE(rec any, pattern string) (string, error) {
+	j, err := m.partitionIndex()
+	if err != nil {
+		return "", err
+	}
+	v, _, err := m.structOf(rec)
+	if err != nil {
+		return "", err
+	}
+	key, err := driver.DefaultParameterConverter.ConvertValue(v.FieldByIndex(m.fields[j].Index).Interface())
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", m.cols[j], err)
+	}
+	if key == nil {
+		return "", fmt.Errorf("mapper: partition column %s is NULL", m.cols[j])
+	}
+	return expandPatterns(pattern, func(spec string) (string, error) {
+		s, err := m.formatKey(j, key, spec)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", m.cols[j], err)
+		}
+		return s, nil
+	})
+}
+
+// formatKey returns key, the partition key of column j, formatted as
+// pattern spec of a [RouteTable] pattern tells.
+func (m *mapper) formatKey(j int, key any, spec string) (string, error) {
+	switch k := key.(type) {
+	case time.Time:
+		if layout, ok := tablePatterns[spec]; ok {
+			loc := m.locationOf(j)
+			if loc == nil {
+				loc = time.UTC
+			}
+			return k.In(loc).Format(layout), nil
+		}
+	case int64:
+		if mod, ok := strings.CutPrefix(spec, "key%"); ok {
+			n, err := strconv.ParseInt(mod, 10, 64)
+			if err != nil || n <= 0 {
+				return "", fmt.Errorf("bad modulo {{%s}}", spec)
+			}
+			return strconv.FormatInt((k%n+n)%n, 10), nil
+		}
+		if spec == "key" {
+			return strconv.FormatInt(k, 10), nil
+		}
+	case string:
+		if spec == "key" {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("cannot format %T key with {{%s}}", key, spec)
+}
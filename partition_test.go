@@ -0,0 +1,62 @@
+package mapper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRouteTable(t *testing.T) {
+	is := is.New(t)
+	type Event struct {
+		ID      int64     `mapper:"id,pk"`
+		Created time.Time `mapper:"created,partition"`
+	}
+	events := Mapper(Event{}, "*")
+	col, ok := events.PartitionColumn()
+	is.True(ok)
+	is.Equal(col, "created")
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	is.NoErr(err)
+	e := Event{ID: 1, Created: time.Date(2024, 7, 1, 1, 0, 0, 0, paris)}
+	is.Equal(events.RouteTable(&e, "events_{{yyyymm}}"), "events_202406") // UTC
+	is.Equal(events.With(WithLocation(paris)).RouteTable(e, "events_{{yyyy}}_{{mm}}"), "events_2024_07")
+	is.Equal(events.InsertString(events.RouteTable(e, "archive.events_{{yyyy}}")), "INSERT INTO archive.events_2024 (id,created) VALUES (?,?)")
+	_, err = events.RouteTableE(e, "events_{{key}}")
+	is.True(err != nil) // no layout
+	_, err = events.RouteTableE(e, "events_{{yyyy")
+	is.True(err != nil) // unclosed
+
+	type User struct {
+		ID     int64   `mapper:"id,pk,partition"`
+		Region *string `mapper:"region"`
+	}
+	users := Mapper(User{}, "*")
+	is.Equal(users.RouteTable(User{ID: 35}, "users_{{key%16}}"), "users_3")
+	is.Equal(users.RouteTable(User{ID: -1}, "users_{{key%16}}"), "users_15")
+	is.Equal(users.RouteTable(User{ID: 7}, "users_{{key}}"), "users_7")
+	_, err = users.RouteTableE(User{ID: 7}, "users_{{key%0}}")
+	is.True(err != nil)
+	_, err = users.RouteTableE(User{ID: 7}, "users_{{yyyy}}")
+	is.True(err != nil) // not a time
+
+	type Tenant struct {
+		Org  string  `mapper:"org,partition"`
+		Name *string `mapper:"name,partition"`
+	}
+	tenants := Mapper(Tenant{}, "org")
+	is.Equal(tenants.RouteTable(Tenant{Org: "acme"}, "docs_{{key}}"), "docs_acme")
+	_, err = tenants.RouteTableE(Tenant{Org: "x; DROP TABLE docs"}, "docs_{{key}}")
+	var unsafe *ErrUnsafeColumn
+	is.True(errors.As(err, &unsafe))
+	_, err = MapperE(Tenant{}, "*")
+	is.True(err != nil) // two partition keys
+	_, err = Mapper(Tenant{}, "name").RouteTableE(Tenant{}, "docs_{{key}}")
+	is.True(err != nil) // NULL
+
+	_, err = Mapper(User{}, "region").RouteTableE(User{}, "users_{{key}}")
+	is.Equal(err, ErrNoPartition)
+}
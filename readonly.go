@@ -0,0 +1,34 @@
+package mapper
+
+// ReadOnly returns a copy of m for database views and reporting replicas,
+// whose write oriented methods fail right away. Those binding values or
+// placeholders of every mapped column, as [Marks], [Values],
+// [VisitValues], [ValuesMap], [NamedMarks] and [SquirrelSetMap], and the
+// write statements [InsertString], [UpdateString], [DeleteString], their
+// argument helpers and CQL counterparts panic with [ErrReadOnly], while
+// [Insert], [Update], [Delete], batch and bulk helpers, and methods
+// returning errors, return it. Reading methods, as [SelectString], [Query],
+// [KeyArgs] and [ExportValues], keep working, and mappers derived from m
+// are read only too.
+//
+//	var report = Mapper(SalesRow{}, "*").ReadOnly()
+func (m *mapper) ReadOnly() *mapper {
+	c := m.Clone()
+	c.readOnly = true
+	return c
+}
+
+// writable returns [ErrReadOnly] when m is read only.
+func (m *mapper) writable() error {
+	if m.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// mustWritable panics with [ErrReadOnly] when m is read only.
+func (m *mapper) mustWritable() {
+	if m.readOnly {
+		panic(ErrReadOnly)
+	}
+}
@@ -0,0 +1,64 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestReadOnly(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	db := sql.OpenDB(&fakeConnector{})
+	defer db.Close()
+	users := Mapper(buildUser{}, "*")
+	dut := users.ReadOnly()
+	u := buildUser{ID: 1, Name: "a"}
+
+	is.Equal(dut.SelectString("users"), users.SelectString("users"))
+	is.Equal(dut.ExportValues(u), []any{int64(1), "a", ""})
+	is.Equal(dut.KeyArgs(u), []any{int64(1)})
+	var b strings.Builder
+	is.NoErr(dut.WriteCSV(&b, []buildUser{u}))
+
+	for name, fn := range map[string]func(){
+		"Marks":          func() { dut.Marks() },
+		"Values":         func() { dut.Values(u) },
+		"InsertString":   func() { dut.InsertString("users") },
+		"UpdateString":   func() { dut.UpdateString("users") },
+		"DeleteString":   func() { dut.DeleteString("users") },
+		"InsertArgs":     func() { dut.InsertArgs(u) },
+		"UpdateArgs":     func() { dut.UpdateArgs(u) },
+		"VisitValues":    func() { dut.VisitValues(u, func(string, any) {}) },
+		"ValuesMap":      func() { dut.ValuesMap(u) },
+		"NamedMarks":     func() { dut.NamedMarks() },
+		"SquirrelSetMap": func() { dut.SquirrelSetMap(u) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrReadOnly {
+					t.Errorf("%s: recovered %v, want ErrReadOnly", name, r)
+				}
+			}()
+			fn()
+		}()
+	}
+
+	_, err := dut.ValuesE(u)
+	is.Equal(err, ErrReadOnly)
+	_, err = dut.Insert(ctx, db, "users", u)
+	is.Equal(err, ErrReadOnly)
+	_, err = dut.Update(ctx, db, "users", u)
+	is.Equal(err, ErrReadOnly)
+	_, err = dut.Delete(ctx, db, "users", u)
+	is.Equal(err, ErrReadOnly)
+	_, err = dut.InsertBatch(ctx, db, "users", []buildUser{u}, BatchOptions{})
+	is.Equal(err, ErrReadOnly)
+	_, err = dut.BulkDelete(ctx, db, "users", []int64{1})
+	is.Equal(err, ErrReadOnly)
+
+	is.Equal(users.Values(u), []any{int64(1), "a", ""}) // m is left alone
+}
@@ -0,0 +1,83 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]*mapper)
+)
+
+// Register records m under name, for [Lookup] and [ValidateAll], and
+// returns it:
+//
+//	var users = mapper.Register("users", mapper.Mapper(User{}, "*").SetOptions(mapper.WithDialect(mapper.Postgres)))
+//
+// It panics when name is empty or already taken, see [RegisterE].
+func Register(name string, m *mapper) *mapper {
+	if err := RegisterE(name, m); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// RegisterE is [Register], returning an error instead of panicking.
+func RegisterE(name string, m *mapper) error {
+	if name == "" || m == nil {
+		return errors.New("Register: empty name or nil mapper")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("Register: mapper %q already registered", name)
+	}
+	registry[name] = m
+	return nil
+}
+
+// Lookup returns the mapper registered under name, see [Register].
+func Lookup(name string) (*mapper, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	m, ok := registry[name]
+	return m, ok
+}
+
+// ValidateAll checks the table of every registered mapper against db with
+// [ValidateSchema], in name order, so services have a single hook failing
+// fast at startup:
+//
+//	if err := mapper.ValidateAll(ctx, db); err != nil {
+//	  log.Fatal(err)
+//	}
+//
+// Tables are the mapper ones, see [Table]. It returns the errors of failed
+// queries and of mismatching tables joined, each prefixed with its mapper
+// name.
+func ValidateAll(ctx context.Context, db Queryer) error {
+	registryMu.RLock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	registryMu.RUnlock()
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range names {
+		m, _ := Lookup(name)
+		r, err := m.ValidateSchema(ctx, db, "")
+		if err == nil {
+			err = r.Err()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
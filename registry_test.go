@@ -0,0 +1,46 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRegistry(t *testing.T) {
+	is := is.New(t)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		clear(registry)
+	})
+	type Account struct {
+		ID   int64  `mapper:"id,pk"`
+		Name string `mapper:"name"`
+	}
+	type Post struct {
+		ID    int64 `mapper:"id,pk"`
+		Title int64 `mapper:"title"`
+	}
+	accounts := Register("accounts", Mapper(Account{}, "*").SetOptions(WithDialect(Postgres)))
+	Register("posts", Mapper(Post{}, "*").SetOptions(WithDialect(Postgres)))
+	m, ok := Lookup("accounts")
+	is.True(ok)
+	is.Equal(m, accounts)
+	_, ok = Lookup("nope")
+	is.True(!ok)
+	is.True(RegisterE("accounts", accounts) != nil) // taken
+
+	db := sql.OpenDB(&fakeConnector{answer: func(query string, args []driver.Value) ([]string, [][]driver.Value) {
+		return []string{"column_name", "data_type", "nullable", "generated"}, [][]driver.Value{
+			{"id", "bigint", false, false},
+			{"name", "text", false, false},
+			{"title", "text", false, false},
+		}
+	}})
+	defer db.Close()
+	err := ValidateAll(context.Background(), db)
+	is.Equal(err.Error(), "posts: table posts does not match mapper: column title is text, not compatible with int64")
+}
@@ -0,0 +1,273 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// RelKind is the kind of a relation between two mappers, see [Rel].
+type RelKind int
+
+const (
+	// BelongsTo relations have the foreign key in the owner table,
+	// referencing the primary key of the target table, as posts.author_id.
+	BelongsTo RelKind = iota
+
+	// HasMany relations have the foreign key in the target table,
+	// referencing the primary key of the owner table, as posts.user_id.
+	HasMany
+)
+
+// relation is a relation declared with [Rel].
+type relation struct {
+	target     *mapper
+	foreignKey string
+	kind       RelKind
+	field      []int // index of the struct field holding related records
+}
+
+// Rel returns a mapper of m relating it to target through foreignKey, a
+// column of m for [BelongsTo] relations, of target for [HasMany] ones. The
+// referenced table MUST have a single column primary key, and the struct
+// of m a field holding related records, not mapped to a column:
+//
+//	type User struct {
+//	  ID    int64  `mapper:"id,pk"`
+//	  Name  string `mapper:"name"`
+//	  Posts []Post `mapper:",ignore"`
+//	}
+//
+//	var users = Mapper(User{}, "*").Rel(posts, "user_id", HasMany)
+//
+// It holds a []T or []*T for [HasMany] relations, a T or *T for
+// [BelongsTo] ones, T being the target struct. [JoinString] and
+// [JoinSelectString] then write the join, and [ScanJoined] nests rows.
+//
+// Tables are the mapper ones, see [Table]. Rel panics when the relation
+// cannot be declared.
+func (m *mapper) Rel(target *mapper, foreignKey string, kind RelKind) *mapper {
+	r := relation{target: target, foreignKey: foreignKey, kind: kind}
+	owner, referenced := m, target
+	if kind == HasMany {
+		owner, referenced = target, m
+	}
+	if !owner.Has(foreignKey) {
+		panic(&ErrMissingColumns{Cols: []string{foreignKey}})
+	}
+	if len(referenced.pkIndexes()) != 1 {
+		panic(fmt.Errorf("relation of %s to %s: %s needs a single column primary key", m.elem, target.elem, referenced.elem))
+	}
+	r.field = m.relField(target.elem, kind)
+	return m.With(func(c *mapper) {
+		c.rels = append(c.rels[:len(c.rels):len(c.rels)], r)
+	})
+}
+
+// relField returns the index of the field of m holding records of t
+// related by kind, or panics.
+func (m *mapper) relField(t reflect.Type, kind RelKind) []int {
+	want := []reflect.Type{t, reflect.PointerTo(t)}
+	if kind == HasMany {
+		want = []reflect.Type{reflect.SliceOf(t), reflect.SliceOf(reflect.PointerTo(t))}
+	}
+	var index []int
+	for i := range m.elem.NumField() {
+		f := m.elem.Field(i)
+		if !f.IsExported() || f.Type != want[0] && f.Type != want[1] {
+			continue
+		}
+		if _, mapped := m.ColumnFor(f.Name); mapped {
+			panic(fmt.Errorf("relation of %s to %s: field %s is mapped, tag it ignore", m.elem, t, f.Name))
+		}
+		if index != nil {
+			panic(fmt.Errorf("relation of %s to %s: more than one field of type %s", m.elem, t, f.Type))
+		}
+		index = f.Index
+	}
+	if index == nil {
+		panic(fmt.Errorf("relation of %s to %s: no field of type %s", m.elem, t, want[0]))
+	}
+	return index
+}
+
+// rel returns the relation of m to target, or panics.
+func (m *mapper) rel(target *mapper) relation {
+	for _, r := range m.rels {
+		if r.target.elem == target.elem {
+			return r
+		}
+	}
+	panic(fmt.Errorf("no relation of %s to %s, see Rel", m.elem, target.elem))
+}
+
+// JoinString returns the LEFT JOIN clause of the target table related to
+// m, as in
+//
+//	LEFT JOIN posts ON posts.user_id=users.id
+//
+// With a [WithTenantColumn] target, the join is scoped to the tenant,
+// taken as first argument, see [TenantArgs]:
+//
+//	LEFT JOIN posts ON posts.user_id=users.id AND posts.org_id=?
+//
+// It panics when no relation to target was declared, see [Rel].
+func (m *mapper) JoinString(target *mapper) string {
+	r := m.rel(target)
+	table, targetTable := m.Table(), target.Table()
+	var on string
+	if r.kind == HasMany {
+		on = targetTable + "." + r.foreignKey + "=" + table + "." + m.cols[m.pkIndexes()[0]]
+	} else {
+		on = targetTable + "." + target.cols[target.pkIndexes()[0]] + "=" + table + "." + r.foreignKey
+	}
+	if target.tenantCol != "" {
+		on += " AND " + targetTable + "." + target.tenantCol + "=" + m.placeholder(1)
+	}
+	return "LEFT JOIN " + targetTable + " ON " + on
+}
+
+// JoinSelectString returns a SELECT statement of the columns of m then
+// those of target, from the table of m joined to the target one, to be
+// completed with WHERE, ORDER BY... clauses and scanned by [ScanJoined]:
+//
+//	SELECT users.id,users.name,posts.id,posts.title FROM users LEFT JOIN posts ON posts.user_id=users.id
+//
+// A tenant scoped join takes the first placeholder, see [JoinString]. It
+// panics when m or target maps expr columns.
+func (m *mapper) JoinSelectString(target *mapper) string {
+	table := m.Table()
+	return "SELECT " + m.selectList(table+".") + m.sep() + target.selectList(target.Table()+".") +
+		" FROM " + table + " " + m.JoinString(target)
+}
+
+// ScanJoined scans rows of a [JoinSelectString] query into T records, with
+// related target records set in their relation field. Rows of the same
+// primary key make a single T, in order of first appearance, and target
+// columns all NULL make no related record. rows is closed.
+//
+//	us, err := ScanJoined[User](users, posts, rows)
+//
+// T must be the struct type m was built from, which needs a primary key.
+func ScanJoined[T any](m *mapper, target *mapper, rows Rows) ([]T, error) {
+	defer closeRows(rows)
+	r := m.rel(target)
+	pks := m.pkIndexes()
+	var res []T
+	seen := make(map[any]int)
+	rs := newRelScan(target)
+	for rows.Next() {
+		var rec T
+		addrs := rs.addrs(m.Addrs(&rec))
+		if err := rows.Scan(addrs...); err != nil {
+			return nil, err
+		}
+		key := relKey(m, reflect.ValueOf(&rec).Elem(), pks)
+		i, ok := seen[key]
+		if !ok {
+			i = len(res)
+			seen[key] = i
+			res = append(res, rec)
+		}
+		child, ok := rs.record()
+		if !ok {
+			continue
+		}
+		fv := reflect.ValueOf(&res[i]).Elem().FieldByIndex(r.field)
+		if fv.Kind() == reflect.Slice {
+			if fv.Type().Elem().Kind() != reflect.Pointer {
+				child = child.Elem()
+			}
+			fv.Set(reflect.Append(fv, child))
+		} else if fv.Kind() == reflect.Pointer {
+			fv.Set(child)
+		} else {
+			fv.Set(child.Elem())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range res {
+		if err := afterScan(context.Background(), &res[i]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// relKey returns a map key of the primary key of v.
+func relKey(m *mapper, v reflect.Value, pks []int) any {
+	if len(pks) == 1 {
+		return v.FieldByIndex(m.fields[pks[0]].Index).Interface()
+	}
+	key := ""
+	for _, j := range pks {
+		key += fmt.Sprintf("%#v\x00", v.FieldByIndex(m.fields[j].Index).Interface())
+	}
+	return key
+}
+
+// relScan scans the target columns of joined rows into target records,
+// through the field scanners of the target mapper, as [Scan] does.
+type relScan struct {
+	target  *mapper
+	child   reflect.Value   // record of the current row
+	holders []reflect.Value // of fields scanned as they are, nil for converted ones
+	found   bool            // whether a converted field was not NULL
+}
+
+func newRelScan(target *mapper) *relScan {
+	return &relScan{target: target, holders: make([]reflect.Value, len(target.fields))}
+}
+
+// addrs appends the scan destinations of the target columns of a row to
+// addrs.
+func (s *relScan) addrs(addrs []any) []any {
+	s.child = reflect.New(s.target.elem)
+	s.found = false
+	for j, f := range s.target.fields {
+		s.holders[j] = reflect.Value{}
+		a := s.target.fieldAddr(s.child.Elem(), nil, j)
+		if sc, ok := a.(sql.Scanner); ok && reflect.TypeOf(a) != reflect.PointerTo(f.Type) {
+			addrs = append(addrs, nullScanner{sc, &s.found})
+			continue
+		}
+		// Scanned into a pointer, NULL being nil.
+		s.holders[j] = reflect.New(reflect.PointerTo(f.Type))
+		addrs = append(addrs, s.holders[j].Interface())
+	}
+	return addrs
+}
+
+// record returns a pointer to the target record of the current row,
+// unless its columns are all NULL.
+func (s *relScan) record() (reflect.Value, bool) {
+	found := s.found
+	for j, h := range s.holders {
+		if !h.IsValid() {
+			continue
+		}
+		if p := h.Elem(); !p.IsNil() {
+			s.child.Elem().FieldByIndex(s.target.fields[j].Index).Set(p.Elem())
+			found = true
+		}
+	}
+	return s.child, found
+}
+
+// nullScanner scans into s, leaving its field alone on NULL, and tells
+// found about others.
+type nullScanner struct {
+	s     sql.Scanner
+	found *bool
+}
+
+func (n nullScanner) Scan(src any) error {
+	if src == nil {
+		return nil
+	}
+	*n.found = true
+	return n.s.Scan(src)
+}
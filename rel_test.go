@@ -0,0 +1,116 @@
+package mapper
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type relPost struct {
+	ID     int64    `mapper:"id,pk"`
+	Title  string   `mapper:"title"`
+	UserID int64    `mapper:"user_id"`
+	Author *relUser `mapper:",ignore"`
+}
+
+type relUser struct {
+	ID    int64     `mapper:"id,pk"`
+	Name  string    `mapper:"name"`
+	Posts []relPost `mapper:",ignore"`
+}
+
+func TestRel(t *testing.T) {
+	is := is.New(t)
+	posts := Mapper(relPost{}, "*").SetOptions(WithTable("posts"))
+	users := Mapper(relUser{}, "*").SetOptions(WithTable("users")).Rel(posts, "user_id", HasMany)
+	postAuthors := posts.Rel(users, "user_id", BelongsTo)
+
+	is.Equal(users.JoinString(posts), "LEFT JOIN posts ON posts.user_id=users.id")
+	is.Equal(postAuthors.JoinString(users), "LEFT JOIN users ON users.id=posts.user_id")
+	is.Equal(users.JoinSelectString(posts), "SELECT users.id,users.name,posts.id,posts.title,posts.user_id FROM users LEFT JOIN posts ON posts.user_id=users.id")
+
+	rows := queryFake(t, []string{"id", "name", "id", "title", "user_id"},
+		[]driver.Value{int64(1), "ann", int64(10), "a", int64(1)},
+		[]driver.Value{int64(2), "bob", nil, nil, nil},
+		[]driver.Value{int64(1), "ann", int64(11), "b", int64(1)},
+	)
+	us, err := ScanJoined[relUser](users, posts, rows)
+	is.NoErr(err)
+	is.Equal(len(us), 2)
+	is.Equal(us[0].Posts, []relPost{{ID: 10, Title: "a", UserID: 1}, {ID: 11, Title: "b", UserID: 1}})
+	is.Equal(us[1].Name, "bob")
+	is.Equal(len(us[1].Posts), 0)
+
+	rows = queryFake(t, []string{"id", "title", "user_id", "id", "name"},
+		[]driver.Value{int64(10), "a", int64(1), int64(1), "ann"},
+	)
+	ps, err := ScanJoined[relPost](postAuthors, users, rows)
+	is.NoErr(err)
+	is.Equal(ps[0].Author.Name, "ann")
+
+	defer func() {
+		is.True(recover() != nil) // no such column
+	}()
+	users.Rel(posts, "owner_id", HasMany)
+}
+
+func TestRelExpr(t *testing.T) {
+	is := is.New(t)
+	type Stats struct {
+		ID     int64   `mapper:"id,pk"`
+		Posts  int64   `mapper:"posts,expr=count(*)"`
+		Author relUser `mapper:",ignore"`
+	}
+	users := Mapper(relUser{}, "*").SetOptions(WithTable("users"))
+	stats := Mapper(Stats{}, "*").SetOptions(WithTable("stats")).Rel(users, "id", BelongsTo)
+	defer func() {
+		is.True(recover() != nil) // count(*) cannot be qualified
+	}()
+	stats.JoinSelectString(users)
+}
+
+func TestRelTenant(t *testing.T) {
+	is := is.New(t)
+	posts := Mapper(relPost{}, "*").SetOptions(WithTable("posts"), WithTenantColumn("org_id", func(ctx context.Context) any {
+		return ctx.Value(orgKey{})
+	}))
+	users := Mapper(relUser{}, "*").SetOptions(WithTable("users")).Rel(posts, "user_id", HasMany)
+
+	is.Equal(users.JoinString(posts), "LEFT JOIN posts ON posts.user_id=users.id AND posts.org_id=?")
+	args, err := posts.TenantArgs(context.WithValue(context.Background(), orgKey{}, int64(7)), "ann")
+	is.NoErr(err)
+	is.Equal(args, []any{int64(7), "ann"})
+}
+
+type relNote struct {
+	ID     string `mapper:"id,pk,uuid=binary"`
+	UserID int64  `mapper:"user_id"`
+	Body   string `mapper:"body,encrypted"`
+}
+
+type relReader struct {
+	ID    int64     `mapper:"id,pk"`
+	Notes []relNote `mapper:",ignore"`
+}
+
+func TestRelConverted(t *testing.T) {
+	is := is.New(t)
+	k, err := NewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	is.NoErr(err)
+	notes := Mapper(relNote{}, "*").SetOptions(WithTable("notes"), WithCipher(k))
+	readers := Mapper(relReader{}, "*").SetOptions(WithTable("readers")).Rel(notes, "user_id", HasMany)
+
+	const id = "6ccd780c-baba-1026-9564-5b8c656024db"
+	vals := notes.Values(relNote{id, 1, "secret"})
+	rows := queryFake(t, []string{"id", "id", "user_id", "body"},
+		[]driver.Value{int64(1), vals[0], int64(1), vals[2]},
+		[]driver.Value{int64(2), nil, nil, nil},
+	)
+	rs, err := ScanJoined[relReader](readers, notes, rows)
+	is.NoErr(err)
+	is.Equal(rs[0].Notes, []relNote{{id, 1, "secret"}})
+	is.Equal(len(rs[1].Notes), 0)
+}
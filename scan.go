@@ -0,0 +1,221 @@
+package mapper
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Rows is the part of a result set the scan helpers need. It is implemented
+// by *sql.Rows as well as pgx.Rows, so pgx users need no database/sql
+// compatibility layer. Rows are closed by the helpers if they have a Close
+// method.
+type Rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+}
+
+// Row is a single row result, as *sql.Row and pgx.Row.
+type Row interface {
+	Scan(dest ...any) error
+}
+
+// closeRows closes rows if it can be, whatever its Close signature.
+func closeRows(rows Rows) {
+	switch r := rows.(type) {
+	case interface{ Close() error }:
+		r.Close()
+	case interface{ Close() }:
+		r.Close()
+	}
+}
+
+// ScanRow scans row into a new T.
+//
+//	u, err := ScanRow[User](users, db.QueryRow(`SELECT `+users.ColumnsString()+` FROM users WHERE id=?`, id))
+//
+// T must be the struct type m was built from.
+func ScanRow[T any](m *mapper, row Row) (rec T, err error) {
+	ctx, end := m.startScan(context.Background())
+	defer func() {
+		n := int64(1)
+		if err != nil {
+			n = 0
+		}
+		end(n, err)
+	}()
+	if err := row.Scan(m.Addrs(&rec)...); err != nil {
+		return rec, err
+	}
+	return rec, afterScan(ctx, &rec)
+}
+
+// streamBuffer is the capacity of the channel returned by [Stream].
+const streamBuffer = 64
+
+// Stream scans rows into values of type T in a separate goroutine and sends
+// them on a buffered channel, so large result sets can be consumed by a
+// pipeline of workers without being materialized in memory.
+//
+// The record channel is closed once rows are exhausted, ctx is cancelled or
+// an error occurs. The error channel then yields at most one error and gets
+// closed. rows is always closed by Stream.
+//
+//	recs, errc := Stream[Record](ctx, ma, rows)
+//	for r := range recs {
+//	  ...
+//	}
+//	if err := <-errc; err != nil {
+//	  ...
+//	}
+//
+// T must be the struct type m was built from.
+func Stream[T any](ctx context.Context, m *mapper, rows Rows) (<-chan T, <-chan error) {
+	out := make(chan T, streamBuffer)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(out)
+		defer closeRows(rows)
+		ctx, end := m.startScan(ctx)
+		var n int64
+		var err error
+		defer func() {
+			if err != nil {
+				errc <- err
+			}
+			end(n, err)
+		}()
+		for rows.Next() {
+			var rec T
+			if err = m.scan(rows, &rec); err != nil {
+				return
+			}
+			if err = afterScan(ctx, &rec); err != nil {
+				return
+			}
+			select {
+			case out <- rec:
+				n++
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			}
+		}
+		err = rows.Err()
+	}()
+	return out, errc
+}
+
+// ForEach scans every row into a single T and calls fn with it. The same
+// record is reused between iterations, so fn must not retain rec: copy it
+// if needed. Fields not mapped by m keep whatever fn left in them.
+//
+// Iteration stops at the first error returned by fn or by the scan, which
+// ForEach then returns. rows is always closed.
+//
+// T must be the struct type m was built from.
+func ForEach[T any](m *mapper, rows Rows, fn func(rec *T) error) (err error) {
+	defer closeRows(rows)
+	ctx, end := m.startScan(context.Background())
+	var n int64
+	defer func() {
+		end(n, err)
+	}()
+	var rec T
+	addrs := m.Addrs(&rec)
+	for rows.Next() {
+		if err := rows.Scan(addrs...); err != nil {
+			return err
+		}
+		if err := afterScan(ctx, &rec); err != nil {
+			return err
+		}
+		n++
+		if err := fn(&rec); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// addrsPool holds Addrs buffers shared by the scan helpers.
+var addrsPool = sync.Pool{New: func() any { return new([]any) }}
+
+// scan the current row of rows into dest, using a pooled Addrs buffer.
+func (m *mapper) scan(rows Rows, dest any) error {
+	buf := addrsPool.Get().(*[]any)
+	*buf = m.AddrsInto(dest, *buf)
+	err := rows.Scan(*buf...)
+	clear(*buf) // do not keep dest alive from the pool
+	addrsPool.Put(buf)
+	return err
+}
+
+// AllIndexed scans every row into a T, indexed by primary key, as for
+// lookup tables:
+//
+//	countries, err := AllIndexed[string, Country](countries, rows)
+//
+// K must be the type of the single field tagged pk. Later rows replace
+// earlier ones of the same key. rows is always closed.
+//
+// T must be the struct type m was built from.
+func AllIndexed[K comparable, T any](m *mapper, rows Rows) (map[K]T, error) {
+	pks := m.pkIndexesE()
+	if len(pks) != 1 {
+		closeRows(rows)
+		return nil, fmt.Errorf("AllIndexed needs a single column primary key, %s has %d", m.elem, len(pks))
+	}
+	res := make(map[K]T)
+	err := allKeyed(m, rows, pks[0], func(k K, rec T) { res[k] = rec })
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// AllGrouped scans every row into a T, grouped by the value of column col,
+// in row order:
+//
+//	byCountry, err := AllGrouped[string, User](users, rows, "country")
+//
+// K must be the type of the field mapped to col. rows is always closed.
+//
+// T must be the struct type m was built from.
+func AllGrouped[K comparable, T any](m *mapper, rows Rows, col string) (map[K][]T, error) {
+	j := fieldSlice(m.cols).index(col)
+	if j == -1 {
+		closeRows(rows)
+		return nil, &ErrMissingColumns{Cols: []string{col}}
+	}
+	res := make(map[K][]T)
+	err := allKeyed(m, rows, j, func(k K, rec T) { res[k] = append(res[k], rec) })
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// allKeyed scans every row into a T, and calls fn with it and the value of
+// its column j.
+func allKeyed[K comparable, T any](m *mapper, rows Rows, j int, fn func(K, T)) error {
+	defer closeRows(rows)
+	if t := m.fields[j].Type; t != reflect.TypeFor[K]() {
+		return fmt.Errorf("column %s is a %s, not a %s", m.cols[j], t, reflect.TypeFor[K]())
+	}
+	for rows.Next() {
+		var rec T
+		if err := m.scan(rows, &rec); err != nil {
+			return err
+		}
+		if err := afterScan(context.Background(), &rec); err != nil {
+			return err
+		}
+		k := reflect.ValueOf(&rec).Elem().FieldByIndex(m.fields[j].Index).Interface().(K)
+		fn(k, rec)
+	}
+	return rows.Err()
+}
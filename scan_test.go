@@ -0,0 +1,162 @@
+package mapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type scanRecord struct {
+	ID   int64
+	Name string
+}
+
+func TestStream(t *testing.T) {
+	is := is.New(t)
+	rows := queryFake(t, []string{"id", "name"},
+		[]driver.Value{int64(1), "a"},
+		[]driver.Value{int64(2), "b"},
+	)
+
+	recs, errc := Stream[scanRecord](context.Background(), Mapper(scanRecord{}, "*"), rows)
+	var got []scanRecord
+	for r := range recs {
+		got = append(got, r)
+	}
+	is.NoErr(<-errc)
+	is.Equal(got, []scanRecord{{1, "a"}, {2, "b"}})
+}
+
+func TestStreamCancel(t *testing.T) {
+	is := is.New(t)
+	values := make([][]driver.Value, streamBuffer+10)
+	for i := range values {
+		values[i] = []driver.Value{int64(i), "x"}
+	}
+	rows := queryFake(t, []string{"id", "name"}, values...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	recs, errc := Stream[scanRecord](ctx, Mapper(scanRecord{}, "*"), rows)
+	<-recs
+	cancel()
+	is.Equal(<-errc, context.Canceled)
+	for range recs {
+	}
+}
+
+func TestForEach(t *testing.T) {
+	is := is.New(t)
+	rows := queryFake(t, []string{"id", "name"},
+		[]driver.Value{int64(1), "a"},
+		[]driver.Value{int64(2), "b"},
+	)
+
+	var got []scanRecord
+	var prev *scanRecord
+	err := ForEach(Mapper(scanRecord{}, "*"), rows, func(rec *scanRecord) error {
+		if prev != nil {
+			is.Equal(prev, rec) // buffer is reused
+		}
+		prev = rec
+		got = append(got, *rec)
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(got, []scanRecord{{1, "a"}, {2, "b"}})
+}
+
+func TestForEachStop(t *testing.T) {
+	is := is.New(t)
+	rows := queryFake(t, []string{"id", "name"},
+		[]driver.Value{int64(1), "a"},
+		[]driver.Value{int64(2), "b"},
+	)
+
+	stop := errors.New("stop")
+	n := 0
+	err := ForEach(Mapper(scanRecord{}, "*"), rows, func(rec *scanRecord) error {
+		n++
+		return stop
+	})
+	is.Equal(err, stop)
+	is.Equal(n, 1)
+}
+
+// sliceRows are Rows without Close, as pgx.Rows Close has no result.
+type sliceRows struct {
+	recs   []scanRecord
+	closed bool
+}
+
+func (r *sliceRows) Next() bool { return len(r.recs) > 0 }
+func (r *sliceRows) Err() error { return nil }
+func (r *sliceRows) Close()     { r.closed = true }
+func (r *sliceRows) Scan(dest ...any) error {
+	*dest[0].(*int64) = r.recs[0].ID
+	*dest[1].(*string) = r.recs[0].Name
+	r.recs = r.recs[1:]
+	return nil
+}
+
+func TestForEachRows(t *testing.T) {
+	is := is.New(t)
+	rows := &sliceRows{recs: []scanRecord{{1, "a"}, {2, "b"}}}
+
+	var got []scanRecord
+	err := ForEach(Mapper(scanRecord{}, "*"), rows, func(rec *scanRecord) error {
+		got = append(got, *rec)
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(got, []scanRecord{{1, "a"}, {2, "b"}})
+	is.True(rows.closed)
+}
+
+func TestScanRow(t *testing.T) {
+	is := is.New(t)
+	rows := &sliceRows{recs: []scanRecord{{1, "a"}}}
+
+	rec, err := ScanRow[scanRecord](Mapper(scanRecord{}, "*"), rows)
+	is.NoErr(err)
+	is.Equal(rec, scanRecord{1, "a"})
+}
+
+func TestAllIndexed(t *testing.T) {
+	is := is.New(t)
+	type Country struct {
+		Code string `mapper:"code,pk"`
+		Name string `mapper:"name"`
+	}
+	m := Mapper(Country{}, "*")
+	rows := queryFake(t, []string{"code", "name"},
+		[]driver.Value{"fr", "France"},
+		[]driver.Value{"it", "Italy"},
+	)
+	got, err := AllIndexed[string, Country](m, rows)
+	is.NoErr(err)
+	is.Equal(got, map[string]Country{"fr": {"fr", "France"}, "it": {"it", "Italy"}})
+
+	_, err = AllIndexed[int64, Country](m, queryFake(t, []string{"code", "name"}))
+	is.True(err != nil) // wrong key type
+	_, err = AllIndexed[int64, scanRecord](Mapper(scanRecord{}, "*"), queryFake(t, []string{"id", "name"}))
+	is.True(err != nil) // no pk
+}
+
+func TestAllGrouped(t *testing.T) {
+	is := is.New(t)
+	rows := queryFake(t, []string{"id", "name"},
+		[]driver.Value{int64(1), "a"},
+		[]driver.Value{int64(2), "b"},
+		[]driver.Value{int64(3), "a"},
+	)
+	got, err := AllGrouped[string, scanRecord](Mapper(scanRecord{}, "*"), rows, "name")
+	is.NoErr(err)
+	is.Equal(got, map[string][]scanRecord{"a": {{1, "a"}, {3, "a"}}, "b": {{2, "b"}}})
+
+	_, err = AllGrouped[string, scanRecord](Mapper(scanRecord{}, "*"), queryFake(t, []string{"id", "name"}), "nom")
+	var missing *ErrMissingColumns
+	is.True(errors.As(err, &missing))
+}
@@ -0,0 +1,114 @@
+package mapper
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ScanMap sets the fields of dest mapped by the columns of vals, the
+// inverse of [ValuesMap], for values decoded from JSON, YAML and the like
+// rather than read from a database:
+//
+//	err := users.ScanMap(map[string]any{"id": 1.0, "name": "alice"}, &u)
+//
+// Values go through the field conversions of [Addrs], as for JSON, uuid or
+// encrypted fields, numbers are converted when exact, strings are parsed as
+// by [ReadCSV], and maps and slices are decoded as JSON. Every column of
+// vals must be mapped, mapped columns absent from vals are left untouched.
+func (m *mapper) ScanMap(vals map[string]any, dest any) error {
+	v, p, err := m.pointedStruct(dest)
+	if err != nil {
+		return err
+	}
+	for col, val := range vals {
+		j := fieldSlice(m.cols).index(col)
+		if j == -1 {
+			return fmt.Errorf("missing destination name %s in %s", col, m.elem)
+		}
+		if err := assign(m.fieldAddr(v, p, j), val); err != nil {
+			return fmt.Errorf("%s: %w", col, err)
+		}
+	}
+	return nil
+}
+
+// assign sets the field at addr, as returned by fieldAddr, to val.
+func assign(addr, val any) error {
+	if s, ok := addr.(sql.Scanner); ok {
+		val, err := driverValue(val)
+		if err != nil {
+			return err
+		}
+		return s.Scan(val)
+	}
+	return assignValue(reflect.ValueOf(addr).Elem(), val)
+}
+
+// driverValue returns val as one of the types drivers return.
+func driverValue(val any) (any, error) {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Map, reflect.Struct, reflect.Slice:
+		if b, ok := val.([]byte); ok {
+			return b, nil
+		}
+		if t, ok := val.(time.Time); ok {
+			return t, nil
+		}
+		return json.Marshal(val)
+	}
+	return val, nil
+}
+
+// assignValue sets v to val, converted to its type.
+func assignValue(v reflect.Value, val any) error {
+	if val == nil {
+		v.SetZero()
+		return nil
+	}
+	if s, ok := val.(string); ok {
+		return setString(v, s)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return assignValue(v.Elem(), val)
+	}
+	rv := reflect.ValueOf(val)
+	switch {
+	case rv.Type().AssignableTo(v.Type()):
+		v.Set(rv)
+		return nil
+	case isNumber(rv.Kind()) && isNumber(v.Kind()):
+		c := rv.Convert(v.Type())
+		if back := c.Convert(rv.Type()); back.Interface() != rv.Interface() {
+			return fmt.Errorf("cannot convert %v to %s exactly", val, v.Type())
+		}
+		v.Set(c)
+		return nil
+	case rv.Kind() == reflect.Bool && v.Kind() == reflect.Bool:
+		v.SetBool(rv.Bool())
+		return nil
+	case rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, v.Addr().Interface())
+	}
+	return fmt.Errorf("cannot convert %T to %s", val, v.Type())
+}
+
+func isNumber(k reflect.Kind) bool {
+	return reflect.Int <= k && k <= reflect.Float64 && k != reflect.Uintptr
+}
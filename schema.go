@@ -0,0 +1,414 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SchemaReport lists the differences between a mapper and a database table.
+type SchemaReport struct {
+	Table    string
+	Missing  []string         // mapped columns absent from the table
+	Extra    []string         // table columns not mapped, which is fine
+	Mistyped []ColumnMismatch // mapped columns whose type cannot be scanned
+
+	Generated    []string // generated columns not tagged so, failing writes
+	NotGenerated []string // columns tagged generated, never written, which are not
+}
+
+// ColumnMismatch describes a mapped column whose database type is not
+// compatible with its struct field, or differs from the type= hint of its
+// tag.
+type ColumnMismatch struct {
+	Column string
+	GoType reflect.Type
+	DBType string
+	Hint   string // type= tag option, if any
+}
+
+// OK tells whether every mapped column exists with a compatible type, and
+// is tagged generated when the database generates it.
+func (r *SchemaReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Mistyped) == 0 && len(r.Generated) == 0 && len(r.NotGenerated) == 0
+}
+
+// Err returns nil when the report is [OK], or an error describing it.
+func (r *SchemaReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "table %s does not match mapper:", r.Table)
+	if len(r.Missing) > 0 {
+		fmt.Fprintf(&b, " missing columns %s;", strings.Join(r.Missing, ","))
+	}
+	for _, c := range r.Mistyped {
+		if c.Hint != "" {
+			fmt.Fprintf(&b, " column %s is %s, not %s;", c.Column, c.DBType, c.Hint)
+		} else {
+			fmt.Fprintf(&b, " column %s is %s, not compatible with %s;", c.Column, c.DBType, c.GoType)
+		}
+	}
+	if len(r.Generated) > 0 {
+		fmt.Fprintf(&b, " generated columns %s not tagged generated;", strings.Join(r.Generated, ","))
+	}
+	if len(r.NotGenerated) > 0 {
+		fmt.Fprintf(&b, " columns %s tagged generated are not;", strings.Join(r.NotGenerated, ","))
+	}
+	return errors.New(strings.TrimSuffix(b.String(), ";"))
+}
+
+// ValidateSchema checks table in db against m: every mapped column must
+// exist with a type compatible with its field, or the type given by the
+// type= tag option, and be tagged generated if and only if the database
+// generates it. Call it at startup to fail fast:
+//
+//	report, err := users.ValidateSchema(ctx, db, "users")
+//	if err == nil {
+//	  err = report.Err()
+//	}
+//
+// Columns are listed from information_schema, or pragma_table_xinfo for
+// SQLite, so m MUST have a [Dialect]. table may be qualified with a schema,
+// as in "public.users". The returned error only reports failing queries.
+func (m *mapper) ValidateSchema(ctx context.Context, db Queryer, table string) (*SchemaReport, error) {
+	table = m.tableName(table)
+	dbCols, err := m.tableColumns(ctx, db, table)
+	if err != nil {
+		return nil, err
+	}
+	r := &SchemaReport{Table: table}
+	for j, col := range m.cols {
+		if m.isExpr(j) {
+			continue
+		}
+		tc, ok := dbCols[col]
+		if !ok {
+			r.Missing = append(r.Missing, col)
+			continue
+		}
+		if !m.columnMatches(j, tc.Type) {
+			r.Mistyped = append(r.Mistyped, ColumnMismatch{col, m.fields[j].Type, tc.Type, m.fields[j].opts.Get("type")})
+		}
+		switch {
+		case tc.Generated && !m.isGenerated(j):
+			r.Generated = append(r.Generated, col)
+		case !tc.Generated && m.isGenerated(j):
+			r.NotGenerated = append(r.NotGenerated, col)
+		}
+		delete(dbCols, col)
+	}
+	for col := range dbCols {
+		r.Extra = append(r.Extra, col)
+	}
+	slices.Sort(r.Extra)
+	return r, nil
+}
+
+// SchemaDiff is a [SchemaReport] able to suggest the statements migrating
+// the table to the mapper.
+type SchemaDiff struct {
+	SchemaReport
+	m *mapper
+}
+
+// DiffSchema compares table in db with m like [ValidateSchema] does, and
+// returns the differences found:
+//
+//	diff, err := users.DiffSchema(ctx, db, "users")
+//	...
+//	stmts, err := diff.AlterStatements(false)
+//	fmt.Println(strings.Join(stmts, ";\n"))
+func (m *mapper) DiffSchema(ctx context.Context, db Queryer, table string) (*SchemaDiff, error) {
+	r, err := m.ValidateSchema(ctx, db, table)
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaDiff{SchemaReport: *r, m: m}, nil
+}
+
+// AlterStatements returns the ALTER TABLE statements adding missing columns
+// and changing the type of mistyped ones, as [CreateTableString] would
+// define them. Extra columns are dropped when dropExtra is set.
+//
+// Review them before running: adding a NOT NULL column without default to
+// a table holding rows fails, and changing types may lose data. SQLite
+// cannot change column types, mistyped columns are an error there. The
+// table, and extra columns when dropped, MUST be safe identifiers, see
+// [ErrUnsafeColumn].
+func (d *SchemaDiff) AlterStatements(dropExtra bool) ([]string, error) {
+	m := d.m
+	if err := checkColumn(d.Table, nil); err != nil {
+		return nil, err
+	}
+	var stmts []string
+	for _, col := range d.Missing {
+		def, err := m.columnDef(fieldSlice(m.cols).index(col))
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, "ALTER TABLE "+d.Table+" ADD COLUMN "+def)
+	}
+	for _, c := range d.Mistyped {
+		j := fieldSlice(m.cols).index(c.Column)
+		switch m.Dialect {
+		case Postgres:
+			typ, err := m.columnTypeOf(j)
+			if err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, "ALTER TABLE "+d.Table+" ALTER COLUMN "+c.Column+" TYPE "+typ)
+		case MySQL:
+			def, err := m.columnDef(j)
+			if err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, "ALTER TABLE "+d.Table+" MODIFY COLUMN "+def)
+		default:
+			return nil, fmt.Errorf("mapper: cannot change the type of column %s in dialect %q", c.Column, m.Dialect)
+		}
+	}
+	if dropExtra {
+		for _, col := range d.Extra {
+			if err := checkColumn(col, nil); err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, "ALTER TABLE "+d.Table+" DROP COLUMN "+col)
+		}
+	}
+	return stmts, nil
+}
+
+// tableColumns returns the columns of table, by name.
+func (m *mapper) tableColumns(ctx context.Context, db Queryer, table string) (map[string]TableColumn, error) {
+	tcs, err := TableColumns(ctx, db, m.Dialect, table)
+	if err != nil {
+		return nil, err
+	}
+	cols := make(map[string]TableColumn, len(tcs))
+	for _, tc := range tcs {
+		cols[tc.Name] = tc
+	}
+	return cols, nil
+}
+
+// TableColumn describes a column of a database table.
+type TableColumn struct {
+	Name      string
+	Type      string // lower cased database type, as bigint or varchar(20)
+	Nullable  bool
+	Generated bool // computed by the database, as STORED or VIRTUAL columns
+}
+
+// TableColumns lists the columns of table in db, in table order. They are
+// read from information_schema, or pragma_table_xinfo for SQLite, as
+// dialect d tells. table may be qualified with a schema, as in
+// "public.users".
+func TableColumns(ctx context.Context, db Queryer, d Dialect, table string) ([]TableColumn, error) {
+	schema, name, qualified := strings.Cut(table, ".")
+	if !qualified {
+		name = table
+	}
+	var rows *sql.Rows
+	var err error
+	switch d {
+	case Postgres:
+		if qualified {
+			rows, err = db.QueryContext(ctx, `SELECT column_name, data_type, is_nullable = 'YES', is_generated FROM information_schema.columns WHERE table_schema = $1 AND table_name = $2 ORDER BY ordinal_position`, schema, name)
+		} else {
+			rows, err = db.QueryContext(ctx, `SELECT column_name, data_type, is_nullable = 'YES', is_generated FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position`, name)
+		}
+	case MySQL:
+		if qualified {
+			rows, err = db.QueryContext(ctx, `SELECT column_name, column_type, is_nullable = 'YES', extra FROM information_schema.columns WHERE table_schema = ? AND table_name = ? ORDER BY ordinal_position`, schema, name)
+		} else {
+			rows, err = db.QueryContext(ctx, `SELECT column_name, column_type, is_nullable = 'YES', extra FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position`, name)
+		}
+	case SQLite:
+		if qualified {
+			rows, err = db.QueryContext(ctx, `SELECT name, type, "notnull" = 0, hidden FROM pragma_table_xinfo(?, ?) WHERE hidden <> 1 ORDER BY cid`, name, schema)
+		} else {
+			rows, err = db.QueryContext(ctx, `SELECT name, type, "notnull" = 0, hidden FROM pragma_table_xinfo(?) WHERE hidden <> 1 ORDER BY cid`, name)
+		}
+	default:
+		return nil, fmt.Errorf("mapper: no schema support for dialect %q, see WithDialect", d)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []TableColumn
+	for rows.Next() {
+		var c TableColumn
+		var gen sql.NullString
+		if err := rows.Scan(&c.Name, &c.Type, &c.Nullable, &gen); err != nil {
+			return nil, err
+		}
+		c.Type = strings.ToLower(c.Type)
+		c.Generated = generatedColumn(d, gen.String)
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+// generatedColumn tells whether a column is generated from the generation
+// metadata of dialect d: is_generated for Postgres, extra for MySQL, which
+// is DEFAULT_GENERATED for mere expression defaults, and hidden for SQLite.
+func generatedColumn(d Dialect, gen string) bool {
+	switch d {
+	case Postgres:
+		return gen == "ALWAYS"
+	case MySQL:
+		gen = strings.ToUpper(gen)
+		return strings.Contains(gen, "VIRTUAL GENERATED") || strings.Contains(gen, "STORED GENERATED")
+	case SQLite:
+		return gen == "2" || gen == "3"
+	}
+	return false
+}
+
+// columnMatches tells whether column j can have dbType, lower cased: the
+// type= tag option if any, or else a type compatible with its field.
+func (m *mapper) columnMatches(j int, dbType string) bool {
+	if hint := m.fields[j].opts.Get("type"); hint != "" {
+		return sameType(hint, dbType)
+	}
+	if m.fields[j].uuid != uuidNone {
+		return compatibleType(reflect.TypeFor[[]byte](), dbType, m.Dialect)
+	}
+	if m.fields[j].flags != nil {
+		return compatibleType(reflect.TypeFor[int64](), dbType, m.Dialect)
+	}
+	return compatibleType(m.fields[j].Type, dbType, m.Dialect)
+}
+
+// typeAliases maps type names to the one databases report.
+var typeAliases = map[string]string{
+	"varchar":     "character varying",
+	"char":        "character",
+	"bpchar":      "character",
+	"int":         "integer",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"int2":        "smallint",
+	"serial":      "integer",
+	"bigserial":   "bigint",
+	"float8":      "double precision",
+	"double":      "double precision",
+	"float4":      "real",
+	"bool":        "boolean",
+	"decimal":     "numeric",
+	"timestamptz": "timestamp with time zone",
+	"timestamp":   "timestamp without time zone",
+	"timetz":      "time with time zone",
+}
+
+// sameType tells whether the declared type hint and dbType, as reported by
+// the database, are the same. Parameters like the 12,2 of numeric(12,2)
+// only count when both have them, as information_schema does not always
+// report them.
+func sameType(hint, dbType string) bool {
+	canon := func(s string) (string, string) {
+		s = strings.Join(strings.Fields(strings.ToLower(s)), " ")
+		base, params, _ := strings.Cut(s, "(")
+		base = strings.TrimSpace(base)
+		if a, ok := typeAliases[base]; ok {
+			base = a
+		}
+		return base, strings.ReplaceAll(params, " ", "")
+	}
+	hb, hp := canon(hint)
+	db, dp := canon(dbType)
+	return hb == db && (hp == "" || dp == "" || hp == dp)
+}
+
+var (
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	timeType    = reflect.TypeFor[time.Time]()
+	bytesType   = reflect.TypeFor[[]byte]()
+)
+
+// compatibleType tells whether a column of dbType, lower cased, can be
+// scanned into a field of type t. It errs on the permissive side: types
+// implementing [sql.Scanner], strings and byte slices accept anything.
+func compatibleType(t reflect.Type, dbType string, d Dialect) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(scannerType) {
+		return true
+	}
+	has := func(words ...string) bool {
+		for _, w := range words {
+			if strings.Contains(dbType, w) {
+				return true
+			}
+		}
+		return false
+	}
+	if d == SQLite && has("text", "char", "clob") && t == timeType {
+		return true // SQLite stores dates as text
+	}
+	switch {
+	case t == timeType:
+		return has("date", "time")
+	case t == bytesType, t.Kind() == reflect.String:
+		return true
+	case t == ipType, t == addrType, t == prefixType, t == macType:
+		return has("inet", "cidr", "macaddr", "char", "text", "binary", "blob")
+	case t == ratType, t == bigIntType:
+		return has("numeric", "decimal", "int", "money", "char", "text") || (t == ratType && has("real", "double", "float"))
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return has("bool", "bit", "tinyint") || (d == SQLite && has("int"))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return has("int", "serial", "numeric", "decimal", "year", "bit")
+	case reflect.Float32, reflect.Float64:
+		return has("real", "double", "float", "numeric", "decimal", "money", "int")
+	}
+	return false
+}
+
+// CheckTypes verifies that the columns of rows are the mapped ones, in the
+// same order, and that their database types can be scanned into the mapped
+// fields. Call it before iterating to get per-column diagnostics instead of
+// a driver Scan error midway. Types are only checked when the driver
+// reports them.
+func (m *mapper) CheckTypes(rows *sql.Rows) error {
+	cts, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	if len(cts) != len(m.cols) {
+		errs = append(errs, fmt.Errorf("rows have %d columns, mapper has %d", len(cts), len(m.cols)))
+	}
+	for j, ct := range cts {
+		if j >= len(m.cols) {
+			break
+		}
+		if !strings.EqualFold(ct.Name(), m.cols[j]) {
+			errs = append(errs, fmt.Errorf("column %d is %s, mapper expects %s", j, ct.Name(), m.cols[j]))
+			continue
+		}
+		dbType := strings.ToLower(ct.DatabaseTypeName())
+		if dbType == "" || m.columnMatches(j, dbType) {
+			continue
+		}
+		if hint := m.fields[j].opts.Get("type"); hint != "" {
+			errs = append(errs, fmt.Errorf("column %s is %s, not %s", m.cols[j], dbType, hint))
+		} else {
+			errs = append(errs, fmt.Errorf("column %s is %s, not compatible with %s", m.cols[j], dbType, m.fields[j].Type))
+		}
+	}
+	return errors.Join(errs...)
+}
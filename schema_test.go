@@ -0,0 +1,159 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestValidateSchema(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		ID      int64
+		Name    string
+		Created time.Time
+		Score   float64
+		Gone    bool
+	}
+	db := sql.OpenDB(&fakeConnector{
+		cols: []string{"column_name", "data_type", "nullable", "generated"},
+		rows: [][]driver.Value{
+			{"id", "bigint", false, false},
+			{"name", "text", false, false},
+			{"created", "timestamp with time zone", false, false},
+			{"score", "text", false, false},
+			{"note", "text", true, false},
+		},
+	})
+	defer db.Close()
+
+	r, err := Mapper(M{}, "*").SetOptions(WithDialect(Postgres)).ValidateSchema(context.Background(), db, "m")
+	is.NoErr(err)
+	is.True(!r.OK())
+	is.Equal(r.Missing, []string{"gone"})
+	is.Equal(r.Extra, []string{"note"})
+	is.Equal(len(r.Mistyped), 1)
+	is.Equal(r.Mistyped[0].Column, "score")
+	is.Equal(r.Err().Error(), "table m does not match mapper: missing columns gone; column score is text, not compatible with float64")
+
+	_, err = Mapper(M{}, "*").ValidateSchema(context.Background(), db, "m")
+	is.True(err != nil) // no dialect
+}
+
+func TestDiffSchema(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		ID    int64
+		Score float64 `mapper:"score,notnull"`
+		Gone  bool    `mapper:"gone,default=false"`
+	}
+	db := sql.OpenDB(&fakeConnector{
+		cols: []string{"column_name", "data_type", "nullable", "generated"},
+		rows: [][]driver.Value{
+			{"id", "bigint", false, false},
+			{"score", "text", false, false},
+			{"note", "text", true, false},
+		},
+	})
+	defer db.Close()
+
+	diff, err := Mapper(M{}, "*").SetOptions(WithDialect(Postgres)).DiffSchema(context.Background(), db, "m")
+	is.NoErr(err)
+	is.Equal(diff.Missing, []string{"gone"})
+	stmts, err := diff.AlterStatements(true)
+	is.NoErr(err)
+	is.Equal(stmts, []string{
+		"ALTER TABLE m ADD COLUMN gone boolean DEFAULT false",
+		"ALTER TABLE m ALTER COLUMN score TYPE double precision",
+		"ALTER TABLE m DROP COLUMN note",
+	})
+
+	diff, err = Mapper(M{}, "*").SetOptions(WithDialect(MySQL)).DiffSchema(context.Background(), db, "m")
+	is.NoErr(err)
+	stmts, err = diff.AlterStatements(false)
+	is.NoErr(err)
+	is.Equal(stmts[1], "ALTER TABLE m MODIFY COLUMN score double NOT NULL")
+
+	diff, err = Mapper(M{}, "*").SetOptions(WithDialect(SQLite)).DiffSchema(context.Background(), db, "m")
+	is.NoErr(err)
+	_, err = diff.AlterStatements(false)
+	is.True(err != nil) // SQLite cannot alter types
+
+	diff, err = Mapper(M{}, "*").SetOptions(WithDialect(Postgres)).DiffSchema(context.Background(), db, "m; DROP TABLE m")
+	is.NoErr(err)
+	_, err = diff.AlterStatements(false)
+	var unsafe *ErrUnsafeColumn
+	is.True(errors.As(err, &unsafe))
+}
+
+func TestValidateSchemaTypeHint(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		Price string    `mapper:"price,type=numeric(12,2)"`
+		Code  string    `mapper:"code,type=varchar(8)"`
+		At    time.Time `mapper:"at,type=timestamptz"`
+		Tag   string    `mapper:"tag,type=char(2)"`
+	}
+	db := sql.OpenDB(&fakeConnector{
+		cols: []string{"column_name", "data_type", "nullable", "generated"},
+		rows: [][]driver.Value{
+			{"price", "numeric", false, false},
+			{"code", "character varying", false, false},
+			{"at", "timestamp with time zone", false, false},
+			{"tag", "text", false, false},
+		},
+	})
+	defer db.Close()
+
+	r, err := Mapper(M{}, "*").SetOptions(WithDialect(Postgres)).ValidateSchema(context.Background(), db, "m")
+	is.NoErr(err)
+	is.Equal(r.Err().Error(), "table m does not match mapper: column tag is text, not char(2)")
+
+	is.True(sameType("decimal(12, 2)", "numeric(12,2)"))
+	is.True(!sameType("decimal(12,2)", "decimal(10,2)"))
+	is.True(sameType("int", "int(11)"))
+}
+
+func TestTableColumns(t *testing.T) {
+	is := is.New(t)
+	db := sql.OpenDB(&fakeConnector{
+		cols: []string{"name", "type", "nullable", "generated"},
+		rows: [][]driver.Value{
+			{"id", "INTEGER", int64(0), int64(0)},
+			{"note", "TEXT", int64(1), int64(0)},
+		},
+	})
+	defer db.Close()
+
+	cols, err := TableColumns(context.Background(), db, SQLite, "m")
+	is.NoErr(err)
+	is.Equal(cols, []TableColumn{{"id", "integer", false, false}, {"note", "text", true, false}})
+}
+
+func TestCheckTypes(t *testing.T) {
+	is := is.New(t)
+	type M struct {
+		ID    int64
+		Name  string
+		Score float64
+	}
+	db := sql.OpenDB(&fakeConnector{
+		cols:  []string{"id", "name", "score"},
+		types: []string{"INTEGER", "TEXT", "TEXT"},
+	})
+	defer db.Close()
+	rows, err := db.Query("SELECT")
+	is.NoErr(err)
+	defer rows.Close()
+
+	err = Mapper(M{}, "*").CheckTypes(rows)
+	is.Equal(err.Error(), "column score is text, not compatible with float64")
+
+	err = Mapper(M{}, "id", "name").CheckTypes(rows)
+	is.Equal(err.Error(), "rows have 3 columns, mapper has 2")
+}
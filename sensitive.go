@@ -0,0 +1,50 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+)
+
+// Redacted is the value of a field tagged sensitive in statement
+// arguments. Drivers get the value, while it prints, marshals and logs as
+// [REDACTED].
+//
+// Fields tagged sensitive, as passwords and tokens, are passed to
+// statements as Redacted values by [InsertArgs], [UpdateArgs] and the
+// helpers running them, so that tracers, loggers and [DebugSQL] do not
+// leak them:
+//
+//	type User struct {
+//	  ID       int64  `mapper:"id,pk"`
+//	  Password []byte `mapper:"password,sensitive"`
+//	}
+type Redacted struct {
+	v any
+}
+
+const redacted = "[REDACTED]"
+
+// Value implements [driver.Valuer], converting the value as database/sql
+// does by default.
+func (r Redacted) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(r.v)
+}
+
+func (Redacted) String() string   { return redacted }
+func (Redacted) GoString() string { return redacted }
+
+// Format implements [fmt.Formatter], so every verb prints [REDACTED].
+func (Redacted) Format(f fmt.State, _ rune) { io.WriteString(f, redacted) }
+
+// MarshalText implements [encoding.TextMarshaler], for JSON and structured
+// loggers.
+func (Redacted) MarshalText() ([]byte, error) { return []byte(redacted), nil }
+
+// redact returns v as a [Redacted] when column j is sensitive.
+func (m *mapper) redact(j int, v any) any {
+	if !m.fields[j].opts.Has("sensitive") {
+		return v
+	}
+	return Redacted{v}
+}
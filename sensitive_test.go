@@ -0,0 +1,41 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestSensitive(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID       int64  `mapper:"id,pk"`
+		Password string `mapper:"password,sensitive"`
+	}
+	var logged []any
+	conn := &fakeConnector{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	users := Mapper(User{}, "*").With(WithDialect(Postgres), WithLogger(func(_ context.Context, _ string, args []any, _ error, _ time.Duration) {
+		logged = args
+	}))
+	u := User{1, "hunter2"}
+
+	_, err := users.Insert(context.Background(), db, "users", u)
+	is.NoErr(err)
+	is.Equal(conn.lastCall().args, []driver.Value{int64(1), "hunter2"}) // drivers get the value
+	is.Equal(fmt.Sprint(logged...), "1 [REDACTED]")
+	is.Equal(fmt.Sprintf("%q %#v %x", logged[1], logged[1], logged[1]), "[REDACTED] [REDACTED] [REDACTED]")
+	b, _ := json.Marshal(logged)
+	is.Equal(string(b), `[1,"[REDACTED]"]`)
+
+	is.Equal(users.DebugSQL(users.UpdateString("users"), users.UpdateArgs(u)...),
+		"/* DebugSQL */ UPDATE users SET password='[REDACTED]' WHERE id=1")
+	is.Equal(users.Values(u), []any{int64(1), "hunter2"})
+}
@@ -0,0 +1,69 @@
+// Package spannermapper builds cloud.google.com/go/spanner mutations out of
+// mapped records, so columns and values cannot get misordered. It lives in
+// its own module to keep mapper free of dependencies.
+package spannermapper
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/spanner"
+)
+
+// Mapper is the part of a mapper this package needs.
+type Mapper interface {
+	Columns() []string
+	Values(dest any) []any
+}
+
+// Insert returns a mutation inserting rec, a mapper target struct or a
+// pointer to one, into table:
+//
+//	mut, err := spannermapper.Insert("Users", users, u)
+//	...
+//	_, err = client.Apply(ctx, []*spanner.Mutation{mut})
+func Insert(table string, m Mapper, rec any) (*spanner.Mutation, error) {
+	return mutation(spanner.Insert, table, m, rec)
+}
+
+// Update returns a mutation updating the row of rec in table. The columns
+// of m MUST include the primary key.
+func Update(table string, m Mapper, rec any) (*spanner.Mutation, error) {
+	return mutation(spanner.Update, table, m, rec)
+}
+
+// InsertOrUpdate returns a mutation inserting rec in table, or updating its
+// row if it exists. The columns of m MUST include the primary key.
+func InsertOrUpdate(table string, m Mapper, rec any) (*spanner.Mutation, error) {
+	return mutation(spanner.InsertOrUpdate, table, m, rec)
+}
+
+// Replace returns a mutation inserting rec in table, deleting its row first
+// if it exists, so columns not mapped get their default value.
+func Replace(table string, m Mapper, rec any) (*spanner.Mutation, error) {
+	return mutation(spanner.Replace, table, m, rec)
+}
+
+func mutation(op func(string, []string, []any) *spanner.Mutation, table string, m Mapper, rec any) (*spanner.Mutation, error) {
+	cols := m.Columns()
+	vals := m.Values(rec)
+	for j, v := range vals {
+		v, err := plain(v)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", cols[j], err)
+		}
+		vals[j] = v
+	}
+	return op(table, cols, vals), nil
+}
+
+// plain resolves database/sql nullable types, which spanner does not know,
+// returning nil for NULL. Other values, including pointers and spanner
+// nullable types, are encoded by spanner itself.
+func plain(v any) (any, error) {
+	if vr, ok := v.(driver.Valuer); ok && reflect.TypeOf(v).PkgPath() == "database/sql" {
+		return vr.Value()
+	}
+	return v, nil
+}
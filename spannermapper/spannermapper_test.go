@@ -0,0 +1,33 @@
+package spannermapper
+
+import (
+	"database/sql"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/dav-m85/mapper"
+	"github.com/matryer/is"
+)
+
+type singer struct {
+	SingerID  int64
+	FirstName string
+	Nickname  sql.NullString
+	Rating    spanner.NullFloat64
+}
+
+func TestMutations(t *testing.T) {
+	is := is.New(t)
+	m := mapper.Mapper(singer{}, "*")
+	rec := &singer{1, "Marc", sql.NullString{}, spanner.NullFloat64{Float64: 4.5, Valid: true}}
+
+	got, err := Insert("Singers", m, rec)
+	is.NoErr(err)
+	want := spanner.Insert("Singers", []string{"singerid", "firstname", "nickname", "rating"},
+		[]any{int64(1), "Marc", nil, spanner.NullFloat64{Float64: 4.5, Valid: true}})
+	is.Equal(got, want)
+
+	got, err = Update("Singers", m.Subset("singerid", "nickname"), singer{SingerID: 1, Nickname: sql.NullString{String: "M", Valid: true}})
+	is.NoErr(err)
+	is.Equal(got, spanner.Update("Singers", []string{"singerid", "nickname"}, []any{int64(1), "M"}))
+}
@@ -0,0 +1,49 @@
+package mapper
+
+// SquirrelColumns returns the mapped columns, for squirrel Select or
+// Columns. It and the other Squirrel helpers return plain types that
+// github.com/Masterminds/squirrel accepts as is, so this package does not
+// depend on it:
+//
+//	sq.Select(users.SquirrelColumns()...).From("users").Where(users.SquirrelEq(example))
+//	sq.Update("users").SetMap(users.SquirrelSetMap(u)).Where(sq.Eq{"id": u.ID})
+func (m *mapper) SquirrelColumns() []string {
+	return append([]string(nil), m.cols...)
+}
+
+// SquirrelSetMap returns the values of rec by column, for squirrel
+// UpdateBuilder.SetMap or InsertBuilder.SetMap, expression and generated
+// columns left out as by [ValuesMap].
+func (m *mapper) SquirrelSetMap(rec any) map[string]any {
+	return m.ValuesMap(rec)
+}
+
+// SquirrelEq returns the non zero values of example by column, a
+// query-by-example predicate squirrel Where takes like a squirrel.Eq.
+// Columns listed in cols are kept when zero, nil ones as nil, which
+// squirrel turns into col IS NULL. Values are converted as by [Values],
+// and expr columns left out. See [WhereExample] without squirrel.
+func (m *mapper) SquirrelEq(example any, cols ...string) map[string]any {
+	v, p, err := m.structOf(example)
+	if err != nil {
+		panic(err)
+	}
+	if missing := m.unknown(cols); len(missing) > 0 {
+		panic(&ErrMissingColumns{Cols: missing})
+	}
+	res := make(map[string]any)
+	for j, f := range m.fields {
+		if m.isExpr(j) {
+			continue
+		}
+		fv := v.FieldByIndex(f.Index)
+		listed := fieldSlice(cols).index(m.cols[j]) != -1
+		switch {
+		case listed && isNull(fv):
+			res[m.cols[j]] = nil
+		case listed, !fv.IsZero():
+			res[m.cols[j]] = m.fieldValue(v, p, j)
+		}
+	}
+	return res
+}
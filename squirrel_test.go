@@ -0,0 +1,30 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSquirrel(t *testing.T) {
+	is := is.New(t)
+	dut := Mapper(scanRecord{}, "*")
+
+	is.Equal(dut.SquirrelColumns(), []string{"id", "name"})
+	is.Equal(dut.SquirrelSetMap(&scanRecord{1, "a"}), map[string]any{"id": int64(1), "name": "a"})
+	is.Equal(dut.SquirrelEq(scanRecord{Name: "a"}), map[string]any{"name": "a"})
+}
+
+func TestSquirrelEqConverted(t *testing.T) {
+	is := is.New(t)
+	type Doc struct {
+		ID    string `mapper:"id,pk,uuid=binary"`
+		Title string `mapper:"title"`
+		Count int64  `mapper:"n,expr=count(*)"`
+	}
+	dut := Mapper(Doc{}, "*")
+	const id = "6ccd780c-baba-1026-9564-5b8c656024db"
+	u, err := parseUUID(id)
+	is.NoErr(err)
+	is.Equal(dut.SquirrelEq(Doc{ID: id, Count: 2}), map[string]any{"id": u[:]})
+}
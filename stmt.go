@@ -0,0 +1,198 @@
+package mapper
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// preparer is implemented by *sql.DB and *sql.Conn.
+type preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// StmtCache keeps prepared statements around, so queries generated from a
+// mapper and run over and over are prepared by the driver only once:
+//
+//	var stmts = NewStmtCache(64)
+//	stmts.ExecContext(ctx, db, `INSERT INTO users VALUES(`+users.Marks()+`)`, users.Values(u)...)
+//
+// Statements are keyed by database and query. When more than size of them
+// are cached, the least recently used one gets closed. Transactions end
+// their statements on commit, so cache statements of a *sql.DB or *sql.Conn
+// only. A StmtCache is safe for concurrent use.
+type StmtCache struct {
+	mu    sync.Mutex
+	size  int
+	lru   *list.List // of *stmtEntry, most recently used first
+	items map[stmtKey]*list.Element
+}
+
+type stmtKey struct {
+	db    preparer
+	query string
+}
+
+type stmtEntry struct {
+	key     stmtKey
+	stmt    *sql.Stmt
+	refs    int  // running calls
+	evicted bool // close when refs drops to 0
+}
+
+// WithStmtCache has the exec helpers, as [Insert], [Update], [Delete] and
+// [Query], run their statements through c when given a *sql.DB or a
+// *sql.Conn, so they are prepared once:
+//
+//	var stmts = NewStmtCache(64)
+//	var users = Mapper(User{}, "*").SetOptions(WithStmtCache(stmts))
+//
+// Statements run in transactions are not cached.
+func WithStmtCache(c *StmtCache) MapperOption {
+	return func(m *mapper) {
+		m.stmts = c
+	}
+}
+
+// cached returns db as a preparer when its statements go through the
+// statement cache of m.
+func (m *mapper) cached(db any) (preparer, bool) {
+	if m.stmts == nil {
+		return nil, false
+	}
+	switch db := db.(type) {
+	case *sql.DB:
+		return db, true
+	case *sql.Conn:
+		return db, true
+	}
+	return nil, false
+}
+
+// execContext runs query on db, through the statement cache if any.
+func (m *mapper) execContext(ctx context.Context, db Execer, query string, args []any) (sql.Result, error) {
+	if p, ok := m.cached(db); ok {
+		return m.stmts.ExecContext(ctx, p, query, args...)
+	}
+	return db.ExecContext(ctx, query, args...)
+}
+
+// queryContext runs query on db, through the statement cache if any.
+func (m *mapper) queryContext(ctx context.Context, db Queryer, query string, args []any) (*sql.Rows, error) {
+	if p, ok := m.cached(db); ok {
+		return m.stmts.QueryContext(ctx, p, query, args...)
+	}
+	return db.QueryContext(ctx, query, args...)
+}
+
+// NewStmtCache returns a cache holding at most size statements.
+func NewStmtCache(size int) *StmtCache {
+	if size < 1 {
+		panic("StmtCache size MUST be positive")
+	}
+	return &StmtCache{
+		size:  size,
+		lru:   list.New(),
+		items: make(map[stmtKey]*list.Element),
+	}
+}
+
+// ExecContext runs query on db with args, preparing it first if needed.
+func (c *StmtCache) ExecContext(ctx context.Context, db preparer, query string, args ...any) (sql.Result, error) {
+	e, err := c.acquire(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(e)
+	return e.stmt.ExecContext(ctx, args...)
+}
+
+// QueryContext runs query on db with args, preparing it first if needed.
+func (c *StmtCache) QueryContext(ctx context.Context, db preparer, query string, args ...any) (*sql.Rows, error) {
+	e, err := c.acquire(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(e)
+	return e.stmt.QueryContext(ctx, args...)
+}
+
+// Len returns the number of cached statements.
+func (c *StmtCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Close closes every cached statement and empties the cache. Statements in
+// use are closed as soon as their call returns.
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var first error
+	for c.lru.Len() > 0 {
+		if err := c.evict(c.lru.Back()); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// acquire returns the entry for query on db, preparing it if needed. It
+// MUST be paired with release.
+func (c *StmtCache) acquire(ctx context.Context, db preparer, query string) (*stmtEntry, error) {
+	k := stmtKey{db, query}
+	c.mu.Lock()
+	if el, ok := c.items[k]; ok {
+		c.lru.MoveToFront(el)
+		e := el.Value.(*stmtEntry)
+		e.refs++
+		c.mu.Unlock()
+		return e, nil
+	}
+	c.mu.Unlock()
+
+	// Prepare without holding the lock, a concurrent call may win the race.
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[k]; ok {
+		stmt.Close()
+		c.lru.MoveToFront(el)
+		e := el.Value.(*stmtEntry)
+		e.refs++
+		return e, nil
+	}
+	e := &stmtEntry{key: k, stmt: stmt, refs: 1}
+	c.items[k] = c.lru.PushFront(e)
+	for c.lru.Len() > c.size {
+		c.evict(c.lru.Back())
+	}
+	return e, nil
+}
+
+// release e after use, closing it if it got evicted meanwhile.
+func (c *StmtCache) release(e *stmtEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.refs--
+	if e.evicted && e.refs == 0 {
+		e.stmt.Close()
+	}
+}
+
+// evict removes el from the cache. c.mu MUST be held.
+func (c *StmtCache) evict(el *list.Element) error {
+	e := c.lru.Remove(el).(*stmtEntry)
+	delete(c.items, e.key)
+	e.evicted = true
+	if e.refs == 0 {
+		return e.stmt.Close()
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestStmtCache(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	conn := &fakeConnector{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	c := NewStmtCache(2)
+	for i := 0; i < 3; i++ {
+		_, err := c.ExecContext(ctx, db, "INSERT 1")
+		is.NoErr(err)
+	}
+	is.Equal(conn.prepared.Load(), int32(1)) // prepared once
+	is.Equal(c.Len(), 1)
+
+	_, err := c.ExecContext(ctx, db, "INSERT 2")
+	is.NoErr(err)
+	_, err = c.ExecContext(ctx, db, "INSERT 3")
+	is.NoErr(err)
+	is.Equal(c.Len(), 2) // INSERT 1 got evicted
+
+	_, err = c.ExecContext(ctx, db, "INSERT 1")
+	is.NoErr(err)
+	is.Equal(conn.prepared.Load(), int32(4))
+
+	is.NoErr(c.Close())
+	is.Equal(c.Len(), 0)
+}
+
+func TestWithStmtCache(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	conn := &fakeConnector{cols: []string{"id", "name", "email"}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	c := NewStmtCache(8)
+	defer c.Close()
+	users := Mapper(buildUser{}, "*").With(WithStmtCache(c))
+
+	for i := range 3 {
+		_, err := users.Insert(ctx, db, "users", buildUser{ID: int64(i)})
+		is.NoErr(err)
+		_, err = Query[buildUser](ctx, users, db, users.SelectString("users"))
+		is.NoErr(err)
+	}
+	is.Equal(conn.prepared.Load(), int32(2)) // once each
+	is.Equal(c.Len(), 2)
+
+	tx, err := db.BeginTx(ctx, nil)
+	is.NoErr(err)
+	_, err = users.Insert(ctx, tx, "users", buildUser{ID: 4})
+	is.NoErr(err)
+	is.NoErr(tx.Commit())
+	is.Equal(c.Len(), 2) // transactions are not cached
+}
@@ -0,0 +1,149 @@
+package mapper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TableNaming infers table names from struct names.
+type TableNaming struct {
+	// Schema qualifies names, as analytics in analytics.page_views.
+	Schema string
+
+	// Case converts the struct name, [SnakeCase] when nil.
+	Case FieldMapper
+
+	// Plural pluralizes the converted name, [Pluralize] when nil. Set it to
+	// [Direct] for singular names.
+	Plural func(string) string
+}
+
+// WithTable sets the mapper table, see [Table]. name may be qualified with
+// a schema and hold date patterns, as sharded tables do:
+//
+//	var events = Mapper(Event{}, "*").SetOptions(WithTable("events_{{yyyymm}}"))
+//
+// Patterns are {{yyyy}}, {{mm}}, {{dd}}, {{yyyymm}} and {{yyyymmdd}}. It
+// panics with [*ErrUnsafeColumn] when name is not a safe identifier, or on
+// unknown patterns.
+func WithTable(name string) MapperOption {
+	if _, err := expandTable(name, time.Time{}); err != nil {
+		panic(err)
+	}
+	return func(m *mapper) {
+		m.table = name
+	}
+}
+
+// WithTableNaming sets how the mapper table is named after the target
+// struct when not set with [WithTable]:
+//
+//	WithTableNaming(TableNaming{Schema: "analytics"}) // PageView in analytics.page_views
+func WithTableNaming(n TableNaming) MapperOption {
+	return func(m *mapper) {
+		m.tableNaming = n
+	}
+}
+
+// Table returns the mapper table, its date patterns expanded at the
+// current time, see [WithClock]. Builders and helpers taking a table name,
+// such as [SelectString] or [Insert], use it when given an empty one:
+//
+//	var users = Mapper(User{}, "*") // table users
+//	rows, err := db.Query(users.SelectString(""))
+//
+// It is named after the target struct, see [TableNaming], unless set with
+// [WithTable]. It panics with [ErrNoTable] when the target struct has no
+// name to infer the table from.
+func (m *mapper) Table() string {
+	return m.TableAt(m.now())
+}
+
+// TableAt returns the mapper table, its date patterns expanded at t, as
+// events_202401 for events_{{yyyymm}} in January 2024.
+func (m *mapper) TableAt(t time.Time) string {
+	if m.table == "" {
+		return m.inferTable()
+	}
+	s, _ := expandTable(m.table, t) // checked by WithTable
+	return s
+}
+
+// tableName returns table, or the mapper table when empty.
+func (m *mapper) tableName(table string) string {
+	if table == "" {
+		return m.Table()
+	}
+	return table
+}
+
+func (m *mapper) inferTable() string {
+	name := m.elem.Name()
+	if name == "" {
+		panic(ErrNoTable)
+	}
+	n := m.tableNaming
+	if n.Case == nil {
+		n.Case = SnakeCase
+	}
+	if n.Plural == nil {
+		n.Plural = Pluralize
+	}
+	name = n.Plural(n.Case(name))
+	if n.Schema != "" {
+		name = n.Schema + "." + name
+	}
+	return name
+}
+
+// tablePatterns are the date patterns of table names, and their Go time
+// layouts.
+var tablePatterns = map[string]string{
+	"yyyy":     "2006",
+	"mm":       "01",
+	"dd":       "02",
+	"yyyymm":   "200601",
+	"yyyymmdd": "20060102",
+}
+
+// expandTable returns name with its date patterns expanded at t, or an
+// error when it has unknown patterns or is not a safe identifier.
+func expandTable(name string, t time.Time) (string, error) {
+	return expandPatterns(name, func(pattern string) (string, error) {
+		layout, known := tablePatterns[pattern]
+		if !known {
+			return "", fmt.Errorf("table %s: unknown pattern {{%s}}", name, pattern)
+		}
+		return t.Format(layout), nil
+	})
+}
+
+// expandPatterns returns name with its {{pattern}} placeholders replaced
+// by expand, or an error when it is not a safe identifier.
+func expandPatterns(name string, expand func(pattern string) (string, error)) (string, error) {
+	var b strings.Builder
+	rest := name
+	for {
+		before, after, ok := strings.Cut(rest, "{{")
+		b.WriteString(before)
+		if !ok {
+			break
+		}
+		pattern, after, ok := strings.Cut(after, "}}")
+		if !ok {
+			return "", fmt.Errorf("table %s: unclosed pattern {{%s", name, pattern)
+		}
+		s, err := expand(pattern)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s)
+		rest = after
+	}
+	s := b.String()
+	if err := checkColumn(s, nil); err != nil {
+		return "", err
+	}
+	return s, nil
+}
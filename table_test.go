@@ -0,0 +1,46 @@
+package mapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTable(t *testing.T) {
+	is := is.New(t)
+	type PageView struct {
+		ID int64 `mapper:"id,pk"`
+	}
+	dut := Mapper(PageView{}, "*")
+	is.Equal(dut.Table(), "page_views")
+	is.Equal(dut.SelectString(""), "SELECT id FROM page_views")
+	is.Equal(dut.DeleteString(""), "DELETE FROM page_views WHERE id=?")
+	is.Equal(dut.SelectString("pv"), "SELECT id FROM pv")
+
+	is.Equal(dut.With(WithTableNaming(TableNaming{Schema: "analytics", Plural: Direct})).Table(), "analytics.page_view")
+
+	jan := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	events := dut.With(WithTable("events_{{yyyymm}}"), WithClock(func() time.Time { return jan }))
+	is.Equal(events.InsertString(""), "INSERT INTO events_202401 (id) VALUES (?)")
+	is.Equal(events.TableAt(jan.AddDate(0, 2, 0)), "events_202403")
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+	WithTable("events_{{week}}")
+}
+
+func TestPluralize(t *testing.T) {
+	is := is.New(t)
+	for s, want := range map[string]string{
+		"user":     "users",
+		"category": "categories",
+		"day":      "days",
+		"address":  "addresses",
+		"box":      "boxes",
+		"batch":    "batches",
+	} {
+		is.Equal(Pluralize(s), want)
+	}
+}
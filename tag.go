@@ -0,0 +1,147 @@
+package mapper
+
+import "strings"
+
+// TagOptions are the options following the column name in a struct tag, as
+// in `mapper:"price,notnull,type=numeric(12,2)"`. Flags map to an empty
+// value.
+type TagOptions map[string]string
+
+// Has tells whether option name is set.
+func (o TagOptions) Has(name string) bool {
+	_, ok := o[name]
+	return ok
+}
+
+// Get returns the value of option name, or "".
+func (o TagOptions) Get(name string) string {
+	return o[name]
+}
+
+// parseTag splits a struct tag value into a column name and its options.
+// Commas between parentheses do not split, so types like numeric(12,2) can
+// be given as values.
+func parseTag(tag string) (string, TagOptions) {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range tag {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, tag[start:])
+
+	var opts TagOptions
+	for _, p := range parts[1:] {
+		if p == "" {
+			continue
+		}
+		if opts == nil {
+			opts = make(TagOptions)
+		}
+		k, v, _ := strings.Cut(p, "=")
+		opts[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return parts[0], opts
+}
+
+// TagParser splits a struct tag value into a column name and options, see
+// [WithTagParser]. An empty column name leaves it to the FieldMapper, and
+// the ignore option skips the field.
+type TagParser func(tag string) (string, TagOptions)
+
+// GormTags reads gorm tags, as in `gorm:"column:user_name;primaryKey;not null"`.
+// column gives the column name, "-" ignores the field, and primaryKey,
+// not null, type and default become the pk, notnull, type= and default=
+// options. Other settings are kept as options with lower cased names. As
+// gorm names untagged fields in snake case, pair it with [SnakeCase]:
+//
+//	MapperWithOptions(User{}, []MapperOption{WithTagParser("gorm", GormTags), WithFieldMapper(SnakeCase)}, "*")
+var GormTags TagParser = parseGormTag
+
+func parseGormTag(tag string) (string, TagOptions) {
+	var col string
+	var opts TagOptions
+	set := func(k, v string) {
+		if opts == nil {
+			opts = make(TagOptions)
+		}
+		opts[k] = v
+	}
+	for part := range strings.SplitSeq(tag, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), ":")
+		switch lk := strings.ToLower(strings.TrimSpace(k)); lk {
+		case "":
+		case "-":
+			set("ignore", "")
+		case "column":
+			col = strings.TrimSpace(v)
+		case "primarykey", "primary_key":
+			set("pk", "")
+		case "not null":
+			set("notnull", "")
+		default:
+			set(lk, strings.TrimSpace(v))
+		}
+	}
+	return col, opts
+}
+
+// XormTags reads xorm tags, as in `xorm:"'user_name' pk varchar(25) notnull"`.
+// The quoted word gives the column name, "-" ignores the field, default
+// takes the next word as value, and words other than xorm keywords are the
+// column type, kept as the type= option. Keywords are kept as options with
+// lower cased names, "not null" as notnull. Like gorm, xorm names untagged
+// fields in snake case by default, see [GormTags].
+var XormTags TagParser = parseXormTag
+
+// xormKeywords are the xorm tag words that are not column types.
+var xormKeywords = map[string]bool{
+	"pk": true, "autoincr": true, "null": true, "notnull": true, "unique": true,
+	"index": true, "extends": true, "created": true, "updated": true,
+	"deleted": true, "version": true, "<-": true, "->": true, "json": true,
+}
+
+func parseXormTag(tag string) (string, TagOptions) {
+	var col string
+	var opts TagOptions
+	set := func(k, v string) {
+		if opts == nil {
+			opts = make(TagOptions)
+		}
+		opts[k] = v
+	}
+	words := strings.Fields(tag)
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		lw := strings.ToLower(w)
+		switch {
+		case w == "-":
+			set("ignore", "")
+		case len(w) >= 2 && w[0] == '\'' && w[len(w)-1] == '\'':
+			col = w[1 : len(w)-1]
+		case lw == "not" && i+1 < len(words) && strings.EqualFold(words[i+1], "null"):
+			set("notnull", "")
+			i++
+		case lw == "default" && i+1 < len(words):
+			set("default", words[i+1])
+			i++
+		case lw == "comment" && i+1 < len(words):
+			set("comment", strings.Trim(words[i+1], "'"))
+			i++
+		case xormKeywords[lw], strings.HasPrefix(lw, "unique("), strings.HasPrefix(lw, "index("):
+			set(lw, "")
+		default:
+			set("type", w)
+		}
+	}
+	return col, opts
+}
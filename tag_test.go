@@ -0,0 +1,38 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGormTags(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID       int64  `gorm:"primaryKey;column:user_id"`
+		UserName string `gorm:"column:name;type:varchar(100);not null;default:'x'"`
+		Email    string `gorm:"uniqueIndex"`
+		Password string `gorm:"-"`
+	}
+	dut := MapperWithOptions(User{}, []MapperOption{WithTagParser("gorm", GormTags), WithFieldMapper(SnakeCase)}, "*")
+	is.Equal(dut.Columns(), []string{"user_id", "name", "email"})
+	fields := dut.Fields()
+	is.Equal(fields[0].Options, TagOptions{"pk": ""})
+	is.Equal(fields[1].Options, TagOptions{"type": "varchar(100)", "notnull": "", "default": "'x'"})
+	is.Equal(fields[2].Options, TagOptions{"uniqueindex": ""})
+}
+
+func TestXormTags(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID       int64  `xorm:"pk autoincr 'user_id'"`
+		UserName string `xorm:"varchar(25) not null unique 'name' default 'x'"`
+		Email    string
+		Password string `xorm:"-"`
+	}
+	dut := MapperWithOptions(User{}, []MapperOption{WithTagParser("xorm", XormTags), WithFieldMapper(SnakeCase)}, "*")
+	is.Equal(dut.Columns(), []string{"user_id", "name", "email"})
+	fields := dut.Fields()
+	is.Equal(fields[0].Options, TagOptions{"pk": "", "autoincr": ""})
+	is.Equal(fields[1].Options, TagOptions{"type": "varchar(25)", "notnull": "", "unique": "", "default": "'x'"})
+}
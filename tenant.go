@@ -0,0 +1,76 @@
+package mapper
+
+import (
+	"context"
+	"slices"
+)
+
+// WithTenantColumn scopes statements to the tenant found in their context
+// by fromCtx, stored in column col of every table:
+//
+//	var users = Mapper(User{}, "*").SetOptions(WithTenantColumn("org_id", func(ctx context.Context) any {
+//	  return ctx.Value(orgKey{})
+//	}))
+//
+// [SelectString] then ends with WHERE org_id=?, taking the tenant as first
+// argument, see [TenantArgs], and [UpdateString] and [DeleteString] end
+// with AND org_id=?, taking it last. [InsertString] has the org_id column
+// last. [Insert], [Update] and [Delete] pass the tenant themselves, and
+// fail with [ErrNoTenant] when fromCtx returns nil.
+//
+// The column is only ever written from the context, so it MUST NOT be
+// mapped to a field. It panics with [*ErrUnsafeColumn] when col is not a
+// safe identifier, or [*ErrDuplicateColumn] when it is mapped.
+func WithTenantColumn(col string, fromCtx func(ctx context.Context) any) MapperOption {
+	if err := checkColumn(col, nil); err != nil {
+		panic(err)
+	}
+	return func(m *mapper) {
+		if slices.Contains(m.cols, col) {
+			panic(&ErrDuplicateColumn{Col: col})
+		}
+		m.tenantCol = col
+		m.tenantOf = fromCtx
+	}
+}
+
+// TenantColumn returns the tenant column, empty without
+// [WithTenantColumn].
+func (m *mapper) TenantColumn() string {
+	return m.tenantCol
+}
+
+// TenantArgs returns args preceded by the tenant of ctx, for queries built
+// from [SelectString]. It fails with [ErrNoTenant] when there is none, and
+// returns args as is without [WithTenantColumn].
+func (m *mapper) TenantArgs(ctx context.Context, args ...any) ([]any, error) {
+	if m.tenantCol == "" {
+		return args, nil
+	}
+	t, err := m.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]any{t}, args...), nil
+}
+
+// tenant returns the tenant of ctx.
+func (m *mapper) tenant(ctx context.Context) (any, error) {
+	t := m.tenantOf(ctx)
+	if t == nil {
+		return nil, ErrNoTenant
+	}
+	return t, nil
+}
+
+// withTenant returns args followed by the tenant of ctx, if any.
+func (m *mapper) withTenant(ctx context.Context, args []any) ([]any, error) {
+	if m.tenantCol == "" {
+		return args, nil
+	}
+	t, err := m.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(args, t), nil
+}
@@ -0,0 +1,53 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type orgKey struct{}
+
+func TestTenantColumn(t *testing.T) {
+	is := is.New(t)
+	conn := &fakeConnector{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	dut := Mapper(buildUser{}, "*").SetOptions(WithDialect(Postgres), WithTenantColumn("org_id", func(ctx context.Context) any {
+		return ctx.Value(orgKey{})
+	}))
+
+	is.Equal(dut.TenantColumn(), "org_id")
+	is.Equal(dut.SelectString("users"), "SELECT id,name,email FROM users WHERE org_id=$1")
+	is.Equal(dut.InsertString("users"), "INSERT INTO users (id,name,email,org_id) VALUES ($1,$2,$3,$4)")
+	is.Equal(dut.UpdateString("users"), "UPDATE users SET name=$1,email=$2 WHERE id=$3 AND org_id=$4")
+	is.Equal(dut.DeleteString("users"), "DELETE FROM users WHERE id=$1 AND org_id=$2")
+
+	ctx := context.WithValue(context.Background(), orgKey{}, int64(7))
+	u := &buildUser{1, "n", "e"}
+	_, err := dut.Insert(ctx, db, "users", u)
+	is.NoErr(err)
+	is.Equal(conn.lastCall().args, []driver.Value{int64(1), "n", "e", int64(7)})
+	_, err = dut.Delete(ctx, db, "users", u)
+	is.NoErr(err)
+	is.Equal(conn.lastCall().args, []driver.Value{int64(1), int64(7)})
+
+	args, err := dut.TenantArgs(ctx, "x")
+	is.NoErr(err)
+	is.Equal(args, []any{int64(7), "x"})
+
+	_, err = dut.Update(context.Background(), db, "users", u)
+	is.True(errors.Is(err, ErrNoTenant))
+
+	type Scoped struct {
+		ID    int64 `mapper:"id,pk"`
+		OrgID int64 `mapper:"org_id"`
+	}
+	_, err = MapperWithOptionsE(Scoped{}, []MapperOption{WithTenantColumn("org_id", nil)}, "*")
+	var dup *ErrDuplicateColumn
+	is.True(errors.As(err, &dup))
+}
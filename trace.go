@@ -0,0 +1,130 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Tracer observes the statements run by [Insert], [Update], [Delete] and
+// [Query], and the scans of rows queried by the caller with [ScanRow],
+// [Stream] and [ForEach], see [WithTracer]. The otelmapper package has an
+// OpenTelemetry implementation.
+type Tracer interface {
+	// StartStatement is called before s runs, and returns the context to
+	// run it with, and a function called once it ran with the number of
+	// rows affected or scanned, -1 when unknown, and its error.
+	StartStatement(ctx context.Context, s TracedStatement) (context.Context, func(rows int64, err error))
+}
+
+// TracedStatement describes a statement for a [Tracer].
+type TracedStatement struct {
+	// Operation is the first word of Query, upper case, as SELECT, or
+	// SCAN for scans.
+	Operation string
+
+	// Table is the table given to the helper, or else the mapper table,
+	// see [Table], empty when it has none.
+	Table string
+
+	// Query is empty for scans, whose statement ran beforehand.
+	Query string
+	Args  []any
+
+	// Target is the struct type the mapper was built from.
+	Target reflect.Type
+}
+
+// WithTracer adds t to the tracers notified of statements run by mapper
+// helpers:
+//
+//	var users = Mapper(User{}, "*").SetOptions(WithTracer(otelmapper.NewTracer()))
+func WithTracer(t Tracer) MapperOption {
+	return func(m *mapper) {
+		m.tracers = append(m.tracers[:len(m.tracers):len(m.tracers)], t)
+	}
+}
+
+// Logger is called by [WithLogger] once a statement ran.
+type Logger func(ctx context.Context, query string, args []any, err error, dur time.Duration)
+
+// WithLogger has log called with every statement run by mapper helpers,
+// its error and duration, to log slow or failing ones centrally:
+//
+//	WithLogger(func(ctx context.Context, query string, args []any, err error, dur time.Duration) {
+//	  if err != nil || dur > time.Second {
+//	    slog.WarnContext(ctx, "slow or failing query", "query", query, "dur", dur, "err", err)
+//	  }
+//	})
+//
+// It is a [Tracer]. Args may hold sensitive values, but those of fields
+// tagged sensitive, which are [Redacted].
+func WithLogger(log Logger) MapperOption {
+	return WithTracer(log)
+}
+
+// StartStatement implements [Tracer], ignoring scans.
+func (log Logger) StartStatement(ctx context.Context, s TracedStatement) (context.Context, func(int64, error)) {
+	if s.Operation == scanOperation {
+		return ctx, func(int64, error) {}
+	}
+	start := time.Now()
+	return ctx, func(_ int64, err error) {
+		log(ctx, s.Query, s.Args, err, time.Since(start))
+	}
+}
+
+// scanOperation is the [TracedStatement] operation of scans.
+const scanOperation = "SCAN"
+
+// startStatement notifies tracers that query is about to run against
+// table, the mapper table when empty.
+func (m *mapper) startStatement(ctx context.Context, table, query string, args []any) (context.Context, func(rows int64, err error)) {
+	if len(m.tracers) == 0 {
+		return ctx, func(int64, error) {}
+	}
+	op, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return m.trace(ctx, TracedStatement{Operation: strings.ToUpper(op), Table: table, Query: query, Args: args})
+}
+
+// startScan notifies tracers that rows are about to be scanned.
+func (m *mapper) startScan(ctx context.Context) (context.Context, func(rows int64, err error)) {
+	if len(m.tracers) == 0 {
+		return ctx, func(int64, error) {}
+	}
+	return m.trace(ctx, TracedStatement{Operation: scanOperation})
+}
+
+// trace notifies tracers of s.
+func (m *mapper) trace(ctx context.Context, s TracedStatement) (context.Context, func(rows int64, err error)) {
+	s.Target = m.elem
+	if s.Table == "" && (m.table != "" || m.elem.Name() != "") {
+		s.Table = m.Table()
+	}
+	ends := make([]func(int64, error), len(m.tracers))
+	for i, t := range m.tracers {
+		ctx, ends[i] = t.StartStatement(ctx, s)
+	}
+	return ctx, func(rows int64, err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](rows, err)
+		}
+	}
+}
+
+// execTraced runs query on db, tracing it, see [TranslateError] for the
+// errors returned.
+func (m *mapper) execTraced(ctx context.Context, db Execer, table, query string, args []any) (sql.Result, error) {
+	ctx, end := m.startStatement(ctx, table, query, args)
+	res, err := m.execContext(ctx, db, query, args)
+	rows := int64(-1)
+	if err == nil {
+		if n, err := res.RowsAffected(); err == nil {
+			rows = n
+		}
+	}
+	end(rows, err)
+	return res, TranslateError(err)
+}
@@ -0,0 +1,138 @@
+package mapper
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// TranslateError returns err as an [*ErrDuplicateKey], [*ErrForeignKey] or
+// [*ErrSerialization] when it, or an error it wraps, is such a driver
+// error, and err otherwise. The exec helpers, as [Insert] or [InsertBatch],
+// and [Query] translate errors already, so that business code tests them
+// with errors.As rather than driver codes or messages:
+//
+//	var dup *mapper.ErrDuplicateKey
+//	if errors.As(err, &dup) && dup.Column == "email" {
+//	  return ErrEmailTaken
+//	}
+//
+// Translated errors wrap err, which errors.As still finds. Errors are
+// recognized without importing drivers, as by [Retryable]:
+//
+//   - errors with a SQLState() string method, as pgx and lib/pq ones,
+//     with columns read from their Detail field;
+//   - *mysql.MySQLError of github.com/go-sql-driver/mysql, with columns
+//     of foreign keys read from their Message field;
+//   - *sqlite.Error of modernc.org/sqlite, with columns of unique
+//     constraints read from their message.
+func TranslateError(err error) error {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch e.(type) {
+		case *ErrDuplicateKey, *ErrForeignKey, *ErrSerialization:
+			return err // already translated
+		}
+		if t := translate(err, e); t != nil {
+			return t
+		}
+	}
+	return err
+}
+
+// translate returns the translation of err when driver error e it wraps is
+// known, and nil otherwise.
+func translate(err, e error) error {
+	if s, ok := e.(interface{ SQLState() string }); ok {
+		constraint := stringField(e, "ConstraintName", "Constraint")
+		col := stringField(e, "ColumnName", "Column")
+		if col == "" {
+			col = between(stringField(e, "Detail"), "Key (", ")=")
+		}
+		switch s.SQLState() {
+		case "23505":
+			return &ErrDuplicateKey{Column: col, Constraint: constraint, Err: err}
+		case "23503":
+			return &ErrForeignKey{Column: col, Constraint: constraint, Err: err}
+		case "40001", "40P01":
+			return &ErrSerialization{Err: err}
+		}
+		return nil
+	}
+	if n, ok := mysqlNumber(e); ok {
+		msg := stringField(e, "Message")
+		switch n {
+		case 1062, 1586:
+			key := between(msg, "for key '", "'")
+			if _, name, ok := strings.Cut(key, "."); ok {
+				key = name // MySQL 8 prefixes the table
+			}
+			return &ErrDuplicateKey{Constraint: key, Err: err}
+		case 1216, 1217, 1451, 1452:
+			col := strings.ReplaceAll(between(msg, "FOREIGN KEY (", ")"), "`", "")
+			return &ErrForeignKey{Column: col, Constraint: between(msg, "CONSTRAINT `", "`"), Err: err}
+		case 1205, 1213:
+			return &ErrSerialization{Err: err}
+		}
+		return nil
+	}
+	if code, ok := sqliteCode(e); ok {
+		switch {
+		case code == 2067 || code == 1555: // SQLITE_CONSTRAINT_UNIQUE and _PRIMARYKEY
+			return &ErrDuplicateKey{Column: sqliteColumns(e.Error()), Err: err}
+		case code == 787: // SQLITE_CONSTRAINT_FOREIGNKEY
+			return &ErrForeignKey{Err: err}
+		case code&0xff == 5 || code&0xff == 6: // SQLITE_BUSY and SQLITE_LOCKED
+			return &ErrSerialization{Err: err}
+		}
+	}
+	return nil
+}
+
+// stringField returns the first non empty string field of err among names,
+// err being a pointer to a struct.
+func stringField(err error, names ...string) string {
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+	for _, name := range names {
+		if f := v.Elem().FieldByName(name); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+			return f.String()
+		}
+	}
+	return ""
+}
+
+// between returns the part of s between the first start and the next end,
+// or "" when missing.
+func between(s, start, end string) string {
+	_, s, ok := strings.Cut(s, start)
+	if !ok {
+		return ""
+	}
+	s, _, ok = strings.Cut(s, end)
+	if !ok {
+		return ""
+	}
+	return s
+}
+
+// sqliteColumns returns the columns of a SQLite unique constraint failure
+// message, as email for "UNIQUE constraint failed: users.email (2067)".
+func sqliteColumns(msg string) string {
+	_, s, ok := strings.Cut(msg, "constraint failed: ")
+	if !ok {
+		return ""
+	}
+	if _, after, ok := strings.Cut(s, "constraint failed: "); ok {
+		s = after // modernc.org/sqlite repeats the prefix
+	}
+	s, _, _ = strings.Cut(s, " (")
+	cols := strings.Split(s, ", ")
+	for i, c := range cols {
+		if _, name, ok := strings.Cut(c, "."); ok {
+			cols[i] = name
+		}
+	}
+	return strings.Join(cols, ", ")
+}
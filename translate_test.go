@@ -0,0 +1,75 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dav-m85/mapper/internal/drivertest/mysql"
+	"github.com/dav-m85/mapper/internal/drivertest/sqlite"
+	"github.com/matryer/is"
+)
+
+// pgconnError mimics the fields of pgconn.PgError.
+type pgconnError struct {
+	Code, Detail, ConstraintName string
+}
+
+func (e *pgconnError) Error() string    { return "ERROR: " + e.Code }
+func (e *pgconnError) SQLState() string { return e.Code }
+
+func TestTranslateError(t *testing.T) {
+	is := is.New(t)
+	var dup *ErrDuplicateKey
+	var fk *ErrForeignKey
+	var ser *ErrSerialization
+
+	pg := &pgconnError{Code: "23505", Detail: "Key (email)=(a@b.c) already exists.", ConstraintName: "users_email_key"}
+	err := TranslateError(fmt.Errorf("insert: %w", pg))
+	is.True(errors.As(err, &dup))
+	is.Equal(*dup, ErrDuplicateKey{Column: "email", Constraint: "users_email_key", Err: dup.Err})
+	is.True(errors.Is(err, pg))
+	is.Equal(err.Error(), "duplicate key on email: insert: ERROR: 23505")
+	is.Equal(TranslateError(err), err) // translated once
+
+	err = TranslateError(&pgconnError{Code: "23503", Detail: `Key (org_id, team_id)=(1, 2) is not present in table "teams".`})
+	is.True(errors.As(err, &fk))
+	is.Equal(fk.Column, "org_id, team_id")
+	is.True(errors.As(TranslateError(&pgError{"40P01"}), &ser))
+
+	err = TranslateError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@b.c' for key 'users.email'"})
+	is.True(errors.As(err, &dup))
+	is.Equal(dup.Constraint, "email")
+	is.Equal(dup.Column, "")
+	err = TranslateError(&mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`users`, CONSTRAINT `users_org` FOREIGN KEY (`org_id`) REFERENCES `orgs` (`id`))"})
+	is.True(errors.As(err, &fk))
+	is.Equal(*fk, ErrForeignKey{Column: "org_id", Constraint: "users_org", Err: fk.Err})
+	is.True(errors.As(TranslateError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found"}), &ser))
+
+	err = TranslateError(sqlite.NewError(2067, "constraint failed: UNIQUE constraint failed: users.org_id, users.email (2067)"))
+	is.True(errors.As(err, &dup))
+	is.Equal(dup.Column, "org_id, email")
+	is.True(errors.As(TranslateError(sqlite.NewError(787, "FOREIGN KEY constraint failed")), &fk))
+	is.True(errors.As(TranslateError(sqlite.NewError(517, "database is locked")), &ser)) // SQLITE_BUSY_SNAPSHOT
+
+	boom := errors.New("boom")
+	is.Equal(TranslateError(boom), boom)
+	is.Equal(TranslateError(&pgError{"22001"}), &pgError{"22001"})
+	is.Equal(TranslateError(nil), nil)
+}
+
+func TestInsertTranslatesErrors(t *testing.T) {
+	is := is.New(t)
+	conn := &fakeConnector{execErr: func(string, []driver.Value) error {
+		return &pgconnError{Code: "23505", Detail: "Key (email)=(a@b.c) already exists."}
+	}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	_, err := Mapper(buildUser{}, "*").Insert(context.Background(), db, "users", buildUser{ID: 1})
+	var dup *ErrDuplicateKey
+	is.True(errors.As(err, &dup))
+	is.Equal(dup.Column, "email")
+}
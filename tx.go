@@ -0,0 +1,166 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"math/rand/v2"
+	"path"
+	"reflect"
+	"time"
+)
+
+// TxOptions configures [Transact].
+type TxOptions struct {
+	sql.TxOptions
+
+	// MaxAttempts bounds the number of runs, 3 when zero.
+	MaxAttempts int
+
+	// Backoff is the longest wait before the first retry, 10ms when zero,
+	// doubled at each retry. Waits are random up to it.
+	Backoff time.Duration
+
+	// Retryable tells which errors are worth a retry, [Retryable] when nil.
+	Retryable func(err error) bool
+}
+
+// beginner is implemented by *sql.DB and *sql.Conn.
+type beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Transact runs fn in a transaction of db, a *sql.DB or *sql.Conn,
+// committed when fn returns nil and rolled back otherwise, even when fn
+// panics. fn gets the transaction as a [QueryExecer], which the exec
+// helpers take:
+//
+//	err := Transact(ctx, db, nil, func(tx mapper.QueryExecer) error {
+//	  if _, err := accounts.Update(ctx, tx, "", from); err != nil {
+//	    return err
+//	  }
+//	  _, err := accounts.Update(ctx, tx, "", to)
+//	  return err
+//	})
+//
+// When fn or the commit fail with a serialization failure or a deadlock,
+// the whole transaction runs again after a backoff, so fn MUST NOT have
+// side effects out of tx. opts may be nil.
+func Transact(ctx context.Context, db beginner, opts *TxOptions, fn func(tx QueryExecer) error) error {
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+	attempts, backoff, retryable := opts.MaxAttempts, opts.Backoff, opts.Retryable
+	if attempts <= 0 {
+		attempts = 3
+	}
+	if backoff <= 0 {
+		backoff = 10 * time.Millisecond
+	}
+	if retryable == nil {
+		retryable = Retryable
+	}
+	for attempt := 1; ; attempt++ {
+		err := transactOnce(ctx, db, &opts.TxOptions, fn)
+		if err == nil || attempt == attempts || !retryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(rand.N(backoff) + 1):
+		}
+		backoff *= 2
+	}
+}
+
+func transactOnce(ctx context.Context, db beginner, opts *sql.TxOptions, fn func(tx QueryExecer) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Retryable tells whether err, or an error it wraps, is a transient
+// transaction failure: a serialization failure or a deadlock. Errors are
+// recognized without importing drivers:
+//
+//   - errors with a SQLState() string method, as pgx and lib/pq ones,
+//     having state 40001 or 40P01;
+//   - *mysql.MySQLError of github.com/go-sql-driver/mysql, numbered 1213
+//     or 1205;
+//   - *sqlite.Error of modernc.org/sqlite, having the SQLITE_BUSY or
+//     SQLITE_LOCKED codes.
+func Retryable(err error) bool {
+	for err != nil {
+		if e, ok := err.(interface{ SQLState() string }); ok {
+			if s := e.SQLState(); s == "40001" || s == "40P01" {
+				return true
+			}
+		}
+		if c, ok := sqliteCode(err); ok && (c&0xff == 5 || c&0xff == 6) { // extended codes keep the primary in the low byte
+			return true
+		}
+		if n, ok := mysqlNumber(err); ok && (n == 1213 || n == 1205) {
+			return true
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				if Retryable(e) {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// driverError tells whether err is a pointer to the struct type name of a
+// package pkg, as the errors of drivers are.
+func driverError(err error, pkg, name string) bool {
+	t := reflect.TypeOf(err)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	return t.Elem().Name() == name && path.Base(t.Elem().PkgPath()) == pkg
+}
+
+// sqliteCode returns the code of a modernc.org/sqlite *sqlite.Error.
+func sqliteCode(err error) (int, bool) {
+	c, ok := err.(interface{ Code() int })
+	if !ok || !driverError(err, "sqlite", "Error") {
+		return 0, false
+	}
+	return c.Code(), true
+}
+
+// mysqlNumber returns the Number field of a *mysql.MySQLError.
+func mysqlNumber(err error) (uint16, bool) {
+	v := reflect.ValueOf(err)
+	if !driverError(err, "mysql", "MySQLError") || v.IsNil() {
+		return 0, false
+	}
+	f := v.Elem().FieldByName("Number")
+	if !f.IsValid() || f.Kind() != reflect.Uint16 {
+		return 0, false
+	}
+	return uint16(f.Uint()), true
+}
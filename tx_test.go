@@ -0,0 +1,82 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dav-m85/mapper/internal/drivertest/mysql"
+	"github.com/matryer/is"
+)
+
+// pgError mimics pgconn.PgError.
+type pgError struct{ code string }
+
+func (e *pgError) Error() string    { return "pg error " + e.code }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestTransact(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	conn := &fakeConnector{commitErrs: []error{&pgError{"40001"}}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	dut := Mapper(buildUser{}, "*")
+
+	runs := 0
+	err := Transact(ctx, db, &TxOptions{Backoff: time.Millisecond}, func(tx QueryExecer) error {
+		runs++
+		_, err := dut.Delete(ctx, tx, "users", buildUser{ID: 1})
+		return err
+	})
+	is.NoErr(err)
+	is.Equal(runs, 2) // the first commit failed
+	var queries []string
+	for _, c := range conn.calls {
+		queries = append(queries, c.query)
+	}
+	is.Equal(queries, []string{"BEGIN", "DELETE FROM users WHERE id=?", "COMMIT", "BEGIN", "DELETE FROM users WHERE id=?", "COMMIT"})
+
+	boom := errors.New("boom")
+	runs = 0
+	err = Transact(ctx, db, nil, func(tx QueryExecer) error {
+		runs++
+		return boom
+	})
+	is.Equal(err, boom)
+	is.Equal(runs, 1) // not retryable
+	is.Equal(conn.lastCall().query, "ROLLBACK")
+
+	runs = 0
+	err = Transact(ctx, db, &TxOptions{MaxAttempts: 2, Backoff: time.Millisecond}, func(tx QueryExecer) error {
+		runs++
+		return &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+	})
+	is.True(err != nil)
+	is.Equal(runs, 2)
+}
+
+func TestRetryable(t *testing.T) {
+	is := is.New(t)
+	is.True(Retryable(fmt.Errorf("update: %w", &pgError{"40P01"})))
+	is.True(!Retryable(&pgError{"23505"}))
+	is.True(Retryable(errors.Join(errors.New("x"), &mysql.MySQLError{Number: 1205})))
+	is.True(!Retryable(&mysql.MySQLError{Number: 1062}))
+	is.True(!Retryable(nil))
+	is.True(!Retryable(&codeError{5}))              // not a SQLite error
+	is.True(!Retryable(&numberError{Number: 1213})) // not a MySQL error
+}
+
+// codeError and numberError have the methods and fields of driver errors,
+// but are not.
+type codeError struct{ code int }
+
+func (e *codeError) Error() string { return "code" }
+func (e *codeError) Code() int     { return e.code }
+
+type numberError struct{ Number uint16 }
+
+func (e *numberError) Error() string { return "number" }
@@ -0,0 +1,109 @@
+package mapper
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// WithLocation normalizes the time.Time, *time.Time and sql.NullTime
+// fields of the mapper: [Values] gives them in UTC, and scans set them in
+// loc. Databases storing timestamps without a time zone, as SQLite and
+// MySQL datetime columns, then hold UTC whatever the local time zone of
+// writers, and readers get times in loc.
+//
+// The tz= tag option sets the location of a field, overriding loc:
+//
+//	Opens time.Time `mapper:"opens,tz=Europe/Paris"`
+func WithLocation(loc *time.Location) MapperOption {
+	if loc == nil {
+		panic("WithLocation: nil location")
+	}
+	return func(m *mapper) {
+		m.location = loc
+	}
+}
+
+// checkTZ returns the tz= location of a field of type t with options opts,
+// or an error when it is unknown or t is not a time.
+func checkTZ(t reflect.Type, opts TagOptions) (*time.Location, error) {
+	if !opts.Has("tz") {
+		return nil, nil
+	}
+	if !isTime(t) {
+		return nil, fmt.Errorf("field of type %s cannot have a time zone", t)
+	}
+	return time.LoadLocation(opts.Get("tz"))
+}
+
+// isTime tells whether fields of type t are normalized by [WithLocation].
+func isTime(t reflect.Type) bool {
+	return t == timeType || t == nullTimeType || t == reflect.PointerTo(timeType)
+}
+
+// locationOf returns the location field j is scanned in, if normalized.
+func (m *mapper) locationOf(j int) *time.Location {
+	if loc := m.fields[j].loc; loc != nil {
+		return loc
+	}
+	if m.location != nil && isTime(m.fields[j].Type) {
+		return m.location
+	}
+	return nil
+}
+
+// utc returns v, the value of field j, in UTC if normalized.
+func (m *mapper) utc(j int, v any) any {
+	if m.locationOf(j) == nil {
+		return v
+	}
+	switch t := v.(type) {
+	case time.Time:
+		return t.UTC()
+	case *time.Time:
+		if t == nil {
+			return nil
+		}
+		return t.UTC()
+	case sql.NullTime:
+		if !t.Valid {
+			return nil
+		}
+		return t.Time.UTC()
+	}
+	return v
+}
+
+// tzScanner scans times into dest, the address of a normalized field, in
+// loc.
+type tzScanner struct {
+	loc  *time.Location
+	dest any
+}
+
+func (s tzScanner) Scan(src any) error {
+	var n sql.NullTime
+	if err := n.Scan(src); err != nil {
+		return err
+	}
+	if n.Valid {
+		n.Time = n.Time.In(s.loc)
+	}
+	switch d := s.dest.(type) {
+	case *time.Time:
+		if !n.Valid {
+			return errors.New("converting NULL to time.Time is unsupported")
+		}
+		*d = n.Time
+	case **time.Time:
+		*d = nil
+		if n.Valid {
+			*d = &n.Time
+		}
+	case *sql.NullTime:
+		*d = n
+	}
+	return nil
+}
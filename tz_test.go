@@ -0,0 +1,70 @@
+package mapper
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestLocation(t *testing.T) {
+	is := is.New(t)
+	paris, err := time.LoadLocation("Europe/Paris")
+	is.NoErr(err)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	is.NoErr(err)
+	type Shop struct {
+		ID      int64        `mapper:"id,pk"`
+		Opened  time.Time    `mapper:"opened"`
+		Closed  *time.Time   `mapper:"closed"`
+		Audited sql.NullTime `mapper:"audited"`
+		Local   time.Time    `mapper:"local,tz=Asia/Tokyo"`
+		Created time.Time    `mapper:"created,autocreate"`
+	}
+	dut := Mapper(Shop{}, "*").SetOptions(WithLocation(paris), WithClock(func() time.Time {
+		return time.Date(2024, 1, 1, 12, 0, 0, 0, paris)
+	}))
+
+	noon := time.Date(2024, 6, 1, 12, 0, 0, 0, paris)
+	vals := dut.Values(Shop{1, noon, nil, sql.NullTime{}, noon, time.Time{}})
+	is.Equal(vals[1], noon.UTC())
+	is.Equal(vals[2], nil)
+	is.Equal(vals[3], nil)
+	is.Equal(vals[4], noon.UTC())
+	args := dut.InsertArgs(&Shop{})
+	is.Equal(args[5], time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)) // stamped in UTC
+
+	naive := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	rows := queryFake(t, []string{"id", "opened", "closed", "audited", "local", "created"},
+		[]driver.Value{int64(1), naive, naive, naive, naive, nil},
+	)
+	is.True(rows.Next())
+	_, err = ScanRow[Shop](dut, rows)
+	is.True(err != nil) // NULL into time.Time
+
+	rows = queryFake(t, []string{"id", "opened", "closed", "audited", "local", "created"},
+		[]driver.Value{int64(1), naive, naive, naive, naive, naive},
+	)
+	is.True(rows.Next())
+	s, err := ScanRow[Shop](dut, rows)
+	is.NoErr(err)
+	is.Equal(s.Opened, noon)
+	is.Equal(s.Opened.Location(), paris)
+	is.Equal(*s.Closed, noon)
+	is.Equal(s.Audited, sql.NullTime{Time: noon, Valid: true})
+	is.Equal(s.Local.Location(), tokyo)
+	is.True(s.Local.Equal(noon))
+
+	type Bad struct {
+		N int `mapper:"n,tz=UTC"`
+	}
+	_, err = MapperE(Bad{}, "*")
+	is.True(err != nil)
+	type Unknown struct {
+		T time.Time `mapper:"t,tz=Mars/Olympus"`
+	}
+	_, err = MapperE(Unknown{}, "*")
+	is.True(err != nil)
+}
@@ -0,0 +1,161 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// UpsertString returns an INSERT statement of the mapped columns into
+// table which updates the row instead when its primary key is taken:
+//
+//	INSERT INTO users (id,name) VALUES (?,?) ON CONFLICT (id) DO UPDATE SET name=excluded.name
+//
+// with the [Postgres] and [SQLite] dialects, and
+//
+//	INSERT INTO users (id,name) VALUES (?,?) ON DUPLICATE KEY UPDATE name=VALUES(name)
+//
+// with the [MySQL] one. Columns are set as with [UpdateString], and it
+// takes the arguments returned by [InsertArgs]. With [WithTenantColumn],
+// rows of other tenants are left alone, which MySQL cannot do.
+//
+// It panics with [ErrNoPrimaryKey] when no mapped column is tagged pk, or
+// with [ErrNoColumns] when nothing is left to update, see [UpsertStringE].
+func (m *mapper) UpsertString(table string) string {
+	s, err := m.UpsertStringE(table)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// UpsertStringE is like [UpsertString] but returns an error instead of
+// panicking, also when the dialect does not support upserts.
+func (m *mapper) UpsertStringE(table string) (string, error) {
+	if err := m.writable(); err != nil {
+		return "", err
+	}
+	pks := m.pkIndexesE()
+	if len(pks) == 0 {
+		return "", ErrNoPrimaryKey
+	}
+	var sets []string
+	for j, col := range m.cols {
+		if m.fields[j].opts.Has("pk") || m.createdOnly(j) || m.readOnlyColumn(j) {
+			continue
+		}
+		switch expr := m.autoExpr(j, true); {
+		case expr != "":
+			sets = append(sets, col+"="+expr)
+		case m.Dialect == MySQL:
+			sets = append(sets, col+"=VALUES("+col+")")
+		default:
+			sets = append(sets, col+"=excluded."+col)
+		}
+	}
+	if len(sets) == 0 {
+		return "", ErrNoColumns
+	}
+	m.warnDeprecated(nil)
+	s := m.insertInto(table) + "(" + m.insertValues(0) + ")"
+	switch m.Dialect {
+	case Postgres, SQLite:
+		keys := make([]string, len(pks))
+		for i, j := range pks {
+			keys[i] = m.cols[j]
+		}
+		s += " ON CONFLICT (" + strings.Join(keys, ",") + ") DO UPDATE SET " + strings.Join(sets, ",")
+		if m.tenantCol != "" {
+			s += " WHERE " + m.tableName(table) + "." + m.tenantCol + "=excluded." + m.tenantCol
+		}
+	case MySQL:
+		if m.tenantCol != "" {
+			return "", errors.New("mapper: no upsert with a tenant column for dialect mysql")
+		}
+		s += " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ",")
+	default:
+		return "", fmt.Errorf("mapper: no upsert support for dialect %q, see WithDialect", m.Dialect)
+	}
+	return s, nil
+}
+
+// Upsert inserts rec into table, or updates its row when its primary key
+// is taken, see [UpsertString]. Like [Insert], its BeforeInsert hook runs
+// first and it passes the tenant of ctx.
+func (m *mapper) Upsert(ctx context.Context, db Execer, table string, rec any) (sql.Result, error) {
+	query, args, err := m.upsert(ctx, table, rec)
+	if err != nil {
+		return nil, err
+	}
+	return m.execTraced(ctx, db, m.tableName(table), query, args)
+}
+
+// UpsertInserted is like [Upsert] but tells whether rec was inserted
+// rather than updated, for audit or metrics. The [Postgres] dialect asks
+// with RETURNING (xmax = 0), while the [MySQL] one counts rows affected, 1
+// for an insert, so connections MUST NOT set CLIENT_FOUND_ROWS. Other
+// dialects cannot tell and fail.
+//
+// With [WithTenantColumn], it fails with [sql.ErrNoRows] when the row
+// belongs to another tenant.
+func (m *mapper) UpsertInserted(ctx context.Context, db QueryExecer, table string, rec any) (inserted bool, err error) {
+	if m.Dialect != Postgres && m.Dialect != MySQL {
+		return false, fmt.Errorf("mapper: cannot tell upserted rows apart for dialect %q", m.Dialect)
+	}
+	query, args, err := m.upsert(ctx, table, rec)
+	if err != nil {
+		return false, err
+	}
+	if m.Dialect == MySQL {
+		res, err := m.execTraced(ctx, db, m.tableName(table), query, args)
+		if err != nil {
+			return false, err
+		}
+		n, err := res.RowsAffected()
+		return n == 1, err
+	}
+
+	query += " RETURNING (xmax = 0)"
+	ctx, end := m.startStatement(ctx, m.tableName(table), query, args)
+	rows := int64(0)
+	defer func() {
+		end(rows, err)
+	}()
+	rs, err := m.queryContext(ctx, db, query, args)
+	if err != nil {
+		return false, TranslateError(err)
+	}
+	defer rs.Close()
+	if !rs.Next() {
+		if err := rs.Err(); err != nil {
+			return false, TranslateError(err)
+		}
+		return false, sql.ErrNoRows
+	}
+	rows = 1
+	return inserted, rs.Scan(&inserted)
+}
+
+// upsert returns the statement and arguments upserting rec into table.
+func (m *mapper) upsert(ctx context.Context, table string, rec any) (string, []any, error) {
+	query, err := m.UpsertStringE(table)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := beforeInsert(ctx, rec); err != nil {
+		return "", nil, err
+	}
+	if err := m.validate(rec); err != nil {
+		return "", nil, err
+	}
+	args, err := m.insertArgs(rec)
+	if err != nil {
+		return "", nil, err
+	}
+	if args, err = m.withTenant(ctx, args); err != nil {
+		return "", nil, err
+	}
+	return query, args, nil
+}
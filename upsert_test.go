@@ -0,0 +1,76 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestUpsertString(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID      int64     `mapper:"id,pk"`
+		Name    string    `mapper:"name"`
+		Created time.Time `mapper:"created,autocreate=now()"`
+		Updated time.Time `mapper:"updated,autoupdate=now()"`
+	}
+	m := Mapper(User{}, "*")
+	is.Equal(m.With(WithDialect(Postgres)).UpsertString("users"),
+		"INSERT INTO users (id,name,created,updated) VALUES ($1,$2,now(),now()) ON CONFLICT (id) DO UPDATE SET name=excluded.name,updated=now()")
+	is.Equal(m.With(WithDialect(MySQL)).UpsertString("users"),
+		"INSERT INTO users (id,name,created,updated) VALUES (?,?,now(),now()) ON DUPLICATE KEY UPDATE name=VALUES(name),updated=now()")
+
+	tenant := WithTenantColumn("org_id", func(context.Context) any { return 1 })
+	is.Equal(m.With(WithDialect(SQLite), tenant).UpsertString("users"),
+		"INSERT INTO users (id,name,created,updated,org_id) VALUES (?,?,now(),now(),?) ON CONFLICT (id) DO UPDATE SET name=excluded.name,updated=now() WHERE users.org_id=excluded.org_id")
+	_, err := m.With(WithDialect(MySQL), tenant).UpsertStringE("users")
+	is.True(err != nil)
+	_, err = m.UpsertStringE("users")
+	is.True(err != nil) // no dialect
+	_, err = Mapper(buildUser{}, "name").With(WithDialect(Postgres)).UpsertStringE("users")
+	is.Equal(err, ErrNoPrimaryKey)
+	_, err = Mapper(buildUser{}, "id").With(WithDialect(Postgres)).UpsertStringE("users")
+	is.Equal(err, ErrNoColumns)
+}
+
+func TestUpsertInserted(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	inserted := true
+	conn := &fakeConnector{answer: func(string, []driver.Value) ([]string, [][]driver.Value) {
+		return []string{"?column?"}, [][]driver.Value{{inserted}}
+	}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	pg := Mapper(buildUser{}, "*").With(WithDialect(Postgres))
+	ok, err := pg.UpsertInserted(ctx, db, "users", buildUser{ID: 1, Name: "a"})
+	is.NoErr(err)
+	is.True(ok)
+	is.Equal(conn.lastCall().query, "INSERT INTO users (id,name,email) VALUES ($1,$2,$3) ON CONFLICT (id) DO UPDATE SET name=excluded.name,email=excluded.email RETURNING (xmax = 0)")
+	inserted = false
+	ok, err = pg.UpsertInserted(ctx, db, "users", buildUser{ID: 1, Name: "a"})
+	is.NoErr(err)
+	is.True(!ok)
+
+	my := Mapper(buildUser{}, "*").With(WithDialect(MySQL))
+	ok, err = my.UpsertInserted(ctx, db, "users", buildUser{ID: 1})
+	is.NoErr(err)
+	is.True(ok)
+	conn.affected = 2
+	ok, err = my.UpsertInserted(ctx, db, "users", buildUser{ID: 1})
+	is.NoErr(err)
+	is.True(!ok)
+
+	_, err = Mapper(buildUser{}, "*").With(WithDialect(SQLite)).UpsertInserted(ctx, db, "users", buildUser{ID: 1})
+	is.True(err != nil)
+
+	conn.answer = func(string, []driver.Value) ([]string, [][]driver.Value) { return []string{"?column?"}, nil }
+	_, err = pg.UpsertInserted(ctx, db, "users", buildUser{ID: 1})
+	is.True(errors.Is(err, sql.ErrNoRows)) // another tenant's row
+}
@@ -0,0 +1,232 @@
+package mapper
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// uuidForm is how a uuid column stores UUIDs.
+type uuidForm uint8
+
+const (
+	uuidNone uuidForm = iota
+	uuidText
+	uuidBinary
+	uuidSwapped
+)
+
+var uuidForms = map[string]uuidForm{"": uuidText, "text": uuidText, "binary": uuidBinary, "swapped": uuidSwapped}
+
+// UUIDv4 returns a random UUID, version 4 of RFC 9562.
+func UUIDv4() [16]byte {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// UUIDv7 returns a time ordered UUID, version 7 of RFC 9562, which makes
+// for better index locality than [UUIDv4].
+func UUIDv7() [16]byte {
+	var u [16]byte
+	rand.Read(u[6:])
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(u[:6], ms[2:])
+	u[6] = u[6]&0x0f | 0x70
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// WithUUID sets the UUID generator of uuid primary keys, [UUIDv4] by
+// default:
+//
+//	m := Mapper(User{}, "*").SetOptions(WithUUID(UUIDv7))
+func WithUUID(gen func() [16]byte) MapperOption {
+	return func(m *mapper) {
+		m.uuid = gen
+	}
+}
+
+var uuidArrayType = reflect.TypeFor[[16]byte]()
+
+// checkUUID returns the storage form of a field of type t with options
+// opts, or an error when it is tagged uuid while not being a string or a
+// [16]byte, or with an unknown form.
+func checkUUID(t reflect.Type, opts TagOptions) (uuidForm, error) {
+	if !opts.Has("uuid") {
+		return uuidNone, nil
+	}
+	if t.Kind() != reflect.String && (t.Kind() != reflect.Array || !t.ConvertibleTo(uuidArrayType)) {
+		return uuidNone, fmt.Errorf("field of type %s cannot be uuid", t)
+	}
+	form, ok := uuidForms[opts.Get("uuid")]
+	if !ok {
+		return uuidNone, fmt.Errorf("unknown uuid form %q, want text, binary or swapped", opts.Get("uuid"))
+	}
+	return form, nil
+}
+
+// setUUID sets the uuid field fv to a UUID of gen, and returns its new
+// value.
+func setUUID(fv reflect.Value, gen func() [16]byte) any {
+	u := gen()
+	var v reflect.Value
+	if fv.Kind() == reflect.String {
+		v = reflect.ValueOf(formatUUID(u)).Convert(fv.Type())
+	} else {
+		v = reflect.ValueOf(u).Convert(fv.Type())
+	}
+	if fv.CanSet() {
+		fv.Set(v)
+	}
+	return v.Interface()
+}
+
+// formatUUID returns the canonical form of u.
+func formatUUID(u [16]byte) string {
+	var b [36]byte
+	hex.Encode(b[:], u[:4])
+	b[8] = '-'
+	hex.Encode(b[9:], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
+
+// parseUUID parses the text form of a UUID.
+func parseUUID(s string) ([16]byte, error) {
+	var u [16]byte
+	t := strings.TrimPrefix(strings.ToLower(s), "urn:uuid:")
+	t = strings.TrimSuffix(strings.TrimPrefix(t, "{"), "}")
+	if len(t) == 36 && t[8] == '-' && t[13] == '-' && t[18] == '-' && t[23] == '-' {
+		t = t[:8] + t[9:13] + t[14:18] + t[19:23] + t[24:]
+	}
+	if len(t) != 32 {
+		return u, fmt.Errorf("invalid UUID %q", s)
+	}
+	if _, err := hex.Decode(u[:], []byte(t)); err != nil {
+		return u, fmt.Errorf("invalid UUID %q", s)
+	}
+	return u, nil
+}
+
+// swapUUID moves the time fields of u first, as MySQL UUID_TO_BIN(u, 1),
+// and unswapUUID moves them back.
+func swapUUID(u [16]byte) [16]byte {
+	var s [16]byte
+	copy(s[:], u[6:8])
+	copy(s[2:], u[4:6])
+	copy(s[4:], u[:4])
+	copy(s[8:], u[8:])
+	return s
+}
+
+func unswapUUID(s [16]byte) [16]byte {
+	var u [16]byte
+	copy(u[:], s[4:8])
+	copy(u[4:], s[2:4])
+	copy(u[6:], s[:2])
+	copy(u[8:], s[8:])
+	return u
+}
+
+// uuidValue returns the database value of v, the value of a uuid field
+// stored in form.
+func uuidValue(form uuidForm, v any) any {
+	rv := reflect.ValueOf(v)
+	var u [16]byte
+	if rv.Kind() == reflect.String {
+		if rv.Len() == 0 {
+			return nil // NULL
+		}
+		if form == uuidText {
+			return v
+		}
+		var err error
+		if u, err = parseUUID(rv.String()); err != nil {
+			return failingValue{err}
+		}
+	} else {
+		u = rv.Convert(uuidArrayType).Interface().([16]byte)
+	}
+	switch form {
+	case uuidBinary:
+		return u[:]
+	case uuidSwapped:
+		s := swapUUID(u)
+		return s[:]
+	}
+	return formatUUID(u)
+}
+
+// uuidScanner scans UUIDs stored in form into the uuid field pointed to by
+// dest.
+type uuidScanner struct {
+	form uuidForm
+	dest reflect.Value
+}
+
+func (s uuidScanner) Scan(src any) error {
+	f := s.dest.Elem()
+	var u [16]byte
+	if b, ok := src.([]byte); ok && len(b) == 16 {
+		copy(u[:], b)
+		if s.form == uuidSwapped {
+			u = unswapUUID(u)
+		}
+	} else if t, ok := textOf(src); ok {
+		if f.Kind() == reflect.String && s.form == uuidText {
+			f.SetString(t)
+			return nil
+		}
+		var err error
+		if u, err = parseUUID(t); err != nil {
+			return err
+		}
+	} else if src == nil {
+		f.SetZero()
+		return nil
+	} else {
+		return fmt.Errorf("cannot scan a %T into a UUID", src)
+	}
+	if f.Kind() == reflect.String {
+		f.SetString(formatUUID(u))
+	} else {
+		f.Set(reflect.ValueOf(u).Convert(f.Type()))
+	}
+	return nil
+}
+
+// uuidColumnType returns the column type of UUIDs stored in form, if any.
+func uuidColumnType(form uuidForm, d Dialect) string {
+	if form == uuidNone {
+		return ""
+	}
+	text := form == uuidText
+	switch {
+	case d == Postgres && text:
+		return "uuid"
+	case d == Postgres:
+		return "bytea"
+	case d == MySQL && text:
+		return "char(36)"
+	case d == MySQL:
+		return "binary(16)"
+	case d == SQLite && text:
+		return "text"
+	case d == SQLite:
+		return "blob"
+	}
+	return ""
+}
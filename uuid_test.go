@@ -0,0 +1,110 @@
+package mapper
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestUUID(t *testing.T) {
+	is := is.New(t)
+	canonical := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	type Doc struct {
+		ID    string `mapper:"id,pk,uuid"`
+		Title string `mapper:"title"`
+	}
+	dut := Mapper(Doc{}, "*").SetOptions(WithUUID(UUIDv7))
+	d := Doc{Title: "t"}
+	is.Equal(dut.Values(&d), []any{nil, "t"}) // NULL, insert helpers generate keys
+	args := dut.InsertArgs(&d)
+	is.True(canonical.MatchString(d.ID))
+	is.Equal(args, []any{d.ID, "t"})
+	is.Equal(dut.InsertArgs(Doc{ID: "given"})[0], "given") // kept when set
+
+	type UUID [16]byte
+	type Blob struct {
+		ID UUID `mapper:"id,pk,uuid"`
+	}
+	b := Blob{}
+	Mapper(Blob{}, "*").InsertArgs(&b)
+	is.True(b.ID != UUID{})
+	is.Equal(b.ID[6]>>4, byte(4))
+
+	type Bad struct {
+		ID int64 `mapper:"id,pk,uuid"`
+	}
+	_, err := MapperE(Bad{}, "*")
+	is.True(err != nil)
+}
+
+func TestUUIDNotKey(t *testing.T) {
+	is := is.New(t)
+	type Doc struct {
+		ID     string `mapper:"id,pk,uuid=binary"`
+		Parent string `mapper:"parent_id,uuid=binary"`
+		Ref    string `mapper:"ref,uuid,default=uuid"`
+		Owner  string `mapper:"owner_id,uuid"`
+	}
+	d := Doc{}
+	args := Mapper(Doc{}, "*").InsertArgs(&d)
+	is.Equal(len(args[0].([]byte)), 16)
+	is.Equal(d.Parent, "")
+	is.Equal(args[1], nil) // NULL, not generated
+	is.True(d.Ref != "")   // opted in
+	is.Equal(args[2], d.Ref)
+	is.Equal(args[3], nil) // NULL in the text form too
+}
+
+func TestUUIDForms(t *testing.T) {
+	is := is.New(t)
+	type UUID [16]byte
+	type Row struct {
+		Text    UUID   `mapper:"text,uuid"`
+		Binary  string `mapper:"binary,uuid=binary"`
+		Swapped UUID   `mapper:"swapped,uuid=swapped"`
+	}
+	dut := Mapper(Row{}, "*")
+	const id = "6ccd780c-baba-1026-9564-5b8c656024db" // the MySQL UUID_TO_BIN example
+	u, err := parseUUID(id)
+	is.NoErr(err)
+	vals := dut.Values(Row{u, id, u})
+	is.Equal(vals[0], id)
+	is.Equal(vals[1], u[:])
+	is.Equal(formatUUID([16]byte(vals[2].([]byte))), "1026baba-6ccd-780c-9564-5b8c656024db")
+	is.Equal(dut.Values(Row{})[1], nil) // empty is NULL
+
+	rows := queryFake(t, []string{"text", "binary", "swapped"},
+		[]driver.Value{"{" + strings.ToUpper(id) + "}", u[:], vals[2]},
+		[]driver.Value{"urn:uuid:" + strings.ReplaceAll(id, "-", ""), nil, id},
+	)
+	is.True(rows.Next())
+	r, err := ScanRow[Row](dut, rows)
+	is.NoErr(err)
+	is.Equal(r, Row{u, id, u})
+	is.True(rows.Next())
+	r, err = ScanRow[Row](dut, rows)
+	is.NoErr(err)
+	is.Equal(r, Row{Text: u, Swapped: u})
+
+	ddl, err := dut.With(WithTable("rows"), WithDialect(MySQL)).CreateTableStringE("")
+	is.NoErr(err)
+	is.True(strings.Contains(ddl, "text char(36)"))
+	is.True(strings.Contains(ddl, "swapped binary(16)"))
+
+	type Bad struct {
+		ID string `mapper:"id,uuid=hex"`
+	}
+	_, err = MapperE(Bad{}, "*")
+	is.True(err != nil)
+}
+
+func TestUUIDv7Order(t *testing.T) {
+	is := is.New(t)
+	a, b := UUIDv7(), UUIDv7()
+	is.True(formatUUID(a)[:8] <= formatUUID(b)[:8])
+	is.Equal(formatUUID([16]byte{0: 0xab, 15: 0x01}), "ab000000-0000-0000-0000-000000000001")
+}
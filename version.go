@@ -0,0 +1,76 @@
+package mapper
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// checkVersion returns an error when the since= or until= options of opts
+// are not integers, or make an empty range.
+func checkVersion(opts TagOptions) error {
+	since, until, err := versionRange(opts)
+	if err != nil {
+		return err
+	}
+	if since > until {
+		return fmt.Errorf("since=%d is after until=%d", since, until)
+	}
+	return nil
+}
+
+// versionRange returns the versions of the schema a field of options opts
+// exists in.
+func versionRange(opts TagOptions) (since, until int, err error) {
+	since, until = 0, math.MaxInt
+	if opts.Has("since") {
+		if since, err = strconv.Atoi(opts.Get("since")); err != nil {
+			return 0, 0, fmt.Errorf("since=%q not a version", opts.Get("since"))
+		}
+	}
+	if opts.Has("until") {
+		if until, err = strconv.Atoi(opts.Get("until")); err != nil {
+			return 0, 0, fmt.Errorf("until=%q not a version", opts.Get("until"))
+		}
+	}
+	return since, until, nil
+}
+
+// ForVersion returns a mapper with the columns of m existing in version v
+// of the schema, as during rolling migrations, while old and new columns
+// coexist. Fields tagged since= or until= exist in some versions only:
+//
+//	type User struct {
+//	  ID       int64  `mapper:"id,pk"`
+//	  Name     string `mapper:"name,until=4"`
+//	  FullName string `mapper:"full_name,since=3"`
+//	}
+//
+//	users := users.ForVersion(deployedSchemaVersion)
+//
+// Both bounds are inclusive: name exists up to version 4, and full_name
+// from version 3 on. Columns with neither option exist in every version.
+// It panics with [ErrNoColumns] when no column is left, see [ForVersionE].
+func (m *mapper) ForVersion(v int) *mapper {
+	s, err := m.ForVersionE(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ForVersionE is like [ForVersion] but returns [ErrNoColumns] instead of
+// panicking.
+func (m *mapper) ForVersionE(v int) (*mapper, error) {
+	idx := make([]int, 0, len(m.cols))
+	for j, f := range m.fields {
+		since, until, _ := versionRange(f.opts) // checked by mapStruct
+		if since <= v && v <= until {
+			idx = append(idx, j)
+		}
+	}
+	if len(idx) == 0 {
+		return nil, ErrNoColumns
+	}
+	return m.pick(idx), nil
+}
@@ -0,0 +1,38 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestForVersion(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID       int64  `mapper:"id,pk"`
+		Name     string `mapper:"name,until=4"`
+		FullName string `mapper:"full_name,since=3"`
+	}
+	dut := Mapper(User{}, "*")
+	is.Equal(dut.ForVersion(2).Columns(), []string{"id", "name"})
+	is.Equal(dut.ForVersion(3).Columns(), []string{"id", "name", "full_name"})
+	is.Equal(dut.ForVersion(5).Columns(), []string{"id", "full_name"})
+	is.Equal(dut.ForVersion(5).Values(User{1, "a", "b"}), []any{int64(1), "b"})
+
+	type Only struct {
+		N int `mapper:"n,since=2"`
+	}
+	_, err := Mapper(Only{}, "*").ForVersionE(1)
+	is.Equal(err, ErrNoColumns)
+
+	type Bad struct {
+		N int `mapper:"n,since=3,until=2"`
+	}
+	_, err = MapperE(Bad{}, "*")
+	is.True(err != nil)
+	type NaN struct {
+		N int `mapper:"n,since=v3"`
+	}
+	_, err = MapperE(NaN{}, "*")
+	is.True(err != nil)
+}
@@ -0,0 +1,85 @@
+package mapper
+
+import (
+	"reflect"
+	"strings"
+)
+
+// NullMatch tells how query-by-example predicates treat nil fields, see
+// [WhereExample].
+type NullMatch int
+
+const (
+	// MatchNil matches nil pointers, maps, slices and invalid sql.Null
+	// fields against NULL columns, with col IS NULL.
+	MatchNil NullMatch = iota
+
+	// SkipNil leaves nil fields out, as zero ones.
+	SkipNil
+)
+
+// WhereExample returns a query-by-example predicate on the non zero fields
+// of example, joined with AND, and its arguments:
+//
+//	where, args := users.WhereExample(User{Country: "FR", Manager: nil}, mapper.MatchNil)
+//	// country=? AND manager_id IS NULL
+//	rows, err := db.Query(users.SelectString("")+" WHERE "+where, args...)
+//
+// Zero fields are left out, unless listed in cols, which then match their
+// zero value. Nil fields match NULL columns with col IS NULL, never the
+// always false col = NULL, unless nulls is [SkipNil] and they are not
+// listed in cols. The predicate is 1=1 when no field is left. It panics on
+// errors, see [WhereExampleE].
+func (m *mapper) WhereExample(example any, nulls NullMatch, cols ...string) (string, []any) {
+	where, args, err := m.WhereExampleE(example, nulls, cols...)
+	if err != nil {
+		panic(err)
+	}
+	return where, args
+}
+
+// WhereExampleE is [WhereExample], returning an error when example is not
+// of the target type or some of cols are not mapped.
+func (m *mapper) WhereExampleE(example any, nulls NullMatch, cols ...string) (string, []any, error) {
+	v, p, err := m.structOf(example)
+	if err != nil {
+		return "", nil, err
+	}
+	if missing := m.unknown(cols); len(missing) > 0 {
+		return "", nil, &ErrMissingColumns{Cols: missing}
+	}
+	var preds []string
+	var args []any
+	for j, f := range m.fields {
+		if m.isExpr(j) {
+			continue
+		}
+		fv := v.FieldByIndex(f.Index)
+		listed := fieldSlice(cols).index(m.cols[j]) != -1
+		switch {
+		case isNull(fv) && (listed || nulls == MatchNil):
+			preds = append(preds, m.cols[j]+" IS NULL")
+		case listed || !fv.IsZero():
+			args = append(args, m.fieldValue(v, p, j))
+			preds = append(preds, m.cols[j]+"="+m.castExpr(j, m.placeholder(len(args))))
+		}
+	}
+	if len(preds) == 0 {
+		return "1=1", nil, nil
+	}
+	return strings.Join(preds, " AND "), args, nil
+}
+
+// isNull tells whether fv, a field value, is written as NULL: a nil
+// pointer, map, slice or interface, or an invalid sql.Null type.
+func isNull(fv reflect.Value) bool {
+	switch fv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return fv.IsNil()
+	case reflect.Struct:
+		if _, ok := nullableType(fv.Type()); ok {
+			return !fv.Field(1).Bool()
+		}
+	}
+	return false
+}
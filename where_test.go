@@ -0,0 +1,41 @@
+package mapper
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWhereExample(t *testing.T) {
+	is := is.New(t)
+	type User struct {
+		ID      int64          `mapper:"id,pk"`
+		Country string         `mapper:"country"`
+		Manager *int64         `mapper:"manager_id"`
+		Nick    sql.NullString `mapper:"nick"`
+		Admin   bool           `mapper:"admin"`
+	}
+	dut := Mapper(User{}, "*")
+
+	where, args := dut.WhereExample(User{Country: "FR"}, MatchNil)
+	is.Equal(where, "country=? AND manager_id IS NULL AND nick IS NULL")
+	is.Equal(args, []any{"FR"})
+
+	where, args = dut.WhereExample(User{Country: "FR"}, SkipNil, "admin", "nick")
+	is.Equal(where, "country=? AND nick IS NULL AND admin=?")
+	is.Equal(args, []any{"FR", false})
+
+	where, args = dut.With(WithDialect(Postgres)).WhereExample(User{ID: 1, Nick: sql.NullString{String: "x", Valid: true}}, SkipNil)
+	is.Equal(where, "id=$1 AND nick=$2")
+	is.Equal(args, []any{int64(1), sql.NullString{String: "x", Valid: true}})
+
+	where, args = dut.WhereExample(User{}, SkipNil)
+	is.Equal(where, "1=1")
+	is.Equal(args, nil)
+
+	_, _, err := dut.WhereExampleE(User{}, SkipNil, "nope")
+	is.True(err != nil)
+
+	is.Equal(dut.SquirrelEq(User{Country: "FR"}, "manager_id", "admin"), map[string]any{"country": "FR", "manager_id": nil, "admin": false})
+}
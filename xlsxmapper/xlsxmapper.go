@@ -0,0 +1,133 @@
+// Package xlsxmapper writes mapped records to Excel sheets, with the mapped
+// columns as headers. Records are streamed to the sheet, so large exports
+// do not hold every cell in memory. It lives in its own module to keep
+// mapper free of dependencies.
+package xlsxmapper
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Mapper is the part of a mapper this package needs.
+type Mapper interface {
+	Columns() []string
+	ExportValues(dest any) []any
+}
+
+// Writer writes records one row at a time to a sheet.
+type Writer struct {
+	m   Mapper
+	sw  *excelize.StreamWriter
+	row int
+}
+
+// NewWriter returns a Writer of records to sheet in f, which is created if
+// needed, and writes the header row, frozen on top. Flush it once done, before saving f.
+// Any existing content of sheet is replaced.
+func NewWriter(f *excelize.File, sheet string, m Mapper) (*Writer, error) {
+	if idx, err := f.GetSheetIndex(sheet); err != nil {
+		return nil, err
+	} else if idx == -1 {
+		if _, err := f.NewSheet(sheet); err != nil {
+			return nil, err
+		}
+	}
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	cols := m.Columns()
+	header := make([]any, len(cols))
+	for j, col := range cols {
+		header[j] = excelize.Cell{StyleID: bold, Value: col}
+	}
+	if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return nil, err
+	}
+	w := &Writer{m: m, sw: sw, row: 1}
+	if err := w.setRow(header); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends rec, a mapper target struct or a pointer to one. Numbers,
+// booleans and times are written as such, times with a date format; NULL
+// values leave the cell empty.
+func (w *Writer) Write(rec any) error {
+	vs := w.m.ExportValues(rec)
+	row := make([]any, len(vs))
+	for j, v := range vs {
+		v, err := plain(v)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", w.m.Columns()[j], err)
+		}
+		row[j] = v
+	}
+	return w.setRow(row)
+}
+
+func (w *Writer) setRow(values []any) error {
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	w.row++
+	return w.sw.SetRow(cell, values)
+}
+
+// Flush ends the sheet. No record can be written afterwards.
+func (w *Writer) Flush() error {
+	return w.sw.Flush()
+}
+
+// plain dereferences pointers and resolves driver.Valuer implementations,
+// such as sql.Null types, returning nil for NULL.
+func plain(v any) (any, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return plain(rv.Elem().Interface())
+	}
+	if vr, ok := v.(driver.Valuer); ok {
+		return vr.Value()
+	}
+	return v, nil
+}
+
+// Write writes records, a slice of structs or struct pointers, as a
+// workbook with a single sheet named sheet to out.
+func Write(out io.Writer, sheet string, m Mapper, records any) error {
+	recs := reflect.ValueOf(records)
+	if recs.Kind() != reflect.Slice && recs.Kind() != reflect.Array {
+		return fmt.Errorf("records not a slice: %s", recs.Kind())
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+	w, err := NewWriter(f, sheet, m)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < recs.Len(); i++ {
+		if err := w.Write(recs.Index(i).Interface()); err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Write(out)
+}
@@ -0,0 +1,49 @@
+package xlsxmapper
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dav-m85/mapper"
+	"github.com/matryer/is"
+	"github.com/xuri/excelize/v2"
+)
+
+type order struct {
+	ID    int
+	Label string
+	Total float64
+	Note  *string
+	Paid  sql.NullTime
+}
+
+func TestWrite(t *testing.T) {
+	is := is.New(t)
+	m := mapper.Mapper(order{}, "*")
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	err := Write(&buf, "Orders", m, []order{
+		{1, "a", 12.5, nil, sql.NullTime{Time: at, Valid: true}},
+		{2, "b", 3, nil, sql.NullTime{}},
+	})
+	is.NoErr(err)
+
+	f, err := excelize.OpenReader(&buf)
+	is.NoErr(err)
+	defer f.Close()
+	is.Equal(f.GetSheetList(), []string{"Orders"})
+	rows, err := f.GetRows("Orders")
+	is.NoErr(err)
+	is.Equal(rows[0], []string{"id", "label", "total", "note", "paid"})
+	is.Equal(rows[1][:3], []string{"1", "a", "12.5"})
+	is.Equal(rows[2], []string{"2", "b", "3"})
+
+	typ, err := f.GetCellType("Orders", "C2")
+	is.NoErr(err)
+	is.Equal(typ, excelize.CellTypeUnset) // numbers have no explicit type
+	v, err := f.GetCellValue("Orders", "E2", excelize.Options{RawCellValue: true})
+	is.NoErr(err)
+	is.Equal(v, "45444.5") // Excel serial date
+}
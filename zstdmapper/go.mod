@@ -0,0 +1,11 @@
+module github.com/dav-m85/mapper/zstdmapper
+
+go 1.24.6
+
+require (
+	github.com/dav-m85/mapper v0.0.0
+	github.com/klauspost/compress v1.19.2
+	github.com/matryer/is v1.4.1
+)
+
+replace github.com/dav-m85/mapper => ../
@@ -0,0 +1,40 @@
+// Package zstdmapper registers the zstd compressor of fields tagged
+// compress=zstd. It lives in its own module to keep mapper free of
+// dependencies, and is imported for its side effect:
+//
+//	import _ "github.com/dav-m85/mapper/zstdmapper"
+//
+//	type Event struct {
+//	  ID      int64  `mapper:"id,pk"`
+//	  Payload []byte `mapper:"payload,compress=zstd"`
+//	}
+package zstdmapper
+
+import (
+	"github.com/dav-m85/mapper"
+	"github.com/klauspost/compress/zstd"
+)
+
+func init() {
+	mapper.RegisterCompressor("zstd", compressor{})
+}
+
+var (
+	encoder, _ = zstd.NewWriter(nil)
+	decoder, _ = zstd.NewReader(nil)
+)
+
+// compressor is the zstd [mapper.Compressor].
+type compressor struct{}
+
+func (compressor) Compress(data []byte) ([]byte, error) {
+	return encoder.EncodeAll(data, nil), nil
+}
+
+func (compressor) Decompress(data []byte) ([]byte, error) {
+	return decoder.DecodeAll(data, nil)
+}
+
+func (compressor) Magic() []byte {
+	return []byte{0x28, 0xb5, 0x2f, 0xfd}
+}
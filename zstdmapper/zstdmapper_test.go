@@ -0,0 +1,27 @@
+package zstdmapper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dav-m85/mapper"
+	"github.com/matryer/is"
+)
+
+func TestCompress(t *testing.T) {
+	is := is.New(t)
+	type Event struct {
+		ID      int64  `mapper:"id,pk"`
+		Payload string `mapper:"payload,compress=zstd"`
+	}
+	m := mapper.Mapper(Event{}, "*")
+	payload := strings.Repeat("hello ", 100)
+	c := m.Values(Event{1, payload})[1].([]byte)
+	is.True(bytes.HasPrefix(c, compressor{}.Magic()))
+	is.True(len(c) < len(payload))
+
+	var e Event
+	is.NoErr(m.Addrs(&e)[1].(interface{ Scan(any) error }).Scan(c))
+	is.Equal(e.Payload, payload)
+}